@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// burpRule is a rule of Burp's advanced target scope; host, port and file
+// are regular expressions
+type burpRule struct {
+	Enabled  bool   `json:"enabled"`
+	Protocol string `json:"protocol"` // "http", "https" or "any"
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"`
+	File     string `json:"file"`
+}
+
+// burpConfig is the part of a Burp project options file holding the scope,
+// loaded with Project options / "Load project options" or Target / Scope
+type burpConfig struct {
+	Target struct {
+		Scope struct {
+			AdvancedMode bool       `json:"advanced_mode"`
+			Include      []burpRule `json:"include"`
+			Exclude      []burpRule `json:"exclude"`
+		} `json:"scope"`
+	} `json:"target"`
+}
+
+// burpScope returns the scope of the live subdomains of a result: the
+// scheme and port of the web server --probe found, else any protocol and port
+func burpScope(result *shodanx.Result) *burpConfig {
+	cfg := &burpConfig{}
+	cfg.Target.Scope.AdvancedMode = true
+	cfg.Target.Scope.Exclude = []burpRule{}
+	cfg.Target.Scope.Include = []burpRule{}
+	for _, name := range liveHosts(result) {
+		rule := burpRule{Enabled: true, Protocol: "any", Host: "^" + regexp.QuoteMeta(name) + "$", File: "^/.*"}
+		if p := result.Probes[name]; p != nil {
+			if scheme, _, port, ok := probeHostPort(p); ok {
+				rule.Protocol = scheme
+				rule.Port = "^" + port + "$"
+			}
+		}
+		cfg.Target.Scope.Include = append(cfg.Target.Scope.Include, rule)
+	}
+	return cfg
+}
+
+// saveBurp writes the Burp Suite scope of a result to <prefix>_burp.json
+func saveBurp(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	burpFile := outputPrefix + "_burp.json"
+	cfg := burpScope(result)
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err == nil {
+		err = os.WriteFile(burpFile, data, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to save Burp scope file %s: %v\n", burpFile, err)
+		return err
+	}
+	fmt.Fprintf(w, "[+] Burp scope with %d hosts saved to %s\n", len(cfg.Target.Scope.Include), burpFile)
+	return nil
+}
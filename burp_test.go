@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestBurpScope(t *testing.T) {
+	result := &shodanx.Result{
+		Subdomains: []string{"www.example.com", "app.example.com", "mail.example.com", "dev.example.com"},
+		IPs: map[string][]string{
+			"www.example.com":  {"192.0.2.1"},
+			"app.example.com":  {"192.0.2.2"},
+			"mail.example.com": {"192.0.2.3"},
+		},
+		Probes: map[string]*shodanx.Probe{
+			"www.example.com": {URL: "https://www.example.com/"},
+			"app.example.com": {URL: "http://app.example.com:8080/login"},
+			"dev.example.com": {URL: "https://dev.example.com/"},
+		},
+	}
+	want := []burpRule{
+		{Enabled: true, Protocol: "https", Host: `^www\.example\.com$`, Port: "^443$", File: "^/.*"},
+		{Enabled: true, Protocol: "http", Host: `^app\.example\.com$`, Port: "^8080$", File: "^/.*"},
+		{Enabled: true, Protocol: "any", Host: `^mail\.example\.com$`, File: "^/.*"},
+	}
+	cfg := burpScope(result)
+	if !cfg.Target.Scope.AdvancedMode {
+		t.Error("advanced scope mode not set")
+	}
+	if got := cfg.Target.Scope.Include; !reflect.DeepEqual(got, want) {
+		t.Errorf("include rules = %+v, want %+v", got, want)
+	}
+	if cfg.Target.Scope.Exclude == nil {
+		t.Error("exclude rules are null, Burp expects a list")
+	}
+}
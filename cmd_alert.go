@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func alertUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s alert <create|list|delete|notify> [OPTIONS]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n  create   Create a network alert for IPs/CIDRs\n")
+	fmt.Fprintf(os.Stderr, "  list     List existing alerts\n")
+	fmt.Fprintf(os.Stderr, "  delete   Delete alerts by ID\n")
+	fmt.Fprintf(os.Stderr, "  notify   Attach notifiers to an alert\n")
+}
+
+func runAlert(args []string) {
+	if len(args) < 1 {
+		alertUsage()
+		os.Exit(exitError)
+	}
+
+	switch args[0] {
+	case "create":
+		runAlertCreate(args[1:])
+	case "list":
+		runAlertList(args[1:])
+	case "delete":
+		runAlertDelete(args[1:])
+	case "notify":
+		runAlertNotify(args[1:])
+	default:
+		alertUsage()
+		os.Exit(exitError)
+	}
+}
+
+func runAlertCreate(args []string) {
+	fs, opts := newFlagSet("alert create", "[<ip|cidr>...]",
+		"alert create --apikey YOUR_API_KEY --name acme --ips 203.0.113.0/24,198.51.100.7",
+		"alert create --apikey YOUR_API_KEY --name acme --input ips.txt")
+	name := fs.String("name", "", "Alert name (required)")
+	ips := fs.String("ips", "", "Comma-separated IPs/CIDRs to monitor")
+	input := fs.String("input", "", "File with one IP or CIDR per line")
+	expires := fs.Int("expires", 0, "Alert lifetime in seconds (0 = never expires)")
+	notifiers := fs.String("notifiers", "", "Comma-separated notifier IDs to attach")
+	triggers := fs.String("triggers", "", "Comma-separated triggers to enable (e.g. new_service,open_database,vulnerable)")
+	opts.parse(fs, args, "")
+
+	if *name == "" {
+		fmt.Println("Error: --name is required!")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	targets := readTargets(fs, append(fs.Args(), splitList(*ips)...), *input)
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	alert, err := client.CreateAlert(ctx, *name, targets, *expires)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("[+] Alert %s (%s) created for %d IPs/ranges\n", alert.ID, alert.Name, len(alert.Filters.IP))
+
+	for _, t := range splitList(*triggers) {
+		if err := client.EnableTrigger(ctx, alert.ID, t); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("[+] Trigger %s enabled\n", t)
+	}
+	for _, n := range splitList(*notifiers) {
+		if err := client.AddNotifier(ctx, alert.ID, n); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("[+] Notifier %s attached\n", n)
+	}
+}
+
+func runAlertNotify(args []string) {
+	fs, opts := newFlagSet("alert notify", "<alert-id> <notifier-id>...", "alert notify --apikey YOUR_API_KEY ABCDEF123456 abc123")
+	remove := fs.Bool("remove", false, "Detach the notifiers instead of attaching them")
+	opts.parse(fs, args, "Alert ID")
+	if fs.NArg() < 2 {
+		fmt.Println("Error: at least one notifier ID is required!")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	alertID := fs.Arg(0)
+	for _, n := range fs.Args()[1:] {
+		if *remove {
+			if err := client.RemoveNotifier(ctx, alertID, n); err != nil {
+				fatal(err)
+			}
+			fmt.Printf("[+] Notifier %s detached from %s\n", n, alertID)
+			continue
+		}
+		if err := client.AddNotifier(ctx, alertID, n); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("[+] Notifier %s attached to %s\n", n, alertID)
+	}
+}
+
+func runAlertList(args []string) {
+	fs, opts := newFlagSet("alert list", "", "alert list --apikey YOUR_API_KEY")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	alerts, err := client.Alerts(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	if len(alerts) == 0 {
+		fmt.Println("[*] No alerts")
+		return
+	}
+	for _, a := range alerts {
+		expiry := "never expires"
+		if a.Expiration != "" {
+			expiry = "expires " + a.Expiration
+		}
+		var notifiers []string
+		for _, n := range a.Notifiers {
+			notifiers = append(notifiers, n.ID)
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\tnotifiers=%s\n", a.ID, a.Name, strings.Join(a.Filters.IP, ","), expiry, strings.Join(notifiers, ","))
+	}
+}
+
+func runAlertDelete(args []string) {
+	fs, opts := newFlagSet("alert delete", "<id>...", "alert delete --apikey YOUR_API_KEY ABCDEF123456")
+	opts.parse(fs, args, "Alert ID")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	for _, id := range fs.Args() {
+		if err := client.DeleteAlert(ctx, id); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("[+] Alert %s deleted\n", id)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// Keyring entry holding the API key
+const (
+	keyringService = "shodanx"
+	keyringUser    = "api_key"
+)
+
+// apiKeyEnv is checked when --apikey is not given
+const apiKeyEnv = "SHODAN_API_KEY"
+
+// Credentials of the other sources, checked before the config file
+const (
+	censysIDEnv          = "CENSYS_API_ID"
+	censysSecretEnv      = "CENSYS_API_SECRET"
+	securityTrailsKeyEnv = "SECURITYTRAILS_API_KEY"
+	urlScanKeyEnv        = "URLSCAN_API_KEY"
+	binaryEdgeKeyEnv     = "BINARYEDGE_API_KEY"
+	fofaKeyEnv           = "FOFA_KEY"
+	fofaEmailEnv         = "FOFA_EMAIL"
+	zoomEyeKeyEnv        = "ZOOMEYE_API_KEY"
+)
+
+// keyringAPIKey returns the key stored by `shodanx auth login`, or "" if there is none
+func keyringAPIKey() string {
+	key, err := keyring.Get(keyringService, keyringUser)
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// lookupAPIKey returns the Shodan API key and where it was found: the
+// --apikey flag (flagKey), then the environment, the config file and the
+// keyring. Both are empty when there is none.
+func lookupAPIKey(flagKey string, cfg *Config) (key, origin string) {
+	if flagKey != "" {
+		return flagKey, "--apikey"
+	}
+	if key := os.Getenv(apiKeyEnv); key != "" {
+		return key, "$" + apiKeyEnv
+	}
+	if key := cfg.provider("shodan").APIKey; key != "" {
+		return key, "the config file"
+	}
+	if key := keyringAPIKey(); key != "" {
+		return key, "the OS keyring"
+	}
+	return "", ""
+}
+
+// maskKey hides an API key but its last 4 characters, and short keys
+// entirely, for messages confirming which key is used
+func maskKey(key string) string {
+	if len(key) < 16 {
+		return "***"
+	}
+	return "***" + key[len(key)-4:]
+}
+
+func authUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s auth <login|logout|status>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n  login    Store the Shodan API key in the OS keyring\n")
+	fmt.Fprintf(os.Stderr, "  logout   Remove the stored API key\n")
+	fmt.Fprintf(os.Stderr, "  status   Show which API key is used and where from: --apikey, $SHODAN_API_KEY, config file or keyring\n")
+}
+
+func runAuth(args []string) {
+	if len(args) < 1 {
+		authUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "login":
+		key, err := promptAPIKey()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := keyring.Set(keyringService, keyringUser, key); err != nil {
+			fmt.Println("Error: Failed to store API key in keyring:", err)
+			os.Exit(1)
+		}
+		fmt.Println("[+] API key stored in the OS keyring")
+
+	case "logout":
+		if err := keyring.Delete(keyringService, keyringUser); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			fmt.Println("Error: Failed to remove API key from keyring:", err)
+			os.Exit(1)
+		}
+		fmt.Println("[+] API key removed from the OS keyring")
+
+	case "status":
+		// The same lookup as every other command, --apikey and --config included
+		fs, opts := newFlagSet("auth status", "")
+		opts.keyOptional = true
+		opts.parse(fs, args[1:], "")
+		if opts.apiKey == "" {
+			fmt.Println("[!] No API key in --apikey, environment, config file or keyring")
+			return
+		}
+		fmt.Printf("[+] API key %s set via %s\n", maskKey(opts.apiKey), opts.apiKeyOrigin)
+
+	default:
+		authUsage()
+		os.Exit(1)
+	}
+}
+
+// promptAPIKey reads the key from the terminal without echoing it, or from a pipe
+func promptAPIKey() (string, error) {
+	fd := int(os.Stdin.Fd())
+	var key string
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Shodan API key: ")
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key: %w", err)
+		}
+		key = string(b)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read API key: %w", err)
+		}
+		key = line
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errors.New("empty API key")
+	}
+	return key, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runCount(args []string) {
+	fs, opts := newFlagSet("count", "<domain>",
+		"count --apikey YOUR_API_KEY example.com",
+		"count --apikey YOUR_API_KEY --facets port:20,vuln example.com")
+	query := fs.String("query", "", "Search query to count instead of hostname:\"<domain>\"")
+	facets := fs.String("facets", strings.Join(shodanx.DefaultFacets, ","), "Comma-separated facets, optionally with a size (e.g. port:20)")
+	top := fs.Int("top", 10, "Number of values shown per facet")
+	opts.parse(fs, args, "Domain")
+
+	q := *query
+	if q == "" {
+		q = shodanx.NewQuery().Filter("hostname", fs.Arg(0)).String()
+	}
+
+	var facetList []string
+	for _, f := range strings.Split(*facets, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			// Ask Shodan for as many buckets as we display
+			if !strings.Contains(f, ":") {
+				f = fmt.Sprintf("%s:%d", f, *top)
+			}
+			facetList = append(facetList, f)
+		}
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	res, err := client.Count(ctx, q, facetList)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("[*] Query: %s\n", q)
+	fmt.Printf("[+] Total results: %d\n", res.Total)
+	printFacets(os.Stdout, res.Facets, facetList, *top)
+}
+
+// printFacets prints one table per facet in the requested order
+func printFacets(w io.Writer, facets map[string][]shodanx.FacetValue, order []string, top int) {
+	for _, f := range order {
+		name := strings.SplitN(f, ":", 2)[0]
+		values := facets[name]
+		if len(values) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n[+] Top %s:\n", name)
+		for i, v := range values {
+			if i >= top {
+				break
+			}
+			fmt.Fprintf(w, "  %-40s %d\n", v.String(), v.Count)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// ctReconnectDelay is how long ct-monitor waits before reconnecting to a
+// dropped certstream feed
+const ctReconnectDelay = 5 * time.Second
+
+// ctExportTargets are the --export targets of ct-monitor; names fresh from a
+// certificate have no addresses yet, leaving nothing for OpenVAS to scan
+var ctExportTargets = []string{"elasticsearch", "kafka", "nats", "misp"}
+
+func runCTMonitor(args []string) {
+	fs, opts := newFlagSet("ct-monitor", "<domain>...",
+		"ct-monitor example.com",
+		"ct-monitor -dL domains.txt --output out/scan --jsonl certs.jsonl",
+		"ct-monitor --db results.sqlite --export kafka example.com")
+	domainList := fs.String("dL", "", "File with one apex domain per line to monitor")
+	output := fs.String("output", "", "Append new subdomains to <output>.txt, the file enum saves to; with several domains each is saved to <output>_<domain>.txt")
+	jsonl := fs.String("jsonl", "", "Append every matching certificate as JSONL to this file")
+	streamURL := fs.String("url", "", "Certstream websocket feed (default "+shodanx.DefaultCertstreamURL+" or the url of the certstream provider in the config file)")
+	backends := addBackendFlags(fs, ctExportTargets)
+	opts.keyOptional = true // certstream is free and keyless
+	opts.parse(fs, args, "")
+
+	domains := fs.Args()
+	if *domainList != "" {
+		lines, err := readLines(*domainList)
+		if err != nil {
+			fmt.Printf("Error: Failed to read %s: %v\n", *domainList, err)
+			os.Exit(1)
+		}
+		domains = append(domains, lines...)
+	}
+	for i, d := range domains {
+		domains[i] = shodanx.NormalizeHostname(d)
+	}
+	domains = shodanx.Unique(domains)
+	if len(domains) == 0 {
+		fmt.Println("Error: Domain argument is required!")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// New names are recorded like the results of an enum run
+	run := &enumRun{}
+	backends.open(run, opts.cfg)
+	defer run.closeBackends()
+
+	// Names saved or recorded by enum or earlier monitoring are not
+	// reported again
+	prefixes := map[string]string{}
+	known := map[string]map[string]bool{}
+	for _, d := range domains {
+		if *output != "" {
+			prefixes[d] = *output
+			if len(domains) > 1 {
+				prefixes[d] = *output + "_" + d
+			}
+		}
+		known[d] = map[string]bool{}
+		for name := range run.knownNames(d, prefixes[d]) {
+			known[d][name] = true
+		}
+	}
+
+	var w *bufio.Writer
+	if *jsonl != "" {
+		f, err := os.OpenFile(*jsonl, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Printf("Error: Failed to open %s: %v\n", *jsonl, err)
+			os.Exit(exitError)
+		}
+		defer f.Close()
+		w = bufio.NewWriter(f)
+		defer w.Flush()
+	}
+
+	client := opts.client()
+	if *streamURL != "" {
+		client.CertstreamURL = *streamURL
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	// A failed write stops the monitor, a dropped connection does not
+	var writeErr error
+	handle := func(e *shodanx.CTEntry) error {
+		names := e.Matching(domains)
+		if len(names) == 0 {
+			return nil
+		}
+		if w != nil {
+			data, _ := json.Marshal(e)
+			w.Write(data)
+			w.WriteByte('\n')
+			// Flush per certificate so consumers see results in real time
+			if writeErr = w.Flush(); writeErr != nil {
+				return writeErr
+			}
+		}
+		fresh := map[string][]string{}
+		for _, name := range names {
+			d := ctDomain(name, domains)
+			if known[d][name] {
+				continue
+			}
+			known[d][name] = true
+			fresh[d] = append(fresh[d], name)
+			fmt.Printf("[+] %s (issuer: %s, log: %s)\n", name, e.Issuer, e.Log)
+			if prefixes[d] != "" {
+				if writeErr = appendLine(prefixes[d]+".txt", name); writeErr != nil {
+					return writeErr
+				}
+			}
+		}
+		for _, d := range domains {
+			if len(fresh[d]) == 0 {
+				continue
+			}
+			started := time.Now()
+			result := &shodanx.Result{Domain: d, Subdomains: []string{}}
+			result.RestrictTo(d)
+			result.AddHostnames(shodanx.SourceCertstream, fresh[d]...)
+			run.record(result, started)
+		}
+		return nil
+	}
+
+	fmt.Printf("[*] Monitoring certificate transparency for %s (Ctrl-C to stop)\n", strings.Join(domains, ", "))
+	for {
+		err := client.CertStream(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if writeErr != nil {
+			fatal(writeErr)
+		}
+		// certstream drops clients now and then, so keep reconnecting
+		fmt.Printf("[!] %v, reconnecting in %s\n", err, ctReconnectDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ctReconnectDelay):
+		}
+	}
+}
+
+// ctDomain returns the most specific of domains that name belongs to
+func ctDomain(name string, domains []string) string {
+	best := ""
+	for _, d := range domains {
+		if shodanx.InDomain(name, d) && len(d) > len(best) {
+			best = d
+		}
+	}
+	return best
+}
+
+// appendLine appends a line to a file, creating it and its directory if needed
+func appendLine(path, line string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	// saveResults writes the TXT file without a trailing newline
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		line = "\n" + line
+	}
+	_, err = f.WriteString(line)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
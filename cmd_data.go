@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func dataUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s data <list|files|download> [OPTIONS]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n  list       List the bulk datasets available to the account (enterprise)\n")
+	fmt.Fprintf(os.Stderr, "  files      List the files of a dataset\n")
+	fmt.Fprintf(os.Stderr, "  download   Download dataset files, optionally keeping only a domain's banners\n")
+}
+
+func runData(args []string) {
+	if len(args) < 1 {
+		dataUsage()
+		os.Exit(exitError)
+	}
+
+	switch args[0] {
+	case "list":
+		runDataList(args[1:])
+	case "files":
+		runDataFiles(args[1:])
+	case "download":
+		runDataDownload(args[1:])
+	default:
+		dataUsage()
+		os.Exit(exitError)
+	}
+}
+
+func runDataList(args []string) {
+	fs, opts := newFlagSet("data list", "", "data list --apikey YOUR_API_KEY")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	datasets, err := client.Datasets(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	for _, d := range datasets {
+		fmt.Printf("%-20s %-10s %s\n", d.Name, d.Scope, d.Description)
+	}
+}
+
+func runDataFiles(args []string) {
+	fs, opts := newFlagSet("data files", "<dataset>", "data files --apikey YOUR_API_KEY raw-daily")
+	opts.parse(fs, args, "Dataset")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	files, err := client.DatasetFiles(ctx, fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	for _, f := range files {
+		fmt.Printf("%-30s %10s  %s\n", f.Name, formatSize(f.Size), formatTimestamp(f.Timestamp))
+	}
+}
+
+func runDataDownload(args []string) {
+	fs, opts := newFlagSet("data download", "<dataset> [file...]",
+		"data download --apikey YOUR_API_KEY raw-daily 2024-01-01.json.gz",
+		"data download --apikey YOUR_API_KEY --domain example.com --output data raw-daily")
+	outDir := fs.String("output", ".", "Directory to write the files to")
+	domain := fs.String("domain", "", "Keep only banners whose hostnames or domains fall under this domain, written as JSONL")
+	opts.parse(fs, args, "Dataset")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	files, err := client.DatasetFiles(ctx, fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+
+	// Without file names every file of the dataset is downloaded
+	if names := fs.Args()[1:]; len(names) > 0 {
+		wanted := map[string]bool{}
+		for _, n := range names {
+			wanted[n] = true
+		}
+		var selected []shodanx.DatasetFile
+		for _, f := range files {
+			if wanted[f.Name] {
+				selected = append(selected, f)
+				delete(wanted, f.Name)
+			}
+		}
+		for n := range wanted {
+			fmt.Printf("[!] No file %s in dataset %s\n", n, fs.Arg(0))
+		}
+		files = selected
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Printf("Error: Failed to create %s: %v\n", *outDir, err)
+		os.Exit(exitError)
+	}
+
+	for _, f := range files {
+		if *domain == "" {
+			fmt.Printf("[*] Downloading %s (%s)\n", f.Name, formatSize(f.Size))
+			err = downloadFile(ctx, client, f, filepath.Join(*outDir, filepath.Base(f.Name)))
+		} else {
+			fmt.Printf("[*] Filtering %s (%s) for %s\n", f.Name, formatSize(f.Size), *domain)
+			err = filterFile(ctx, client, f, filepath.Join(*outDir, strings.TrimSuffix(filepath.Base(f.Name), ".gz")), *domain)
+		}
+		if ctx.Err() != nil {
+			fmt.Println("\n[!] Download interrupted")
+			os.Exit(exitError)
+		}
+		if err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// downloadFile saves a dataset file unchanged
+func downloadFile(ctx context.Context, client *shodanx.Client, f shodanx.DatasetFile, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := client.DownloadDatasetFile(ctx, f, out); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Saved %s\n", path)
+	return nil
+}
+
+// filterFile writes the banners of a dataset file that match domain as JSONL
+func filterFile(ctx context.Context, client *shodanx.Client, f shodanx.DatasetFile, path, domain string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+
+	kept := 0
+	err = client.ReadDatasetFile(ctx, f, func(b *shodanx.StreamBanner) error {
+		if !bannerMatches(&b.Match, domain) {
+			return nil
+		}
+		kept++
+		w.Write(b.Raw)
+		return w.WriteByte('\n')
+	})
+	if err != nil {
+		w.Flush()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Saved %d matching banners to %s\n", kept, path)
+	return nil
+}
+
+// formatSize prints a byte count in human readable units
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatTimestamp prints a dataset timestamp in milliseconds since the epoch
+func formatTimestamp(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format("2006-01-02 15:04")
+}
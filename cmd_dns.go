@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runDNS(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "resolve":
+			runDNSResolve(args[1:])
+			return
+		case "reverse":
+			runDNSReverse(args[1:])
+			return
+		}
+	}
+
+	fs, opts := newFlagSet("dns", "<domain>",
+		"dns --apikey YOUR_API_KEY example.com",
+		"dns resolve --apikey YOUR_API_KEY --input subs.txt",
+		"dns reverse --apikey YOUR_API_KEY 203.0.113.0/24")
+	records := fs.Bool("records", false, "Print DNS records instead of subdomains")
+	opts.parse(fs, args, "Domain")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	res, err := client.DNSDomain(ctx, fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+
+	if *records {
+		for _, r := range res.Data {
+			fmt.Printf("%s\t%s\t%s\t%s\n", r.Subdomain, r.Type, r.Value, r.LastSeen)
+		}
+		return
+	}
+
+	for _, h := range res.Hostnames() {
+		fmt.Println(h)
+	}
+}
+
+// runDNSResolve batch-resolves hostnames through Shodan
+func runDNSResolve(args []string) {
+	fs, opts := newFlagSet("dns resolve", "<hostname>...", "dns resolve --apikey YOUR_API_KEY --input subs.txt")
+	input := fs.String("input", "", "File with one hostname per line")
+	opts.parse(fs, args, "")
+
+	names := fs.Args()
+	if *input != "" {
+		lines, err := readLines(*input)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(exitError)
+		}
+		names = append(names, lines...)
+	}
+	if len(names) == 0 {
+		fmt.Println("Error: at least one hostname is required!")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	resolved, err := client.Resolve(ctx, shodanx.Unique(names))
+	for _, name := range shodanx.Unique(names) {
+		if ip, ok := resolved[name]; ok {
+			fmt.Printf("%s\t%s\n", name, ip)
+		}
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// runDNSReverse reverse-resolves IPs and ranges through Shodan
+func runDNSReverse(args []string) {
+	fs, opts := newFlagSet("dns reverse", "<ip|cidr>...", "dns reverse --apikey YOUR_API_KEY 203.0.113.0/24")
+	input := fs.String("input", "", "File with one IP or CIDR per line")
+	opts.parse(fs, args, "")
+
+	var ips []string
+	for _, t := range readTargets(fs, fs.Args(), *input) {
+		expanded, err := shodanx.ExpandCIDR(t)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(exitError)
+		}
+		ips = append(ips, expanded...)
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	names, err := client.Reverse(ctx, shodanx.Unique(ips))
+	found := make([]string, 0, len(names))
+	for ip := range names {
+		found = append(found, ip)
+	}
+	sort.Slice(found, func(i, j int) bool { return ipLess(found[i], found[j]) })
+	for _, ip := range found {
+		fmt.Printf("%s\t%s\n", ip, strings.Join(names[ip], ","))
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
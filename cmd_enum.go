@@ -0,0 +1,1248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"plugin"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runEnum(args []string) {
+	fs, opts := newFlagSet("enum", "<domain>",
+		"enum --apikey YOUR_API_KEY --output results example.com",
+		"enum --apikey YOUR_API_KEY .mil",
+		"enum --apikey YOUR_API_KEY -dL domains.txt --output out/scan",
+		"enum - < domains.txt | httpx")
+	domainList := fs.String("dL", "", "File with one apex domain per line to enumerate in one run")
+	output := fs.String("output", "", "Output file name (without extension); with -dL each domain is saved as <output>_<domain>")
+	outputTmpl := fs.String("template", "", "Go text/template file to render the results through, to <output>_<template name without .tmpl> or else stdout (default: config file)")
+	upload := fs.String("upload", "", "Upload the files written by --output to cloud storage after each run, under <prefix>/<run start>/: s3://bucket/scans, gs://bucket/scans or azblob://container/scans (default: config file)")
+	backends := addBackendFlags(fs, exportTargets)
+	format := fs.String("format", "", "Comma-separated formats written by --output: "+strings.Join(outputFormats, ", ")+" (default: config file, else txt,json); jsonl streams assets as they are found, to stdout with - input, where urls also replaces the names")
+	internetDB := fs.Bool("internetdb", false, "Resolve subdomains and enrich their IPs via the free InternetDB (ports, CPEs, vulns, tags)")
+	honeyscore := fs.Bool("honeyscore", false, "Resolve subdomains and flag IPs that look like honeypots")
+	resolve := fs.Bool("resolve", false, "Resolve every subdomain (A/AAAA/CNAME) and note which ones resolve")
+	probe := fs.Bool("probe", false, "Check HTTPS/HTTP on resolved subdomains and record status, title, server and redirect (implies --resolve)")
+	waf := fs.Bool("waf", false, "Also send an attack-like request to probed hosts without a recognisable WAF and flag those that block it (implies --probe)")
+	favicon := fs.Bool("favicon", false, "Hash the favicons of probed hosts and search http.favicon.hash for other services serving them (implies --probe; costs query credits)")
+	screenshots := fs.String("screenshots", "", "Directory to save PNG screenshots of probed web servers to, taken with headless Chrome (implies --probe)")
+	probeTimeout := fs.Duration("probe-timeout", shodanx.DefaultProbeTimeout, "Timeout per HTTP probe")
+	cloud := fs.Bool("cloud", false, "Resolve subdomains and attribute their IPs to AWS/GCP/Azure/DigitalOcean by the published ranges, with region")
+	azureRanges := fs.String("azure-ranges", "", "Azure \"Service Tags - Public\" JSON file to include Azure in --cloud (implies --cloud)")
+	vulnReport := fs.Bool("vuln-report", false, "Print the CVEs Shodan lists for the matched services, most severe first")
+	skipCDN := fs.Bool("skip-cdn", false, "Leave the IPs of CDN-fronted subdomains (Cloudflare, Akamai, Fastly, CloudFront) out of InternetDB and honeyscore enrichment (implies --resolve)")
+	buckets := fs.Bool("buckets", false, "Find S3, GCS and Azure Blob buckets behind the subdomains and their CNAMEs and check whether they are publicly listable (implies --resolve)")
+	mailRecords := fs.Bool("mail", false, "Look up MX, TXT, SPF and DMARC records of the domain and its subdomains, listing third-party mail hosts and weak email security")
+	rdns := fs.String("rdns", "", "Reverse-resolve the IPs of matched services and add new subdomains: local (PTR lookups via --resolvers) or shodan (/dns/reverse)")
+	resolvers := fs.String("resolvers", "", "Comma-separated DNS servers (ip or ip:port), or a file with one per line, instead of the system resolver")
+	workers := fs.Int("workers", 10, "Concurrent DNS/InternetDB/honeyscore lookups and HTTP probes")
+	maxPages := fs.Int("max-pages", 0, "Maximum result pages per query, each page after the first costs a query credit (0 = all; default set by --profile)")
+	profile := fs.String("profile", "standard", "Query profile: "+profileNames())
+	queriesFile := fs.String("queries", "", "File of query templates, one per line, e.g. ssl.cert.subject.cn:\"{{.Domain}}\" (replaces the built-in list)")
+	extend := fs.Bool("extend", false, "Run the --queries/config templates in addition to the profile's queries")
+	noBroad := fs.Bool("no-broad", true, "Skip the noisy built-in "+strings.Join(shodanx.BroadFilters, "/")+" queries (--no-broad=false runs them; the thorough profile runs them unless set)")
+	excludeQueries := fs.String("exclude-queries", "", "Comma-separated patterns of queries to skip, matched against the query or its filter name (* is a wildcard, e.g. http.*)")
+	sourceList := fs.String("sources", "shodan", "Comma-separated sources to enumerate with: "+strings.Join(enumSources(), ", ")+"; leave out shodan to run only the others")
+	plugins := fs.String("plugins", "", "Comma-separated Go plugins (.so) that register additional sources")
+	freeOnly := fs.Bool("free-only", false, "Use only the free, keyless sources ("+strings.Join(freeSources, ", ")+") instead of --sources; no Shodan key or plan needed")
+	crtsh := fs.Bool("crtsh", false, "Also search certificate transparency logs on crt.sh, which find names Shodan never saw served (free, no API key)")
+	censys := fs.Bool("censys", false, "Also search Censys hosts and certificates and merge them with the Shodan matches (credentials from $"+censysIDEnv+"/$"+censysSecretEnv+" or the config file)")
+	securityTrails := fs.Bool("securitytrails", false, "Also add the subdomains and DNS history of the domain from SecurityTrails (key from $"+securityTrailsKeyEnv+" or the config file)")
+	archives := fs.Bool("archives", false, "Also harvest names from URLs archived by the Wayback Machine and scanned by urlscan.io (free; optional key in $"+urlScanKeyEnv+" or the config file)")
+	recursive := fs.Bool("recursive", false, "Re-run hostname and certificate queries against the intermediate levels of discovered subdomains")
+	depth := fs.Int("depth", 1, "Recursive passes with --recursive")
+	includeRelated := fs.Bool("include-related", false, "Keep names found by the queries that are not under the domain, saved to <output>_related.txt")
+	scopeFile := fs.String("scope", "", "File of include/exclude rules (globs, re: regexes, ! to exclude); out-of-scope hostnames are dropped")
+	planOnly := fs.Bool("dry-run", false, "Print the queries and estimate the query credits of the run using free counts, without searching")
+	country := fs.String("country", "", "Only match services in these comma-separated country codes (e.g. DE,FR)")
+	port := fs.String("port", "", "Only match services on these comma-separated ports (e.g. 443,8443)")
+	product := fs.String("product", "", "Only match services running this product (e.g. nginx)")
+	asn := fs.String("asn", "", "Without a domain: find the hostnames and netblocks of these comma-separated ASNs (e.g. AS15169); with one: only match services in them")
+	ipList := fs.String("ips", "", "File of IPs to resolve back to hostnames via host lookups and reverse DNS; with a domain, names under it are merged into the results")
+	cidr := fs.String("cidr", "", "Without a domain: find the hostnames in these comma-separated ranges via net: and reverse DNS; with one: only match services in them")
+	org := fs.String("org", "", "Without a domain: find the domains, hostnames and netblocks of this organisation; with one: only match its services")
+
+	// Domains may come only from -dL, so don't require a positional argument.
+	// Whether a Shodan key is needed depends on --sources, checked below.
+	opts.keyOptional = true
+	opts.parse(fs, args, "")
+	// "-" reads domains from stdin and keeps stdout for plain subdomains, so
+	// the output can be piped into httpx, dnsx, nuclei and the like
+	var domains []string
+	pipeline := false
+	for _, arg := range fs.Args() {
+		if arg != "-" {
+			domains = append(domains, arg)
+			continue
+		}
+		pipeline = true
+		lines, err := readStdinLines()
+		if err != nil {
+			fmt.Println("Error: Failed to read stdin:", err)
+			os.Exit(1)
+		}
+		domains = append(domains, lines...)
+	}
+	if *domainList != "" {
+		lines, err := readLines(*domainList)
+		if err != nil {
+			fmt.Printf("Error: Failed to read %s: %v\n", *domainList, err)
+			os.Exit(1)
+		}
+		domains = append(domains, lines...)
+	}
+	// Queries and results use the normalized, punycode form of the domains
+	for i, d := range domains {
+		domains[i] = shodanx.NormalizeHostname(d)
+	}
+	domains = shodanx.Unique(domains)
+	var ips []string
+	if *ipList != "" {
+		ips = readTargets(fs, nil, *ipList)
+	}
+	if len(domains) == 0 && *org == "" && *asn == "" && *cidr == "" && len(ips) == 0 {
+		fmt.Println("Error: Domain argument is required!")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// --org, --asn and --cidr scope the queries of a domain. Without one, the
+	// first of them that is set becomes the pivot target.
+	scopeOrg, scopeASN, scopeCIDR := *org, *asn, *cidr
+	if len(domains) == 0 {
+		switch {
+		case *org != "":
+			scopeOrg = ""
+		case *asn != "":
+			scopeASN = ""
+		case *cidr != "":
+			scopeCIDR = ""
+		}
+	}
+
+	// --format replaces the formats of the config file
+	formats := opts.cfg.Formats
+	if *format != "" {
+		formats = splitList(strings.ToLower(*format))
+	}
+	for _, f := range formats {
+		if !hasFormat(outputFormats, f) {
+			fmt.Printf("Error: unknown output format %q (available: %s)\n", f, strings.Join(outputFormats, ", "))
+			os.Exit(1)
+		}
+	}
+
+	// Every stage that needs the web servers of the subdomains probes them first
+	probing := *probe || *waf || *favicon || *screenshots != ""
+	run := &enumRun{
+		results:        os.Stdout,
+		log:            os.Stdout,
+		pipeline:       pipeline,
+		internetDB:     *internetDB,
+		honeyscore:     *honeyscore,
+		resolve:        *resolve || probing || *buckets || *skipCDN,
+		skipCDN:        *skipCDN,
+		vulnReport:     *vulnReport,
+		rdns:           *rdns,
+		mail:           *mailRecords,
+		buckets:        *buckets,
+		cloud:          *cloud || *azureRanges != "",
+		azureRanges:    *azureRanges,
+		prober:         proberFor(probing, *waf, *favicon, *workers, *probeTimeout),
+		screenshots:    *screenshots,
+		resolver:       &shodanx.DNSResolver{Servers: readList(*resolvers), Workers: *workers},
+		workers:        *workers,
+		formats:        formats,
+		extend:         *extend,
+		ips:            ips,
+		includeRelated: *includeRelated,
+		templates:      opts.cfg.Queries,
+	}
+	if pipeline {
+		run.log = os.Stderr
+	}
+	opts.log = run.log
+	prof, err := shodanx.LookupProfile(*profile)
+	if err != nil {
+		fmt.Fprintln(run.log, "Error:", err)
+		os.Exit(1)
+	}
+	run.profile = prof
+	if *rdns != "" && *rdns != "local" && *rdns != "shodan" {
+		fmt.Fprintf(run.log, "Error: --rdns must be local or shodan, not %q\n", *rdns)
+		os.Exit(1)
+	}
+	// Plugins register their sources from init, before --sources is checked
+	loadPlugins(run.log, splitList(*plugins))
+	if *freeOnly {
+		if isFlagSet(fs, "sources") {
+			fmt.Fprintln(run.log, "[!] --free-only replaces --sources")
+		}
+		*sourceList = strings.Join(freeSources, ",")
+	}
+	sources, err := parseSources(*sourceList)
+	if err != nil {
+		fmt.Fprintln(run.log, "Error:", err)
+		os.Exit(1)
+	}
+	// The flags of the other sources add to --sources
+	for name, on := range map[string]bool{"crtsh": *crtsh, "censys": *censys, "securitytrails": *securityTrails, "archives": *archives} {
+		if on {
+			sources[name] = true
+		}
+	}
+	// Pivots and the Shodan-backed stages need a key even without the shodan source
+	needsKey := sources["shodan"] || len(domains) == 0 || *honeyscore || *favicon || *rdns == "shodan"
+	if needsKey && opts.apiKey == "" {
+		fmt.Fprintln(run.log, "Error: Shodan API key is required!")
+		fs.Usage()
+		os.Exit(1)
+	}
+	run.base = shodanx.EnumerateOptions{
+		Filters:    scopeFilters(*country, *port, *product, scopeASN, scopeOrg, scopeCIDR),
+		Exclude:    splitList(*excludeQueries),
+		MaxPages:   -1,
+		SkipShodan: !sources["shodan"],
+	}
+	for _, name := range sortedSources(sources) {
+		if name != "shodan" {
+			run.base.Sources = append(run.base.Sources, name)
+		}
+	}
+	if *recursive {
+		run.base.Depth = *depth
+	}
+	if isFlagSet(fs, "max-pages") {
+		run.base.MaxPages = *maxPages
+	}
+	if isFlagSet(fs, "no-broad") {
+		run.broad = new(bool)
+		*run.broad = !*noBroad
+	}
+	if *scopeFile != "" {
+		lines, err := readLines(*scopeFile)
+		if err == nil {
+			run.scope, err = shodanx.ParseScope(lines)
+		}
+		if err != nil {
+			fmt.Fprintf(run.log, "Error: Failed to load scope %s: %v\n", *scopeFile, err)
+			os.Exit(1)
+		}
+	}
+	if *queriesFile != "" {
+		lines, err := readLines(*queriesFile)
+		if err != nil {
+			fmt.Fprintf(run.log, "Error: Failed to read %s: %v\n", *queriesFile, err)
+			os.Exit(1)
+		}
+		run.templates = lines
+	}
+
+	if opts.apiKey != "" {
+		fmt.Fprintf(run.log, "[*] Using API key: %s\n", maskKey(opts.apiKey))
+	}
+	fmt.Fprintf(run.log, "[*] Sources: %s\n", strings.Join(sortedSources(sources), ", "))
+	fmt.Fprintf(run.log, "[*] Profile: %s (%s)\n", prof.Name, prof.Description)
+	if run.base.Filters != "" {
+		fmt.Fprintf(run.log, "[*] Scoping every query with: %s\n", run.base.Filters)
+	}
+
+	client := opts.client()
+	if sources["censys"] && !client.HasCensys() {
+		fmt.Fprintf(run.log, "Error: --censys needs Censys credentials in $%s/$%s or the censys section of the config file\n", censysIDEnv, censysSecretEnv)
+		os.Exit(1)
+	}
+	if sources["securitytrails"] && !client.HasSecurityTrails() {
+		fmt.Fprintf(run.log, "Error: --securitytrails needs a SecurityTrails API key in $%s or the securitytrails section of the config file\n", securityTrailsKeyEnv)
+		os.Exit(1)
+	}
+	if sources["binaryedge"] && !client.HasBinaryEdge() {
+		fmt.Fprintf(run.log, "Error: the binaryedge source needs a BinaryEdge API key in $%s or the binaryedge section of the config file\n", binaryEdgeKeyEnv)
+		os.Exit(1)
+	}
+	if sources["fofa"] && !client.HasFofa() {
+		fmt.Fprintf(run.log, "Error: the fofa source needs a FOFA API key in $%s or the fofa section of the config file\n", fofaKeyEnv)
+		os.Exit(1)
+	}
+	if sources["zoomeye"] && !client.HasZoomEye() {
+		fmt.Fprintf(run.log, "Error: the zoomeye source needs a ZoomEye API key in $%s or the zoomeye section of the config file\n", zoomEyeKeyEnv)
+		os.Exit(1)
+	}
+
+	if *outputTmpl == "" {
+		*outputTmpl = opts.cfg.Template
+	}
+	if *outputTmpl != "" {
+		tmpl, err := loadOutputTemplate(*outputTmpl)
+		if err != nil {
+			fmt.Fprintf(run.log, "Error: Failed to load template: %v\n", err)
+			os.Exit(1)
+		}
+		run.outputTemplate = tmpl
+		// The rendered file is uploaded with the others
+		run.artifactSuffixes = append(run.artifactSuffixes, tmpl.suffix)
+	}
+	if *upload == "" {
+		*upload = opts.cfg.Upload
+	}
+	if *upload != "" {
+		if *output == "" {
+			fmt.Fprintln(run.log, "Error: --upload needs --output")
+			os.Exit(1)
+		}
+		uploader, err := newArtifactUploader(*upload)
+		if err != nil {
+			fmt.Fprintf(run.log, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		run.uploader = uploader
+	}
+	backends.open(run, opts.cfg)
+	defer run.closeBackends()
+	// Only the banner archives need the banners as Shodan returned them
+	client.KeepRaw = run.onMatch() != nil
+
+	// Cancel in-flight requests on Ctrl-C/SIGTERM but keep what was found so far
+	ctx, stop := signalContext()
+	defer stop()
+	run.stop = stop
+
+	// Without a domain the organisation or network itself is the target
+	if len(domains) == 0 {
+		var target string
+		var queries []string
+		pivotOpts := shodanx.PivotOptions{Filters: run.base.Filters}
+		switch {
+		case *org != "":
+			target, queries = *org, shodanx.OrgQueries(*org)
+		case *asn != "":
+			target, queries = *asn, shodanx.ASNQueries(splitList(*asn)...)
+		case *cidr != "":
+			ranges := readTargets(fs, splitList(*cidr), "")
+			target, queries = *cidr, shodanx.CIDRQueries(ranges...)
+			pivotOpts.Reverse = ranges
+		default:
+			target = *ipList
+			pivotOpts.Hosts, pivotOpts.Reverse = ips, ips
+		}
+		pivotOpts.MaxPages = run.options(target).MaxPages
+		pivotOpts.OnMatch = run.onMatch()
+		if *planOnly {
+			dryRun(run.log, ctx, client, target, pivotOpts.Options(queries))
+			return
+		}
+		checkCredits(run.log, ctx, client)
+		run.pivot(ctx, client, target, queries, pivotOpts, *output)
+		return
+	}
+	if *planOnly {
+		for _, domain := range domains {
+			dryRun(run.log, ctx, client, domain, run.options(domain))
+		}
+		return
+	}
+	if needsKey {
+		checkCredits(run.log, ctx, client)
+	}
+
+	if len(domains) == 1 {
+		_, err := run.enumerate(ctx, client, domains[0], *output)
+		interrupted := ctx.Err() != nil
+		if err != nil && !interrupted {
+			fatalTo(run.log, err)
+		}
+		return
+	}
+
+	// Stop at the first interrupt or fatal API error, keeping finished domains
+	var summaries []domainSummary
+	for i, domain := range domains {
+		fmt.Fprintf(run.log, "\n[*] Domain %d/%d\n", i+1, len(domains))
+		prefix := ""
+		if *output != "" {
+			prefix = *output + "_" + strings.Trim(domain, ".")
+		}
+		before := client.CreditsUsed()
+		result, err := run.enumerate(ctx, client, domain, prefix)
+		summaries = append(summaries, domainSummary{domain, len(result.Subdomains), len(result.Exposed), client.CreditsUsed() - before, err})
+		if err != nil {
+			break
+		}
+	}
+	interrupted := ctx.Err() != nil
+
+	fmt.Fprintf(run.log, "\n[+] Summary of %d of %d domains:\n", len(summaries), len(domains))
+	total := 0
+	for _, s := range summaries {
+		status := "ok"
+		if s.err != nil {
+			status = s.err.Error()
+		}
+		fmt.Fprintf(run.log, "  %-40s %6d subdomains %4d exposed %5d credits  %s\n", s.domain, s.subdomains, s.exposed, s.credits, status)
+		total += s.subdomains
+	}
+	fmt.Fprintf(run.log, "[+] %d subdomains, %d query credits in total\n", total, client.CreditsUsed())
+
+	if last := summaries[len(summaries)-1]; last.err != nil && !interrupted {
+		fatalTo(run.log, last.err)
+	}
+}
+
+// domainSummary is one row of the -dL summary
+type domainSummary struct {
+	domain     string
+	subdomains int
+	exposed    int // high-risk services
+	credits    int
+	err        error
+}
+
+// enumRun holds the enum settings shared by every target domain
+type enumRun struct {
+	profile     shodanx.Profile
+	base        shodanx.EnumerateOptions // MaxPages < 0 keeps the profile's
+	broad       *bool                    // --no-broad inverted; nil keeps the profile's
+	templates   []string
+	extend      bool
+	resolve     bool
+	resolver    *shodanx.DNSResolver
+	prober      *shodanx.Prober // nil disables probing
+	screenshots string
+	skipCDN     bool
+	vulnReport  bool
+	rdns        string // "local", "shodan" or "" for no reverse lookups
+	mail        bool
+	buckets     bool
+	cloud       bool
+	azureRanges string
+	cloudRanges *shodanx.CloudRanges // fetched once for every domain
+	internetDB  bool
+	honeyscore  bool
+	workers     int
+	formats     []string
+	store       *resultStore // nil without --db
+	mongo       *mongoStore  // nil without --mongo
+	es          *esExporter  // nil without --export elasticsearch
+	kafka       *kafkaPublisher
+	nats        *natsPublisher
+	misp        *mispExporter     // nil without --export misp
+	gvm         *gvmExporter      // nil without --export openvas
+	uploader    *artifactUploader // nil without --upload
+
+	// artifactSuffixes are the files of this run uploaded besides the
+	// standard ones, e.g. the --template output
+	artifactSuffixes []string
+
+	// outputTemplate renders the results of --template; nil without it
+	outputTemplate *outputTemplate
+
+	// banners collects the banners matched by the current run for the
+	// stores that keep them, see onMatch
+	banners []*shodanx.Match
+
+	// ips are looked up after the first domain; the names under each domain
+	// are merged into its result
+	ips     []string
+	ipNames *shodanx.Result
+
+	// scope drops out-of-scope hostnames before output; nil keeps all
+	scope *shodanx.Scope
+
+	// includeRelated keeps the names outside the target domain
+	includeRelated bool
+
+	// results receives the subdomains; in pipeline mode they are written
+	// one per line without addresses or other decoration
+	results  io.Writer
+	pipeline bool
+
+	// log receives the progress, warnings and errors: stderr in pipeline
+	// mode, so that only the results go to stdout
+	log io.Writer
+
+	// stop releases the interrupt handler
+	stop context.CancelFunc
+}
+
+// options returns the EnumerateOptions for domain, exiting on invalid templates
+func (r *enumRun) options(domain string) shodanx.EnumerateOptions {
+	opts := r.profile.Options(domain)
+	opts.Filters = r.base.Filters
+	if r.broad != nil {
+		opts.IncludeBroad = *r.broad
+	}
+	opts.Exclude = r.base.Exclude
+	opts.Depth = r.base.Depth
+	opts.SkipShodan = r.base.SkipShodan
+	opts.Sources = r.base.Sources
+	if r.base.MaxPages >= 0 {
+		opts.MaxPages = r.base.MaxPages
+	}
+	if len(r.templates) > 0 {
+		q, err := shodanx.ExpandQueries(r.templates, domain)
+		if err != nil {
+			fmt.Fprintln(r.log, "Error:", err)
+			os.Exit(1)
+		}
+		if r.extend {
+			opts.Extra = q
+		} else {
+			opts.Queries = q
+		}
+	}
+	return opts
+}
+
+// enumerate runs the enumeration of a single domain, prints and saves the
+// result under outputPrefix (if set). The result is never nil; err is the
+// fatal API error or interrupt that stopped the run.
+func (r *enumRun) enumerate(ctx context.Context, client *shodanx.Client, domain, outputPrefix string) (*shodanx.Result, error) {
+	fmt.Fprintf(r.log, "[*] Starting scan for domain: %s\n", domain)
+	if apex, err := shodanx.RegistrableDomain(domain); err == nil && !strings.HasPrefix(domain, ".") && !shodanx.IsApex(domain) {
+		fmt.Fprintf(r.log, "[*] %s is a subdomain of %s, only names under it are kept\n", domain, apex)
+	}
+	before := client.CreditsUsed()
+	started := time.Now()
+
+	opts := r.options(domain)
+	opts.OnMatch = r.onMatch()
+	stream := r.assetStream(domain, outputPrefix)
+	if stream != nil {
+		opts.OnFound = func(name, source string) {
+			if r.scope == nil || net.ParseIP(name) != nil || r.scope.InScope(name) {
+				stream.add(name, source)
+			}
+		}
+	}
+	result, err := client.Enumerate(ctx, domain, opts)
+	if len(r.ips) > 0 && err == nil {
+		// The IPs are looked up once and shared by every domain
+		if r.ipNames == nil {
+			r.ipNames, err = client.Pivot(ctx, domain, nil, shodanx.PivotOptions{Hosts: r.ips, Reverse: r.ips})
+			if !shodanx.IsFatal(err) {
+				err = nil
+			}
+		}
+		n := len(result.Subdomains)
+		result.Merge(r.ipNames, domain)
+		fmt.Fprintf(r.log, "[+] %d new subdomains from %d IPs\n", len(result.Subdomains)-n, len(r.ips))
+	}
+	if r.rdns != "" && err == nil && ctx.Err() == nil {
+		err = r.reverseDNS(ctx, client, domain, result)
+	}
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		r.stop() // a second Ctrl-C while saving terminates immediately
+		fmt.Fprintln(r.log, "\n[!] Scan interrupted, keeping partial results")
+	} else if err != nil {
+		fmt.Fprintf(r.log, "\n[!] Scan aborted: %v\n", err)
+	}
+	r.applyScope(result)
+	if len(result.Related) > 0 {
+		if r.includeRelated {
+			fmt.Fprintf(r.log, "[*] %d related names on %d other domains kept separately\n", len(result.Related), len(shodanx.GroupByDomain(result.Related)))
+		} else {
+			fmt.Fprintf(r.log, "[*] Ignored %d names outside %s (see --include-related)\n", len(result.Related), domain)
+			result.Related = nil
+		}
+	}
+	allSubs := result.Subdomains
+	fmt.Fprintf(r.log, "[*] Query credits used: %d\n", client.CreditsUsed()-before)
+	printTruncated(r.log, result, opts.MaxPages)
+
+	if (r.resolve || r.internetDB || r.honeyscore || r.cloud) && !interrupted && len(allSubs) > 0 {
+		fmt.Fprintf(r.log, "[*] Resolving %d subdomains\n", len(allSubs))
+		result.AddResolutions(r.resolver.Resolve(ctx, allSubs))
+		fmt.Fprintf(r.log, "[*] %d of %d subdomains resolve\n", len(result.IPs), len(allSubs))
+		if !r.resolve {
+			result.CNAMEs, result.Unresolved = nil, nil
+		}
+		if len(result.CDN) > 0 {
+			fmt.Fprintf(r.log, "[*] %d subdomains are fronted by a CDN\n", len(result.CDN))
+		}
+		if n := archivedOnly(result); n > 0 {
+			fmt.Fprintf(r.log, "[*] %d names only seen in web archives no longer resolve\n", n)
+		}
+		addrs := shodanx.Addresses(result.IPs)
+		if r.skipCDN {
+			// A CDN edge answers for many customers, so its ports say nothing about the origin
+			addrs = shodanx.Addresses(result.Origins())
+		}
+		if r.prober != nil {
+			alive := make([]string, 0, len(result.IPs))
+			for _, name := range allSubs {
+				if len(result.IPs[name]) > 0 {
+					alive = append(alive, name)
+				}
+			}
+			fmt.Fprintf(r.log, "[*] Probing %d resolved subdomains for web servers\n", len(alive))
+			result.Probes = r.prober.Probe(ctx, alive)
+			shielded := 0
+			for _, p := range result.Probes {
+				if p.WAF != "" {
+					shielded++
+				}
+			}
+			if shielded > 0 {
+				fmt.Fprintf(r.log, "[*] %d of %d web servers are behind a WAF\n", shielded, len(result.Probes))
+			}
+			if r.prober.Favicon {
+				r.pivotFavicons(ctx, client, domain, result)
+			}
+			if r.screenshots != "" && len(result.Probes) > 0 {
+				fmt.Fprintf(r.log, "[*] Taking screenshots of %d web servers\n", len(result.Probes))
+				shooter := &shodanx.Screenshotter{Dir: r.screenshots, Workers: r.workers}
+				if n, err := shooter.Capture(ctx, result.Probes); err != nil {
+					fmt.Fprintf(r.log, "[!] Screenshots skipped: %v\n", err)
+				} else {
+					fmt.Fprintf(r.log, "[+] %d screenshots saved to %s\n", n, r.screenshots)
+				}
+			}
+		}
+		if r.cloud {
+			r.attributeCloud(ctx, client, result)
+		}
+		if r.internetDB {
+			fmt.Fprintf(r.log, "[*] Enriching %d IPs via InternetDB\n", len(addrs))
+			result.InternetDB = client.LookupInternetDB(ctx, addrs, r.workers)
+			result.AddInternetDBExposures()
+		}
+		if r.honeyscore {
+			fmt.Fprintf(r.log, "[*] Checking %d IPs for honeypots\n", len(addrs))
+			result.Honeyscores = client.Honeyscores(ctx, addrs, r.workers)
+		}
+	}
+
+	if r.buckets && !interrupted {
+		result.Buckets = result.FindBuckets()
+		if len(result.Buckets) > 0 {
+			fmt.Fprintf(r.log, "[*] Checking %d storage buckets for public listing\n", len(result.Buckets))
+			shodanx.CheckBuckets(ctx, client.HTTPClient, result.Buckets, r.workers)
+		}
+	}
+
+	if r.mail && !interrupted {
+		fmt.Fprintf(r.log, "[*] Looking up email records of %s and %d subdomains\n", result.Domain, len(allSubs))
+		result.Mail = r.resolver.MailRecords(ctx, result.Domain, allSubs)
+	}
+
+	// Unresolved certificate names are only known after resolution
+	result.FindLeaks()
+	result.ScoreRisk()
+	if !r.pipeline {
+		allSubs = result.ByRisk() // riskiest first for triage
+	}
+
+	// Without files, the URLs of the web servers or the template output are
+	// piped instead of the names
+	pipeURLs := r.pipeline && outputPrefix == "" && hasFormat(r.formats, "urls")
+	pipeTemplate := r.pipeline && outputPrefix == "" && r.outputTemplate != nil
+	fmt.Fprintf(r.log, "\n[+] Found %d unique subdomains:\n", len(allSubs))
+	for _, s := range allSubs {
+		if r.pipeline {
+			// Streamed JSONL takes the place of the plain names
+			if (stream == nil || stream.file != nil) && !pipeURLs && !pipeTemplate {
+				fmt.Fprintln(r.results, s)
+			}
+			continue
+		}
+		name := displayName(s)
+		if result.IPs == nil {
+			fmt.Fprintln(r.log, name)
+			continue
+		}
+		if cname := result.CNAMEs[s]; cname != "" {
+			name += " -> " + cname
+		}
+		if cdn := result.CDN[s]; cdn != "" {
+			name += " [cdn:" + cdn + "]"
+		}
+		if score := result.RiskOf(s); score > 0 {
+			name += fmt.Sprintf(" [risk:%d]", score)
+		}
+		fmt.Fprintf(r.log, "%s %s\n", name, formatAddresses(result.IPs[s], result.InternetDB, result.Honeyscores, result.Cloud))
+	}
+
+	if pipeURLs {
+		for _, u := range webURLs(result) {
+			fmt.Fprintln(r.results, u)
+		}
+	}
+
+	if len(result.Probes) > 0 && !r.pipeline {
+		fmt.Fprintf(r.log, "\n[+] Found %d web servers:\n", len(result.Probes))
+		for _, s := range allSubs {
+			if p := result.Probes[s]; p != nil {
+				fmt.Fprintln(r.log, formatProbe(p))
+			}
+		}
+	}
+
+	if !r.pipeline {
+		printRisks(result)
+		printExposures(result)
+		printServices(result)
+		printMail(result)
+		printBuckets(result)
+		printLeaks(result)
+		printFaviconMatches(result, domain)
+		printCertFindings(result)
+		printDNSHistory(result)
+		if r.vulnReport {
+			printVulnReport(result)
+		}
+	}
+
+	if len(result.Addresses) > 0 && !r.pipeline {
+		fmt.Fprintf(r.log, "\n[+] Found %d IP addresses in place of hostnames:\n", len(result.Addresses))
+		for _, ip := range result.Addresses {
+			if c := result.Cloud[ip]; c != nil {
+				fmt.Fprintf(r.log, "%s [cloud=%s]\n", ip, formatCloud(c))
+				continue
+			}
+			fmt.Fprintln(r.log, ip)
+		}
+	}
+
+	if len(result.Summary) > 0 {
+		fmt.Fprintln(r.log, "\n[+] Exposure summary across all matched services:")
+		printFacets(r.log, result.Summary, shodanx.DefaultFacets, shodanx.SummaryTop)
+	}
+	if len(result.Exposed) > 0 {
+		fmt.Fprintf(r.log, "[!] High-risk services exposed: %s\n", exposureCounts(result.Exposed))
+	}
+
+	// The previous run's names are read before the new results replace them
+	known := r.knownNames(result.Domain, outputPrefix)
+	if outputPrefix != "" {
+		keepFirstSeen(result, outputPrefix)
+	}
+	printStats(r.log, result, known, client.CreditsUsed()-before)
+
+	if stream != nil {
+		if err := stream.finish(r.log, result); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to write JSONL results: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	r.renderTemplate(result, outputPrefix)
+
+	// IMPROVED SAVING WITH ERROR HANDLING AND FALLBACK
+	if outputPrefix != "" {
+		if err := saveResults(r.log, result, outputPrefix, r.formats); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to save results: %v\n", err)
+			os.Exit(1)
+		}
+		if len(result.Related) > 0 {
+			saveList(r.log, outputPrefix+"_related.txt", "Related names", result.Related)
+		}
+		r.upload(outputPrefix, started)
+	}
+	r.record(result, started)
+	return result, err
+}
+
+// assetStream returns the JSONL stream of a domain when the jsonl format is
+// chosen: to <prefix>.jsonl, or in pipeline mode to the results. It returns
+// nil otherwise.
+func (r *enumRun) assetStream(domain, outputPrefix string) *assetStream {
+	if !hasFormat(r.formats, "jsonl") || (outputPrefix == "" && !r.pipeline) {
+		return nil
+	}
+	stream, err := newAssetStream(outputPrefix, r.results, domain)
+	if err != nil {
+		fmt.Fprintf(r.log, "Error: Failed to create JSONL file: %v\n", err)
+		os.Exit(1)
+	}
+	return stream
+}
+
+// onMatch returns the callback collecting banners, nil when no store keeps them
+func (r *enumRun) onMatch() func(*shodanx.Match) {
+	if r.mongo == nil && r.es == nil {
+		return nil
+	}
+	return func(m *shodanx.Match) {
+		r.banners = append(r.banners, m)
+	}
+}
+
+// printTruncated tells once per run how many queries had more results than
+// the pages fetched, since the default profile only reads the first page
+func printTruncated(w io.Writer, result *shodanx.Result, maxPages int) {
+	if len(result.Truncated) == 0 || maxPages <= 0 {
+		return
+	}
+	missing := 0
+	for _, n := range result.Truncated {
+		missing += n
+	}
+	pages := "the first page"
+	if maxPages > 1 {
+		pages = fmt.Sprintf("the first %d pages", maxPages)
+	}
+	fmt.Fprintf(w, "[!] %d queries had more results than %s, %d results left out; fetch them with --max-pages or --profile thorough (a query credit per extra page)\n",
+		len(result.Truncated), pages, missing)
+}
+
+// knownNames returns the subdomains of domain that a previous run saved
+// under outputPrefix or, failing that, recorded in the --db database; nil
+// when there is no previous run
+func (r *enumRun) knownNames(domain, outputPrefix string) map[string]bool {
+	if outputPrefix != "" {
+		if known := readKnown(outputPrefix); known != nil {
+			return known
+		}
+	}
+	if r.store == nil {
+		return nil
+	}
+	known, err := r.store.known(domain)
+	if err != nil {
+		fmt.Fprintf(r.log, "[!] Failed to read previous results from %s: %v\n", r.store.name, err)
+	}
+	return known
+}
+
+// record saves a result to the --db and --mongo databases and the --export
+// targets, if any
+func (r *enumRun) record(result *shodanx.Result, started time.Time) {
+	banners := r.banners
+	r.banners = nil
+	if r.store != nil {
+		if err := r.store.save(result, started); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to record results in %s: %v\n", r.store.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(r.log, "[+] Results recorded in", r.store.name)
+	}
+	if r.mongo != nil {
+		if err := r.mongo.save(r.log, result, banners); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to store results in %s: %v\n", r.mongo.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(r.log, "[+] Results stored in", r.mongo.name)
+	}
+	if r.es != nil {
+		if err := r.es.export(result, banners, started); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to export results to %s: %v\n", r.es.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(r.log, "[+] Exported %d assets and %d banners to %s\n", len(result.Subdomains), len(banners), r.es.name)
+	}
+	if r.kafka != nil {
+		if err := r.kafka.publish(assetEvents(result, started)); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to publish to %s: %v\n", r.kafka.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(r.log, "[+] Published %d assets to %s\n", len(result.Subdomains), r.kafka.name)
+	}
+	if r.nats != nil {
+		if err := r.nats.publish(assetEvents(result, started)); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to publish to %s: %v\n", r.nats.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(r.log, "[+] Published %d assets to %s\n", len(result.Subdomains), r.nats.name)
+	}
+	if r.misp != nil {
+		id, added, err := r.misp.export(result)
+		if err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to export results to %s: %v\n", r.misp.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(r.log, "[+] Added %d attributes to MISP event %s in %s\n", added, id, r.misp.name)
+	}
+	if r.gvm != nil {
+		hosts := liveHosts(result)
+		if len(hosts) == 0 {
+			fmt.Fprintln(r.log, "[!] No live hosts, no OpenVAS target created")
+			return
+		}
+		id, err := r.gvm.export(result, hosts, started)
+		if err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to create OpenVAS target in %s: %v\n", r.gvm.name, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(r.log, "[+] Created OpenVAS target %s with %d live hosts in %s\n", id, len(hosts), r.gvm.name)
+	}
+}
+
+// renderTemplate renders a result through the --template, if any, to its
+// file next to the output prefix or else to the results
+func (r *enumRun) renderTemplate(result *shodanx.Result, outputPrefix string) {
+	if r.outputTemplate == nil {
+		return
+	}
+	var err error
+	if outputPrefix != "" {
+		err = r.outputTemplate.save(r.log, result, outputPrefix)
+	} else if err = r.outputTemplate.render(r.results, result); err != nil {
+		fmt.Fprintf(r.log, "Error: Failed to render template: %v\n", err)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// upload copies the output files of a run to the --upload destination, if any
+func (r *enumRun) upload(outputPrefix string, started time.Time) {
+	if r.uploader == nil {
+		return
+	}
+	dest, err := r.uploader.upload(outputPrefix, started, r.artifactSuffixes)
+	if err != nil {
+		fmt.Fprintf(r.log, "Error: Failed to upload results to %s: %v\n", r.uploader.name, err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(r.log, "[+] Results uploaded to", dest)
+}
+
+// archivedOnly counts the unresolved subdomains found by the web archives alone
+func archivedOnly(result *shodanx.Result) int {
+	n := 0
+	for _, name := range result.Unresolved {
+		archived := true
+		for _, src := range result.Sources[name] {
+			if src != shodanx.SourceWayback && src != shodanx.SourceURLScan {
+				archived = false
+			}
+		}
+		if archived {
+			n++
+		}
+	}
+	return n
+}
+
+// enumSources returns the sources --sources accepts: shodan and the
+// registered sources, including those of loaded plugins
+func enumSources() []string {
+	return append([]string{"shodan"}, shodanx.SourceNames()...)
+}
+
+// freeSources need no API key and are the only ones run with --free-only
+var freeSources = []string{"crtsh", "archives", "hackertarget", "anubis", "rapiddns"}
+
+// loadPlugins opens Go plugins, whose init functions register their sources
+func loadPlugins(w io.Writer, paths []string) {
+	for _, path := range paths {
+		if _, err := plugin.Open(path); err != nil {
+			fmt.Fprintf(w, "Error: Failed to load plugin %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// parseSources returns the set of sources in a comma-separated list
+func parseSources(list string) (map[string]bool, error) {
+	sources := map[string]bool{}
+	for _, name := range splitList(strings.ToLower(list)) {
+		valid := false
+		for _, s := range enumSources() {
+			valid = valid || s == name
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown source %q (available: %s)", name, strings.Join(enumSources(), ", "))
+		}
+		sources[name] = true
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("--sources needs at least one source")
+	}
+	return sources, nil
+}
+
+// sortedSources lists the enabled sources in the order of enumSources
+func sortedSources(sources map[string]bool) []string {
+	var names []string
+	for _, s := range enumSources() {
+		if sources[s] {
+			names = append(names, s)
+		}
+	}
+	return names
+}
+
+// saveList writes one entry per line, exiting on failure
+func saveList(w io.Writer, path, what string, lines []string) {
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		fmt.Fprintf(w, "Error: Failed to save %s %s: %v\n", strings.ToLower(what), path, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(w, "[+] %s saved to %s\n", what, path)
+}
+
+// attributeCloud records the cloud provider and region of the resolved and
+// bare IPs of result, fetching the published ranges on first use
+func (r *enumRun) attributeCloud(ctx context.Context, client *shodanx.Client, result *shodanx.Result) {
+	if r.cloudRanges == nil {
+		fmt.Fprintln(r.log, "[*] Fetching published cloud IP ranges")
+		ranges, err := shodanx.FetchCloudRanges(ctx, client.HTTPClient)
+		if err != nil {
+			fmt.Fprintf(r.log, "[!] %v\n", err)
+		}
+		if r.azureRanges != "" {
+			if err := ranges.LoadAzureRanges(r.azureRanges); err != nil {
+				fmt.Fprintf(r.log, "[!] Azure ranges not loaded: %v\n", err)
+			}
+		}
+		r.cloudRanges = ranges
+	}
+
+	addrs := append(shodanx.Addresses(result.IPs), result.Addresses...)
+	result.Cloud = r.cloudRanges.Annotate(addrs)
+	if len(result.Cloud) == 0 {
+		return
+	}
+	counts := map[string]int{}
+	for _, c := range result.Cloud {
+		counts[c.Provider]++
+	}
+	var parts []string
+	for p, n := range counts {
+		parts = append(parts, fmt.Sprintf("%s %d", p, n))
+	}
+	sort.Strings(parts)
+	fmt.Fprintf(r.log, "[*] %d of %d IPs are in cloud ranges: %s\n", len(result.Cloud), len(addrs), strings.Join(parts, ", "))
+}
+
+// reverseDNS looks up the names of the addresses of the matched services
+// and adds those under domain. Only fatal Shodan errors are returned.
+func (r *enumRun) reverseDNS(ctx context.Context, client *shodanx.Client, domain string, result *shodanx.Result) error {
+	addrs := result.MatchedAddresses()
+	if len(addrs) == 0 {
+		return nil
+	}
+	fmt.Fprintf(r.log, "[*] Reverse DNS (%s) on %d IPs\n", r.rdns, len(addrs))
+	var names map[string][]string
+	source := shodanx.SourceReverseDNS
+	if r.rdns == "local" {
+		names, source = r.resolver.Reverse(ctx, addrs), shodanx.SourcePTR
+	} else {
+		var err error
+		names, err = client.Reverse(ctx, addrs)
+		if shodanx.IsFatal(err) {
+			return err
+		}
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(r.log, "[!] Reverse DNS: %v\n", err)
+		}
+	}
+	fmt.Fprintf(r.log, "[+] %d new subdomains from reverse DNS\n", result.AddReverse(source, names, domain))
+	return nil
+}
+
+// pivotFavicons searches for other services serving the favicons of the
+// probed hosts. Hostnames under domain are added to the result with the
+// address they were seen on; the rest are kept in FaviconMatches.
+func (r *enumRun) pivotFavicons(ctx context.Context, client *shodanx.Client, domain string, result *shodanx.Result) {
+	var hashes []int32
+	seen := map[int32]bool{}
+	for _, p := range result.Probes {
+		if p.FaviconHash != 0 && !seen[p.FaviconHash] {
+			seen[p.FaviconHash] = true
+			hashes = append(hashes, p.FaviconHash)
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+
+	fmt.Fprintf(r.log, "[*] Searching %d favicon hashes\n", len(hashes))
+	matches, err := client.SearchFavicons(ctx, hashes, r.options(domain).MaxPages)
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(r.log, "[!] Favicon search: %v\n", err)
+	}
+	result.FaviconMatches = matches
+
+	n := len(result.Subdomains)
+	for _, m := range matches {
+		for _, h := range m.Hostnames {
+			name := shodanx.NormalizeHostname(h)
+			if !shodanx.InDomain(name, domain) || (r.scope != nil && !r.scope.InScope(name)) {
+				continue
+			}
+			result.AddHostnames(fmt.Sprintf("favicon:%d", m.Hash), name)
+			if len(result.IPs[name]) == 0 {
+				result.IPs[name] = []string{m.IP}
+			}
+		}
+	}
+	fmt.Fprintf(r.log, "[+] %d services share the favicons, %d new subdomains\n", len(matches), len(result.Subdomains)-n)
+}
+
+// printFaviconMatches lists the services sharing a favicon with the target
+// that are not named under domain, likely its unlisted infrastructure
+func printFaviconMatches(result *shodanx.Result, domain string) {
+	var lines []string
+	for _, m := range result.FaviconMatches {
+		inDomain := false
+		for _, h := range m.Hostnames {
+			inDomain = inDomain || shodanx.InDomain(h, domain)
+		}
+		if inDomain {
+			continue
+		}
+		line := fmt.Sprintf("%s:%d", m.IP, m.Port)
+		if len(m.Hostnames) > 0 {
+			line += " [" + strings.Join(m.Hostnames, ",") + "]"
+		}
+		if m.Org != "" {
+			line += " " + m.Org
+		}
+		lines = append(lines, fmt.Sprintf("%s (favicon:%d)", line, m.Hash))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Printf("\n[+] Found %d services outside %s serving its favicons:\n", len(lines), domain)
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+}
+
+// proberFor returns the prober of the --probe stage, or nil when it is off
+func proberFor(enabled, waf, favicon bool, workers int, timeout time.Duration) *shodanx.Prober {
+	if !enabled {
+		return nil
+	}
+	return &shodanx.Prober{Workers: workers, Timeout: timeout, WAF: waf, Favicon: favicon}
+}
+
+// formatProbe prints a probe httpx-style: URL [status] [title] [server] [waf:name] [favicon:hash] [-> location]
+func formatProbe(p *shodanx.Probe) string {
+	parts := []string{p.URL, fmt.Sprintf("[%d]", p.StatusCode)}
+	if p.Title != "" {
+		parts = append(parts, "["+p.Title+"]")
+	}
+	if p.Server != "" {
+		parts = append(parts, "["+p.Server+"]")
+	}
+	if p.WAF != "" {
+		parts = append(parts, "[waf:"+p.WAF+"]")
+	}
+	if p.FaviconHash != 0 {
+		parts = append(parts, fmt.Sprintf("[favicon:%d]", p.FaviconHash))
+	}
+	if p.Location != "" {
+		parts = append(parts, "[-> "+p.Location+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// displayName adds the Unicode spelling to punycode hostnames
+func displayName(name string) string {
+	if u := shodanx.ToUnicode(name); u != name {
+		return name + " (" + u + ")"
+	}
+	return name
+}
+
+// applyScope drops the out-of-scope hostnames of result
+func (r *enumRun) applyScope(result *shodanx.Result) {
+	if r.scope == nil {
+		return
+	}
+	if dropped := result.Filter(r.scope.InScope); len(dropped) > 0 {
+		fmt.Fprintf(r.log, "[*] Dropped %d out-of-scope hostnames\n", len(dropped))
+	}
+}
+
+// dryRun prints every query that would run and the credits it would cost
+func dryRun(w io.Writer, ctx context.Context, client *shodanx.Client, domain string, enumOpts shodanx.EnumerateOptions) {
+	fmt.Fprintln(w, "[*] Dry run, counting results without spending query credits")
+	est, err := client.Estimate(ctx, domain, enumOpts)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Fprintf(w, "\n%8s %6s %8s  %s\n", "RESULTS", "PAGES", "CREDITS", "QUERY")
+	for _, q := range est.Queries {
+		fmt.Fprintf(w, "%8d %6d %8d  %s\n", q.Total, q.Pages, q.Credits, q.Query)
+	}
+	if !enumOpts.SkipDNS && !enumOpts.SkipShodan {
+		fmt.Fprintf(w, "%8s %6s %8d  %s\n", "", "", 1, "DNS API: "+domain)
+	}
+	for _, name := range enumOpts.Sources {
+		var queries []string
+		switch name {
+		case "censys":
+			queries = shodanx.CensysHostQueries(domain)
+		case "fofa":
+			queries = shodanx.FofaQueries(domain)
+		case "zoomeye":
+			queries = shodanx.ZoomEyeQueries(domain)
+		}
+		if queries == nil {
+			queries = []string{domain}
+		}
+		for _, q := range queries {
+			fmt.Fprintf(w, "%8s %6s %8s  %s\n", "", "", "", name+": "+q)
+		}
+	}
+	fmt.Fprintf(w, "\n[+] %d queries, estimated query credits: %d\n", len(est.Queries), est.Credits)
+	if est.PerParent > 0 {
+		fmt.Fprintf(w, "[!] Not included: --recursive runs %d more queries, a query credit per result page each, for every intermediate subdomain found (up to --depth %d levels)\n",
+			est.PerParent, enumOpts.Depth)
+	}
+
+	if enumOpts.SkipShodan {
+		return
+	}
+	if info, err := client.APIInfo(ctx); err == nil {
+		fmt.Fprintf(w, "[*] Query credits left: %d\n", info.QueryCredits)
+		if est.Credits > info.QueryCredits {
+			fmt.Fprintln(w, "[!] The run would exceed the credits left on this key")
+		}
+	}
+	if client.MaxCredits > 0 && est.Credits > client.MaxCredits {
+		fmt.Fprintf(w, "[!] The run would stop at the --max-credits budget of %d\n", client.MaxCredits)
+	}
+}
+
+// scopeFilters turns the scope flags into filters appended to every query.
+// Lists are passed unquoted so Shodan treats them as alternatives.
+func scopeFilters(country, port, product, asn, org, cidr string) string {
+	q := shodanx.NewQuery()
+	if org != "" {
+		q.Filter("org", org)
+	}
+	if list := splitList(country); len(list) > 0 {
+		q.Raw("country:" + strings.ToUpper(strings.Join(list, ",")))
+	}
+	if list := splitList(port); len(list) > 0 {
+		q.Raw("port:" + strings.Join(list, ","))
+	}
+	if product != "" {
+		q.Filter("product", product)
+	}
+	if list := splitList(asn); len(list) > 0 {
+		for i, a := range list {
+			list[i] = shodanx.NormalizeASN(a)
+		}
+		q.Raw("asn:" + strings.Join(list, ","))
+	}
+	if list := splitList(cidr); len(list) > 0 {
+		q.Raw("net:" + strings.Join(list, ","))
+	}
+	return q.String()
+}
+
+// profileNames lists the built-in profiles for the --profile usage
+func profileNames() string {
+	names := make([]string, len(shodanx.Profiles))
+	for i, p := range shodanx.Profiles {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
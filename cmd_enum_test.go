@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestScopeFilters(t *testing.T) {
+	tests := []struct {
+		name                                   string
+		country, port, product, asn, org, cidr string
+		want                                   string
+	}{
+		{name: "none", want: ""},
+		{name: "country list", country: "de, fr,", want: "country:DE,FR"},
+		{name: "ports", port: "443,8443", want: "port:443,8443"},
+		{name: "product quoted", product: `Apache "httpd"`, want: `product:"Apache \"httpd\""`},
+		{name: "asn normalized", asn: "as13335, 15169", want: "asn:AS13335,AS15169"},
+		{name: "org", org: "Example Inc", want: `org:"Example Inc"`},
+		{name: "cidr", cidr: "192.0.2.0/24,198.51.100.0/24", want: "net:192.0.2.0/24,198.51.100.0/24"},
+		{name: "combined", country: "us", port: "22", product: "OpenSSH", asn: "AS64500", org: "Example",
+			want: `org:"Example" country:US port:22 product:"OpenSSH" asn:AS64500`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopeFilters(tt.country, tt.port, tt.product, tt.asn, tt.org, tt.cidr); got != tt.want {
+				t.Errorf("scopeFilters = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKnownNames(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openStore(filepath.Join(dir, "results.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	result := &shodanx.Result{Domain: "example.com"}
+	result.AddHostnames("test", "db.example.com")
+	if err := store.save(result, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	prefix := filepath.Join(dir, "results")
+	if err := os.WriteFile(prefix+".txt", []byte("txt.example.com"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		store          bool
+		domain, prefix string
+		want           map[string]bool
+	}{
+		{name: "nothing to compare against", domain: "example.com"},
+		{name: "output files win", store: true, domain: "example.com", prefix: prefix,
+			want: map[string]bool{"txt.example.com": true}},
+		{name: "database without output", store: true, domain: "example.com",
+			want: map[string]bool{"db.example.com": true}},
+		{name: "database without previous output", store: true, domain: "example.com", prefix: filepath.Join(dir, "none"),
+			want: map[string]bool{"db.example.com": true}},
+		{name: "other domain", store: true, domain: "example.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &enumRun{}
+			if tt.store {
+				run.store = store
+			}
+			if got := run.knownNames(tt.domain, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("knownNames = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runHost(args []string) {
+	fs, opts := newFlagSet("host", "<ip>...",
+		"host --apikey YOUR_API_KEY 1.2.3.4",
+		"host --apikey YOUR_API_KEY --input ips.txt")
+	banners := fs.Bool("banners", true, "Print the banner of each service")
+	history := fs.Bool("history", false, "Fetch historical banners and show when services appeared or changed")
+	input := fs.String("input", "", "File with one IP per line; several IPs switch to a one-line summary per host")
+	honeyscore := fs.Bool("honeyscore", false, "Check whether the hosts look like honeypots")
+	bulkSize := fs.Int("bulk-size", shodanx.DefaultBulkSize, "IPs per bulk lookup request (enterprise keys)")
+	opts.parse(fs, args, "")
+
+	ips := fs.Args()
+	if *input != "" {
+		lines, err := readLines(*input)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(exitError)
+		}
+		ips = append(ips, lines...)
+	}
+	if len(ips) == 0 {
+		fmt.Println("Error: IP argument is required!")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	for _, ip := range ips {
+		if net.ParseIP(ip) == nil {
+			fmt.Printf("Error: %q is not a valid IP address\n", ip)
+			os.Exit(exitError)
+		}
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	if len(ips) > 1 {
+		hosts, err := client.Hosts(ctx, shodanx.Unique(ips), *bulkSize)
+		var scores map[string]float64
+		if *honeyscore && err == nil {
+			var found []string
+			for _, h := range hosts {
+				found = append(found, h.IPStr)
+			}
+			scores = client.Honeyscores(ctx, found, 1)
+		}
+		printHostSummaries(hosts, scores)
+		if err != nil {
+			fatal(err)
+		}
+		return
+	}
+	ip := ips[0]
+
+	lookup := client.Host
+	if *history {
+		lookup = client.HostHistory
+	}
+	host, err := lookup(ctx, ip)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("[+] %s\n", host.IPStr)
+	printField("Hostnames", strings.Join(host.Hostnames, ", "))
+	printField("Organization", host.Org)
+	printField("ISP", host.ISP)
+	printField("ASN", host.ASN)
+	printField("Location", strings.Trim(host.City+", "+host.CountryName, ", "))
+	printField("OS", host.OS)
+	printField("Tags", strings.Join(host.Tags, ", "))
+	printField("Last seen", host.LastUpdate)
+	if *honeyscore {
+		score, err := client.Honeyscore(ctx, ip)
+		if err != nil {
+			fatal(err)
+		}
+		verdict := "not a honeypot"
+		if shodanx.IsHoneypot(score) {
+			verdict = "LIKELY HONEYPOT"
+		}
+		printField("Honeyscore", fmt.Sprintf("%.2f (%s)", score, verdict))
+	}
+
+	printField("Open ports", joinPorts(host.Ports, ", "))
+
+	vulns := append([]string(nil), host.Vulns...)
+	sort.Strings(vulns)
+	printField("Vulns", strings.Join(vulns, ", "))
+
+	if *history {
+		printHistory(host)
+		return
+	}
+
+	if !*banners {
+		return
+	}
+	sort.Slice(host.Data, func(i, j int) bool { return host.Data[i].Port < host.Data[j].Port })
+	for _, b := range host.Data {
+		fmt.Printf("\n[*] %d/%s %s %s (%s)\n", b.Port, b.Transport, b.Product, b.Version, b.Timestamp)
+		for _, line := range strings.Split(strings.TrimSpace(b.Data), "\n") {
+			fmt.Printf("    %s\n", strings.TrimRight(line, "\r"))
+		}
+	}
+}
+
+// printHostSummaries prints one line per host: IP, ports, hostnames and vuln
+// count, flagging likely honeypots
+func printHostSummaries(hosts []shodanx.Host, scores map[string]float64) {
+	sort.Slice(hosts, func(i, j int) bool { return ipLess(hosts[i].IPStr, hosts[j].IPStr) })
+	for _, h := range hosts {
+		flag := ""
+		if score, ok := scores[h.IPStr]; ok && shodanx.IsHoneypot(score) {
+			flag = fmt.Sprintf("\tHONEYPOT=%.2f", score)
+		}
+		fmt.Printf("%s\tports=%s\thostnames=%s\tvulns=%d\t%s%s\n",
+			h.IPStr, joinPorts(h.Ports, ","), strings.Join(h.Hostnames, ","), len(h.Vulns), h.Org, flag)
+	}
+	fmt.Printf("[+] %d hosts found\n", len(hosts))
+}
+
+// printHistory prints the first/last sighting of each service and every
+// product or version change in between
+func printHistory(host *shodanx.Host) {
+	for _, sh := range host.History() {
+		fmt.Printf("\n[*] %d/%s first seen %s, last seen %s\n", sh.Port, sh.Transport, sh.FirstSeen, sh.LastSeen)
+		for _, b := range sh.Changes {
+			product := strings.TrimSpace(b.Product + " " + b.Version)
+			if product == "" {
+				product = "(unidentified)"
+			}
+			fmt.Printf("    %s  %s\n", b.Timestamp, product)
+		}
+	}
+}
+
+// printField prints a labelled value, skipping empty ones
+func printField(label, value string) {
+	if value != "" {
+		fmt.Printf("  %-14s %s\n", label+":", value)
+	}
+}
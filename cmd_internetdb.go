@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runInternetDB(args []string) {
+	fs, opts := newFlagSet("internetdb", "<ip|hostname>...",
+		"internetdb 1.2.3.4",
+		"internetdb api.example.com www.example.com")
+	workers := fs.Int("workers", 10, "Concurrent DNS/InternetDB lookups")
+	opts.keyOptional = true // InternetDB is free and keyless
+	opts.parse(fs, args, "IP or hostname")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	// IPs are looked up directly, hostnames are resolved first
+	var names []string
+	ips := map[string][]string{}
+	for _, arg := range fs.Args() {
+		if net.ParseIP(arg) != nil {
+			ips[arg] = []string{arg}
+		} else {
+			names = append(names, arg)
+		}
+	}
+	for name, list := range shodanx.ResolveHosts(ctx, names, *workers) {
+		ips[name] = list
+	}
+	hosts := client.LookupInternetDB(ctx, shodanx.Addresses(ips), *workers)
+
+	for _, arg := range fs.Args() {
+		fmt.Printf("%s %s\n", arg, formatAddresses(ips[arg], hosts, nil, nil))
+	}
+}
+
+// formatAddresses summarises the InternetDB records, honeyscores and cloud
+// ranges of a name's addresses as "[1.2.3.4 cloud=AWS/us-east-1 ports=80,443 ...]"
+func formatAddresses(ips []string, hosts map[string]*shodanx.InternetDBHost, scores map[string]float64, cloud map[string]*shodanx.CloudRange) string {
+	if len(ips) == 0 {
+		return "[unresolved]"
+	}
+
+	var parts []string
+	for _, ip := range ips {
+		fields := []string{ip}
+		if score, ok := scores[ip]; ok && shodanx.IsHoneypot(score) {
+			fields = append(fields, fmt.Sprintf("HONEYPOT=%.2f", score))
+		}
+		if c := cloud[ip]; c != nil {
+			fields = append(fields, "cloud="+formatCloud(c))
+		}
+		h := hosts[ip]
+		if h == nil {
+			parts = append(parts, "["+strings.Join(fields, " ")+"]")
+			continue
+		}
+
+		if len(h.Ports) > 0 {
+			fields = append(fields, "ports="+joinPorts(h.Ports, ","))
+		}
+		if len(h.Vulns) > 0 {
+			fields = append(fields, "vulns="+strings.Join(h.Vulns, ","))
+		}
+		if len(h.Tags) > 0 {
+			fields = append(fields, "tags="+strings.Join(h.Tags, ","))
+		}
+		if len(h.CPEs) > 0 {
+			fields = append(fields, "cpes="+strings.Join(h.CPEs, ","))
+		}
+		parts = append(parts, "["+strings.Join(fields, " ")+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatCloud prints a cloud range as provider/region
+func formatCloud(c *shodanx.CloudRange) string {
+	if c.Region == "" {
+		return c.Provider
+	}
+	return c.Provider + "/" + c.Region
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// keyValueFlag collects repeated key=value flags
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	var parts []string
+	for k, v := range f {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (f keyValueFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	f[k] = v
+	return nil
+}
+
+func notifierUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s notifier <list|providers|create|delete> [OPTIONS]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n  list        List notifiers\n")
+	fmt.Fprintf(os.Stderr, "  providers   List notification providers and their required arguments\n")
+	fmt.Fprintf(os.Stderr, "  create      Create a notifier\n")
+	fmt.Fprintf(os.Stderr, "  delete      Delete notifiers by ID\n")
+}
+
+func runNotifier(args []string) {
+	if len(args) < 1 {
+		notifierUsage()
+		os.Exit(exitError)
+	}
+
+	switch args[0] {
+	case "list":
+		runNotifierList(args[1:])
+	case "providers":
+		runNotifierProviders(args[1:])
+	case "create":
+		runNotifierCreate(args[1:])
+	case "delete":
+		runNotifierDelete(args[1:])
+	default:
+		notifierUsage()
+		os.Exit(exitError)
+	}
+}
+
+func runNotifierList(args []string) {
+	fs, opts := newFlagSet("notifier list", "", "notifier list --apikey YOUR_API_KEY")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	notifiers, err := client.Notifiers(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	for _, n := range notifiers {
+		fmt.Printf("%s\t%s\t%s\n", n.ID, n.Provider, n.Description)
+	}
+}
+
+func runNotifierProviders(args []string) {
+	fs, opts := newFlagSet("notifier providers", "", "notifier providers --apikey YOUR_API_KEY")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	providers, err := client.NotifierProviders(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-15s required: %s\n", name, strings.Join(providers[name].Required, ", "))
+	}
+}
+
+func runNotifierCreate(args []string) {
+	fs, opts := newFlagSet("notifier create", "",
+		"notifier create --apikey YOUR_API_KEY --provider email --description soc --arg to=soc@example.com",
+		"notifier create --apikey YOUR_API_KEY --provider slack --description acme --arg webhook_url=https://hooks.slack.com/...")
+	provider := fs.String("provider", "", "Notification provider (see 'notifier providers')")
+	description := fs.String("description", "", "Notifier description")
+	providerArgs := keyValueFlag{}
+	fs.Var(providerArgs, "arg", "Provider argument as key=value (repeatable)")
+	opts.parse(fs, args, "")
+
+	if *provider == "" {
+		fmt.Println("Error: --provider is required!")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	id, err := client.CreateNotifier(ctx, *provider, *description, providerArgs)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("[+] Notifier %s created\n", id)
+}
+
+func runNotifierDelete(args []string) {
+	fs, opts := newFlagSet("notifier delete", "<id>...", "notifier delete --apikey YOUR_API_KEY abc123")
+	opts.parse(fs, args, "Notifier ID")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	for _, id := range fs.Args() {
+		if err := client.DeleteNotifier(ctx, id); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("[+] Notifier %s deleted\n", id)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// pivot enumerates an organisation, network or range instead of a domain and
+// prints the registered domains, netblocks and hostnames found on it
+func (r *enumRun) pivot(ctx context.Context, client *shodanx.Client, target string, queries []string, opts shodanx.PivotOptions, outputPrefix string) {
+	fmt.Fprintf(r.log, "[*] Starting pivot on: %s\n", target)
+	before := client.CreditsUsed()
+	started := time.Now()
+
+	result, err := client.Pivot(ctx, target, queries, opts)
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		r.stop() // a second Ctrl-C while saving terminates immediately
+		fmt.Fprintln(r.log, "\n[!] Pivot interrupted, keeping partial results")
+	} else if err != nil {
+		fmt.Fprintf(r.log, "\n[!] Pivot aborted: %v\n", err)
+	}
+	r.applyScope(result)
+	result.ScoreRisk()
+	fmt.Fprintf(r.log, "[*] Query credits used: %d\n", client.CreditsUsed())
+
+	// Pivots only know their assets at the end, so the stream is written at once
+	stream := r.assetStream(target, outputPrefix)
+	if r.pipeline {
+		// The template output takes the place of the names like in enumerate
+		pipeTemplate := outputPrefix == "" && r.outputTemplate != nil
+		if (stream == nil || stream.file != nil) && !pipeTemplate {
+			for _, s := range result.Subdomains {
+				fmt.Fprintln(r.results, s)
+			}
+		}
+	} else {
+		printPivot(result)
+		if r.vulnReport {
+			printVulnReport(result)
+		}
+	}
+
+	known := r.knownNames(result.Domain, outputPrefix)
+	if outputPrefix != "" {
+		keepFirstSeen(result, outputPrefix)
+	}
+	printStats(r.log, result, known, client.CreditsUsed()-before)
+
+	if stream != nil {
+		if err := stream.finish(r.log, result); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to write JSONL results: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	r.renderTemplate(result, outputPrefix)
+	if outputPrefix != "" {
+		if err := saveResults(r.log, result, outputPrefix, r.formats); err != nil {
+			fmt.Fprintf(r.log, "Error: Failed to save results: %v\n", err)
+			os.Exit(1)
+		}
+		r.upload(outputPrefix, started)
+	}
+	r.record(result, started)
+	if err != nil && !interrupted {
+		fatalTo(r.log, err)
+	}
+}
+
+// printPivot prints the domains, netblocks and hostnames of a pivot result
+func printPivot(result *shodanx.Result) {
+	groups := shodanx.GroupByDomain(result.Subdomains)
+	fmt.Printf("\n[+] Found %d registered domains:\n", len(result.Domains))
+	for _, d := range result.Domains {
+		fmt.Printf("%-40s %d hostnames\n", d, len(groups[d]))
+	}
+
+	blocks := make([]string, 0, len(result.Netblocks))
+	for b := range result.Netblocks {
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return ipLess(blockIP(blocks[i]), blockIP(blocks[j])) })
+	// Group the hostnames by netblock and address
+	names := map[string][]string{}
+	for name, ips := range result.IPs {
+		for _, ip := range ips {
+			names[ip] = append(names[ip], name)
+		}
+	}
+	fmt.Printf("\n[+] Found %d netblocks:\n", len(blocks))
+	for _, b := range blocks {
+		ips := append([]string(nil), result.Netblocks[b]...)
+		sort.Slice(ips, func(i, j int) bool { return ipLess(ips[i], ips[j]) })
+		fmt.Printf("%s (%d hosts)\n", b, len(ips))
+		for _, ip := range ips {
+			sort.Strings(names[ip])
+			fmt.Printf("  %-40s %s\n", ip, strings.Join(names[ip], ", "))
+		}
+	}
+
+	fmt.Printf("\n[+] Found %d unique hostnames:\n", len(result.Subdomains))
+	for _, s := range result.Subdomains {
+		fmt.Printf("%s %s\n", displayName(s), formatAddresses(result.IPs[s], nil, nil, result.Cloud))
+	}
+
+	if len(result.Addresses) > 0 {
+		fmt.Printf("\n[+] Found %d IP addresses in place of hostnames:\n", len(result.Addresses))
+		for _, ip := range result.Addresses {
+			fmt.Println(ip)
+		}
+	}
+
+	printRisks(result)
+	printExposures(result)
+	printServices(result)
+	printCertFindings(result)
+	printLeaks(result)
+
+	if len(result.Summary) > 0 {
+		fmt.Println("\n[+] Exposure summary across all matched services:")
+		printFacets(os.Stdout, result.Summary, shodanx.DefaultFacets, shodanx.SummaryTop)
+	}
+}
+
+// blockIP returns the network address of a CIDR string
+func blockIP(cidr string) string {
+	ip, _, _ := strings.Cut(cidr, "/")
+	return ip
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+func runPorts(args []string) {
+	fs, opts := newFlagSet("ports", "", "ports --apikey YOUR_API_KEY")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	ports, err := client.Ports(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	sort.Ints(ports)
+	for _, p := range ports {
+		fmt.Println(p)
+	}
+}
+
+func runProtocols(args []string) {
+	fs, opts := newFlagSet("protocols", "", "protocols --apikey YOUR_API_KEY")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	protocols, err := client.Protocols(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-25s %s\n", name, protocols[name])
+	}
+}
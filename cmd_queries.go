@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func queriesUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s queries <search|list|tags> [OPTIONS]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n  search   Search community queries for a term\n")
+	fmt.Fprintf(os.Stderr, "  list     List the most popular or most recent queries\n")
+	fmt.Fprintf(os.Stderr, "  tags     List the most popular query tags\n")
+}
+
+func runQueries(args []string) {
+	if len(args) < 1 {
+		queriesUsage()
+		os.Exit(exitError)
+	}
+
+	switch args[0] {
+	case "search", "list":
+		runQueriesSearch(args[0], args[1:])
+	case "tags":
+		runQueriesTags(args[1:])
+	default:
+		queriesUsage()
+		os.Exit(exitError)
+	}
+}
+
+func runQueriesSearch(mode string, args []string) {
+	argsUsage := ""
+	if mode == "search" {
+		argsUsage = "<term>"
+	}
+	fs, opts := newFlagSet("queries "+mode, argsUsage,
+		"queries search --apikey YOUR_API_KEY jenkins",
+		"queries search --apikey YOUR_API_KEY --save queries.txt login")
+	page := fs.Int("page", 1, "Result page")
+	sortBy := fs.String("sort", "votes", "Sort order for list: votes or timestamp")
+	save := fs.String("save", "", "Append the query strings to this file, one per line, for use in the enumeration query list")
+	raw := fs.Bool("raw", false, "Print only the query strings")
+	if mode == "search" {
+		opts.parse(fs, args, "Term")
+	} else {
+		opts.parse(fs, args, "")
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	var res *shodanx.SavedQueries
+	var err error
+	if mode == "search" {
+		res, err = client.SearchQueries(ctx, strings.Join(fs.Args(), " "), *page)
+	} else {
+		res, err = client.ListQueries(ctx, *page, *sortBy)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	for _, q := range res.Matches {
+		if *raw {
+			fmt.Println(q.Query)
+			continue
+		}
+		fmt.Printf("[%d votes] %s\n    %s\n", q.Votes, q.Title, q.Query)
+		if len(q.Tags) > 0 {
+			fmt.Printf("    tags: %s\n", strings.Join(q.Tags, ", "))
+		}
+	}
+	if !*raw {
+		fmt.Printf("\n[+] Showing %d of %d queries\n", len(res.Matches), res.Total)
+	}
+
+	if *save != "" {
+		f, err := os.OpenFile(*save, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Printf("Error: Failed to open %s: %v\n", *save, err)
+			os.Exit(exitError)
+		}
+		defer f.Close()
+		for _, q := range res.Matches {
+			fmt.Fprintf(f, "# %s\n%s\n", q.Title, q.Query)
+		}
+		fmt.Printf("[+] Saved %d queries to %s\n", len(res.Matches), *save)
+	}
+}
+
+func runQueriesTags(args []string) {
+	fs, opts := newFlagSet("queries tags", "", "queries tags --apikey YOUR_API_KEY --size 20")
+	size := fs.Int("size", 10, "Number of tags")
+	opts.parse(fs, args, "")
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	tags, err := client.QueryTags(ctx, *size)
+	if err != nil {
+		fatal(err)
+	}
+	for _, t := range tags {
+		fmt.Printf("  %-30s %d\n", t.Value, t.Count)
+	}
+}
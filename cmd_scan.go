@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runScan(args []string) {
+	fs, opts := newFlagSet("scan", "<ip|cidr>...",
+		"scan --apikey YOUR_API_KEY 203.0.113.10 198.51.100.0/28",
+		"scan --apikey YOUR_API_KEY --input ips.txt --merge results")
+	input := fs.String("input", "", "File with one IP or CIDR per line")
+	wait := fs.Bool("wait", true, "Wait for the scan to finish and fetch its results")
+	interval := fs.Duration("interval", 30*time.Second, "Status polling interval")
+	merge := fs.String("merge", "", "Merge discovered hostnames into an existing enum output (prefix without extension)")
+	maxPages := fs.Int("max-pages", 1, "Maximum result pages to fetch, each page after the first costs a query credit (0 = all)")
+
+	// Targets may come only from --input, so don't require a positional argument
+	opts.parse(fs, args, "")
+	targets := readTargets(fs, fs.Args(), *input)
+
+	// Check the results to merge into before spending scan credits
+	var result *shodanx.Result
+	if *merge != "" {
+		var err error
+		if result, err = loadResults(*merge); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(exitError)
+		}
+		if result.Domain == "" {
+			fmt.Printf("Error: --merge needs the results of an enum run, but %s.json is missing or has no domain\n", *merge)
+			os.Exit(exitError)
+		}
+		result.RestrictTo(result.Domain)
+	}
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	sub, err := client.SubmitScan(ctx, targets)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("[+] Scan %s submitted for %d IPs, %d scan credits left\n", sub.ID, sub.Count, sub.CreditsLeft)
+	if !*wait {
+		return
+	}
+
+	if _, err := client.WaitScan(ctx, sub.ID, *interval); err != nil {
+		fatal(err)
+	}
+
+	res, err := client.ScanResults(ctx, sub.ID, *maxPages)
+	if err != nil {
+		if res == nil {
+			fatal(err)
+		}
+		fmt.Println("Error:", err)
+	}
+
+	fmt.Printf("[+] Scan found %d services:\n", len(res.Matches))
+	for _, m := range res.Matches {
+		fmt.Printf("%s:%d/%s\t%s %s\n", m.IPStr, m.Port, m.Transport, m.Product, m.Version)
+	}
+
+	if result == nil {
+		return
+	}
+	before, related := len(result.Subdomains), len(result.Related)
+	result.AddHostnames("scan:"+sub.ID, res.Hostnames()...)
+	fmt.Printf("[+] Merged %d new hostnames into %s\n", len(result.Subdomains)-before, *merge)
+	if n := len(result.Related) - related; n > 0 {
+		fmt.Printf("[*] %d hostnames outside %s added to the related names\n", n, result.Domain)
+	}
+	if err := saveResults(os.Stdout, result, *merge, opts.cfg.Formats); err != nil {
+		fmt.Printf("Error: Failed to save results: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// readTargets collects IPs/CIDRs from the arguments and an optional file,
+// exiting if none are given or one is malformed
+func readTargets(fs *flag.FlagSet, args []string, input string) []string {
+	targets := args
+	if input != "" {
+		lines, err := readLines(input)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(exitError)
+		}
+		targets = append(targets, lines...)
+	}
+	if len(targets) == 0 {
+		fmt.Println("Error: at least one IP or CIDR is required!")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	for _, t := range targets {
+		if net.ParseIP(t) == nil {
+			if _, _, err := net.ParseCIDR(t); err != nil {
+				fmt.Printf("Error: %q is not an IP address or CIDR\n", t)
+				os.Exit(exitError)
+			}
+		}
+	}
+	return targets
+}
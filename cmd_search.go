@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func runSearch(args []string) {
+	fs, opts := newFlagSet("search", "<query>",
+		`search --apikey YOUR_API_KEY 'ssl.cert.subject.cn:"example.com"'`)
+	hostsOnly := fs.Bool("hostnames", false, "Print only the extracted hostnames")
+	maxPages := fs.Int("max-pages", 1, "Maximum result pages to fetch, each page after the first costs a query credit (0 = all)")
+	opts.parse(fs, args, "Query")
+
+	query := strings.Join(fs.Args(), " ")
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	res, err := client.SearchAll(ctx, query, *maxPages)
+	if err != nil {
+		if res == nil {
+			fatal(err)
+		}
+		fmt.Println("Error:", err)
+	}
+
+	if *hostsOnly {
+		for _, h := range res.Hostnames() {
+			fmt.Println(h)
+		}
+		return
+	}
+
+	fmt.Printf("[+] %d total results, showing %d:\n", res.Total, len(res.Matches))
+	for _, m := range res.Matches {
+		fmt.Printf("%s:%d/%s\t%s\t%s\n", m.IPStr, m.Port, m.Transport, m.Org, strings.Join(m.Hostnames, ","))
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func sourcesUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s sources <list|check> [OPTIONS] [source]...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n  list        List the sources --sources accepts\n")
+	fmt.Fprintf(os.Stderr, "  check       Check the credentials, connectivity and quota of sources\n")
+}
+
+func runSources(args []string) {
+	if len(args) < 1 {
+		sourcesUsage()
+		os.Exit(exitError)
+	}
+
+	switch args[0] {
+	case "list":
+		for _, name := range enumSources() {
+			fmt.Println(name)
+		}
+	case "check":
+		runSourcesCheck(args[1:])
+	default:
+		sourcesUsage()
+		os.Exit(exitError)
+	}
+}
+
+// sourceCheck is the outcome of checking one source
+type sourceCheck struct {
+	quota string
+	err   error
+}
+
+func runSourcesCheck(args []string) {
+	fs, opts := newFlagSet("sources check", "[source]...",
+		"sources check",
+		"sources check censys fofa")
+	plugins := fs.String("plugins", "", "Comma-separated Go plugins (.so) whose sources are checked too")
+	opts.keyOptional = true // only checked like the other sources
+	opts.parse(fs, args, "")
+	loadPlugins(os.Stdout, splitList(*plugins))
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = enumSources()
+	}
+	known := map[string]bool{}
+	for _, name := range enumSources() {
+		known[name] = true
+	}
+	for _, name := range names {
+		if !known[name] {
+			fmt.Printf("Error: unknown source %q (see 'sources list')\n", name)
+			os.Exit(exitError)
+		}
+	}
+
+	client := opts.client()
+	client.Logger = nil // retries would interleave with the table
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	// Check in parallel, the sources have separate limits
+	results := make([]sourceCheck, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			if name != "shodan" {
+				results[i].quota, results[i].err = client.CheckSource(ctx, name)
+				return
+			}
+			if opts.apiKey == "" {
+				results[i].err = shodanx.ErrNotConfigured
+				return
+			}
+			info, err := client.APIInfo(ctx)
+			if err == nil {
+				results[i].quota = fmt.Sprintf("%d query credits left (%s plan)", info.QueryCredits, info.Plan)
+			}
+			results[i].err = err
+		}(i, name)
+	}
+	wg.Wait()
+
+	failed := false
+	fmt.Printf("%-16s %-16s %s\n", "SOURCE", "STATUS", "DETAILS")
+	for i, name := range names {
+		status, details := "ok", results[i].quota
+		switch err := results[i].err; {
+		case errors.Is(err, shodanx.ErrNotConfigured):
+			status = "not configured"
+		case errors.Is(err, shodanx.ErrNoCheck):
+			status = "unchecked"
+		case err != nil:
+			status, details = "FAILED", err.Error()
+			failed = true
+		}
+		fmt.Printf("%-16s %-16s %s\n", name, status, details)
+	}
+	if failed {
+		os.Exit(exitError)
+	}
+}
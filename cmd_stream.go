@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func runStream(args []string) {
+	fs, opts := newFlagSet("stream", "",
+		"stream --apikey YOUR_API_KEY --alerts",
+		"stream --apikey YOUR_API_KEY --ports 3389,9200 --match example.com --output live.jsonl")
+	alerts := fs.Bool("alerts", false, "Stream banners for all network alerts of the account")
+	alertID := fs.String("alert", "", "Stream banners for a single network alert ID")
+	ports := fs.String("ports", "", "Filtered firehose: comma-separated ports")
+	asn := fs.String("asn", "", "Filtered firehose: comma-separated ASNs (e.g. AS15169)")
+	countries := fs.String("countries", "", "Filtered firehose: comma-separated country codes")
+	match := fs.String("match", "", "Only emit banners whose hostnames or domains end with this domain")
+	output := fs.String("output", "", "Append JSONL to this file instead of stdout")
+	opts.parse(fs, args, "")
+
+	var path string
+	switch {
+	case *alertID != "":
+		path = shodanx.StreamAlert(*alertID)
+	case *alerts:
+		path = shodanx.StreamAlerts
+	case *ports != "":
+		path = shodanx.StreamFilter("ports", *ports)
+	case *asn != "":
+		path = shodanx.StreamFilter("asn", *asn)
+	case *countries != "":
+		path = shodanx.StreamFilter("countries", *countries)
+	default:
+		// The unfiltered firehose requires an enterprise subscription
+		path = shodanx.StreamFirehose
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Printf("Error: Failed to open %s: %v\n", *output, err)
+			os.Exit(exitError)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	client := opts.client()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "[*] Streaming %s (Ctrl-C to stop)\n", path)
+	err := client.Stream(ctx, path, func(b *shodanx.StreamBanner) error {
+		if *match != "" && !bannerMatches(&b.Match, *match) {
+			return nil
+		}
+		w.Write(b.Raw)
+		w.WriteByte('\n')
+		// Flush per banner so consumers see results in real time
+		return w.Flush()
+	})
+	if err != nil && ctx.Err() == nil {
+		w.Flush()
+		fatal(err)
+	}
+}
+
+// bannerMatches reports whether any hostname or domain of m is domain or one of its subdomains
+func bannerMatches(m *shodanx.Match, domain string) bool {
+	for _, name := range append(append([]string(nil), m.Hostnames...), m.Domains...) {
+		if shodanx.InDomain(name, domain) {
+			return true
+		}
+	}
+	return false
+}
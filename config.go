@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the optional YAML configuration file. Command line flags always
+// take precedence over values set here.
+type Config struct {
+	APIKey string `yaml:"api_key"`
+
+	// Formats lists the output formats written by --output, see outputFormats
+	Formats []string `yaml:"formats"`
+
+	// DB is the SQLite file or PostgreSQL DSN results are recorded in, see --db
+	DB string `yaml:"db"`
+
+	// Mongo is the MongoDB URI assets and banners are stored in, see --mongo
+	Mongo string `yaml:"mongo"`
+
+	// ESURL is the Elasticsearch/OpenSearch URL of --export elasticsearch
+	ESURL string `yaml:"es_url"`
+
+	// GVMURL is the gvmd URL of --export openvas, with credentials
+	GVMURL string `yaml:"gvm_url"`
+
+	// Kafka holds the brokers and topic of --export kafka
+	Kafka KafkaConfig `yaml:"kafka"`
+
+	// NATS holds the server and subject prefix of --export nats
+	NATS NATSConfig `yaml:"nats"`
+
+	// MISP holds the instance URL and API key of --export misp
+	MISP MISPConfig `yaml:"misp"`
+
+	// Template is the Go text/template file results are rendered through,
+	// see --template
+	Template string `yaml:"template"`
+
+	// Upload is the S3, GCS or Azure Blob URL output files are uploaded to,
+	// see --upload
+	Upload string `yaml:"upload"`
+
+	// Queries replaces the built-in query list. Each entry is a Go template
+	// receiving the target as {{.Domain}}.
+	Queries []string `yaml:"queries"`
+
+	// RateLimit is the maximum number of API requests per second
+	RateLimit float64 `yaml:"rate_limit"`
+
+	// Retries is the number of retries for network errors and 429/5xx responses
+	Retries *int `yaml:"retries"`
+
+	// Proxy is an HTTP(S) or SOCKS5 proxy URL used for all API requests
+	Proxy string `yaml:"proxy"`
+
+	// Censys holds the Censys Search API credentials used by --censys
+	Censys CensysConfig `yaml:"censys"`
+
+	// SecurityTrails holds the SecurityTrails API key used by --securitytrails
+	SecurityTrails APIKeyConfig `yaml:"securitytrails"`
+
+	// URLScan holds the optional urlscan.io API key used by --archives
+	URLScan APIKeyConfig `yaml:"urlscan"`
+
+	// BinaryEdge holds the BinaryEdge API key used by --sources binaryedge
+	BinaryEdge APIKeyConfig `yaml:"binaryedge"`
+
+	// Fofa holds the FOFA credentials used by --sources fofa
+	Fofa FofaConfig `yaml:"fofa"`
+
+	// ZoomEye holds the ZoomEye API key used by --sources zoomeye
+	ZoomEye APIKeyConfig `yaml:"zoomeye"`
+
+	// Providers holds the credentials, endpoint and request settings of
+	// each other source, keyed by source name as in subfinder's provider
+	// config. Credentials here win over the sections above.
+	Providers map[string]ProviderConfig `yaml:"providers"`
+}
+
+// ProviderConfig is an entry of the providers section of the config file
+type ProviderConfig struct {
+	APIKey string `yaml:"api_key"`
+
+	// APIID and Secret are Censys' credentials, Email is FOFA's
+	APIID  string `yaml:"api_id"`
+	Secret string `yaml:"secret"`
+	Email  string `yaml:"email"`
+
+	// URL replaces the default endpoint, e.g. for a mirror or proxy
+	URL string `yaml:"url"`
+
+	// RateLimit is the maximum number of requests per second; 0 keeps the
+	// source's default
+	RateLimit float64 `yaml:"rate_limit"`
+
+	// Timeout bounds each request, e.g. 30s; 0 keeps the default
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retries replaces the --retries count for the source
+	Retries *int `yaml:"retries"`
+
+	// Concurrency is the number of requests run in parallel by sources
+	// making several (search backend queries, BinaryEdge host lookups)
+	Concurrency int `yaml:"concurrency"`
+}
+
+// provider returns the providers entry of a source, with the credentials of
+// its own section filling the ones left empty
+func (c *Config) provider(name string) ProviderConfig {
+	p := c.Providers[name]
+	var legacy ProviderConfig
+	switch name {
+	case "shodan":
+		legacy.APIKey = c.APIKey
+	case "censys":
+		legacy = ProviderConfig{APIID: c.Censys.APIID, Secret: c.Censys.Secret}
+	case "securitytrails":
+		legacy.APIKey = c.SecurityTrails.APIKey
+	case "urlscan":
+		legacy.APIKey = c.URLScan.APIKey
+	case "binaryedge":
+		legacy.APIKey = c.BinaryEdge.APIKey
+	case "fofa":
+		legacy = ProviderConfig{APIKey: c.Fofa.APIKey, Email: c.Fofa.Email}
+	case "zoomeye":
+		legacy.APIKey = c.ZoomEye.APIKey
+	}
+	if p.APIKey == "" {
+		p.APIKey, p.Email = legacy.APIKey, legacy.Email
+	}
+	if p.APIID == "" {
+		p.APIID, p.Secret = legacy.APIID, legacy.Secret
+	}
+	return p
+}
+
+// CensysConfig is the censys section of the config file
+type CensysConfig struct {
+	APIID  string `yaml:"api_id"`
+	Secret string `yaml:"secret"`
+}
+
+// FofaConfig is the fofa section of the config file; older accounts also
+// need the email
+type FofaConfig struct {
+	Email  string `yaml:"email"`
+	APIKey string `yaml:"api_key"`
+}
+
+// APIKeyConfig is the config section of a source that only needs a key
+type APIKeyConfig struct {
+	APIKey string `yaml:"api_key"`
+}
+
+// KafkaConfig is the kafka section of the config file
+type KafkaConfig struct {
+	Brokers string `yaml:"brokers"` // comma-separated host:port
+	Topic   string `yaml:"topic"`
+}
+
+// NATSConfig is the nats section of the config file
+type NATSConfig struct {
+	URL     string `yaml:"url"`
+	Subject string `yaml:"subject"`
+}
+
+// MISPConfig is the misp section of the config file
+type MISPConfig struct {
+	URL    string `yaml:"url"`
+	APIKey string `yaml:"api_key"`
+}
+
+// defaultConfigPath returns ~/.config/shodanx/config.yaml (or the platform
+// equivalent), or config.toml next to it if only that exists
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, "shodanx", "config.yaml")
+	if toml := filepath.Join(dir, "shodanx", "config.toml"); !fileExists(path) && fileExists(toml) {
+		return toml
+	}
+	return path
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// loadConfig reads the config file at path, TOML if it ends in .toml and
+// YAML otherwise. An empty path loads the default location, which is allowed
+// to be missing.
+func loadConfig(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+		if path == "" {
+			return &Config{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		data, err = tomlToYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// tomlToYAML converts a TOML config to YAML, so both formats share the keys
+// of the yaml tags
+func tomlToYAML(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
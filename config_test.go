@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFormats(t *testing.T) {
+	retries := 5
+	want := &Config{
+		APIKey:    "shodankey",
+		Formats:   []string{"txt", "json"},
+		RateLimit: 0.5,
+		Retries:   &retries,
+		Censys:    CensysConfig{APIID: "id", Secret: "secret"},
+		Providers: map[string]ProviderConfig{
+			"crtsh": {Timeout: 90 * time.Second, Concurrency: 2},
+		},
+	}
+	tests := []struct {
+		file, data string
+	}{
+		{"config.yaml", `api_key: shodankey
+formats: [txt, json]
+rate_limit: 0.5
+retries: 5
+censys:
+  api_id: id
+  secret: secret
+providers:
+  crtsh:
+    timeout: 90s
+    concurrency: 2
+`},
+		{"config.toml", `api_key = "shodankey"
+formats = ["txt", "json"]
+rate_limit = 0.5
+retries = 5
+
+[censys]
+api_id = "id"
+secret = "secret"
+
+[providers.crtsh]
+timeout = "90s"
+concurrency = 2
+`},
+		{"CONFIG.TOML", `api_key = "shodankey"
+formats = ["txt", "json"]
+rate_limit = 0.5
+retries = 5
+censys = { api_id = "id", secret = "secret" }
+providers = { crtsh = { timeout = "90s", concurrency = 2 } }
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := loadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("loadConfig = %+v, want %+v", cfg, want)
+			}
+		})
+	}
+}
+
+func TestTOMLToYAMLErrors(t *testing.T) {
+	for _, data := range []string{`api_key = `, `[providers`, `formats = ["txt", 1`} {
+		if _, err := tomlToYAML([]byte(data)); err == nil {
+			t.Errorf("tomlToYAML(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestConfigProvider(t *testing.T) {
+	cfg := &Config{
+		APIKey:         "legacy-shodan",
+		Censys:         CensysConfig{APIID: "legacy-id", Secret: "legacy-secret"},
+		SecurityTrails: APIKeyConfig{APIKey: "legacy-st"},
+		Fofa:           FofaConfig{APIKey: "legacy-fofa", Email: "legacy@example.com"},
+		ZoomEye:        APIKeyConfig{APIKey: "legacy-zoomeye"},
+		Providers: map[string]ProviderConfig{
+			"securitytrails": {APIKey: "st", URL: "https://st.example.com"},
+			"censys":         {RateLimit: 0.2},
+			"fofa":           {APIKey: "fofa"},
+			"crtsh":          {Timeout: time.Minute},
+		},
+	}
+	tests := []struct {
+		name string
+		want ProviderConfig
+	}{
+		{"securitytrails", ProviderConfig{APIKey: "st", URL: "https://st.example.com"}},
+		{"censys", ProviderConfig{APIID: "legacy-id", Secret: "legacy-secret", RateLimit: 0.2}},
+		// The provider's key wins, and the legacy email belongs to the legacy key
+		{"fofa", ProviderConfig{APIKey: "fofa"}},
+		{"zoomeye", ProviderConfig{APIKey: "legacy-zoomeye"}},
+		{"crtsh", ProviderConfig{Timeout: time.Minute}},
+		{"binaryedge", ProviderConfig{}},
+		{"shodan", ProviderConfig{APIKey: "legacy-shodan"}},
+	}
+	for _, tt := range tests {
+		if got := cfg.provider(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("provider(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLookupAPIKey(t *testing.T) {
+	cfg := &Config{APIKey: "config-key"}
+	tests := []struct {
+		flag, env string
+		key, from string
+	}{
+		{"flag-key", "env-key", "flag-key", "--apikey"},
+		{"", "env-key", "env-key", "$" + apiKeyEnv},
+		{"", "", "config-key", "the config file"},
+	}
+	for _, tt := range tests {
+		t.Setenv(apiKeyEnv, tt.env)
+		if key, from := lookupAPIKey(tt.flag, cfg); key != tt.key || from != tt.from {
+			t.Errorf("lookupAPIKey(%q) with $%s=%q = %q, %q, want %q, %q", tt.flag, apiKeyEnv, tt.env, key, from, tt.key, tt.from)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// graphNode is an asset of the relationship graph; Kind is domain,
+// subdomain, ip, asn or cert
+type graphNode struct {
+	ID, Label, Kind string
+}
+
+// graphEdge links two assets; Relation names how, e.g. "resolves to"
+type graphEdge struct {
+	From, To, Relation string
+}
+
+// assetGraph is the relationship graph of a result: apex domain →
+// subdomain → IP → ASN, and subdomain → TLS certificate
+type assetGraph struct {
+	Name  string
+	Nodes []graphNode
+	Edges []graphEdge
+
+	nodes map[string]bool
+	edges map[graphEdge]bool
+}
+
+// node adds an asset once and returns its ID
+func (g *assetGraph) node(kind, key, label string) string {
+	id := kind + ":" + key
+	if !g.nodes[id] {
+		g.nodes[id] = true
+		g.Nodes = append(g.Nodes, graphNode{ID: id, Label: label, Kind: kind})
+	}
+	return id
+}
+
+// edge links two assets once
+func (g *assetGraph) edge(from, to, relation string) {
+	e := graphEdge{From: from, To: to, Relation: relation}
+	if !g.edges[e] {
+		g.edges[e] = true
+		g.Edges = append(g.Edges, e)
+	}
+}
+
+// resultGraph builds the graph of a result. The subdomains of a pivot hang
+// off their registered domains, those of an enumeration off its domain.
+func resultGraph(result *shodanx.Result) *assetGraph {
+	g := &assetGraph{Name: result.Domain, nodes: map[string]bool{}, edges: map[graphEdge]bool{}}
+	apex := map[string]string{}
+	if len(result.Domains) > 0 {
+		for domain, names := range shodanx.GroupByDomain(result.Subdomains) {
+			for _, name := range names {
+				apex[name] = domain
+			}
+		}
+	}
+	root := g.node("domain", result.Domain, result.Domain)
+
+	addIP := func(from, ip string) {
+		id := g.node("ip", ip, ip)
+		g.edge(from, id, "resolves to")
+		if geo := result.Geo[ip]; geo != nil && geo.ASN != "" {
+			label := geo.ASN
+			if geo.Org != "" {
+				label += " " + geo.Org
+			}
+			g.edge(id, g.node("asn", geo.ASN, label), "announced by")
+		}
+	}
+	for _, name := range result.Subdomains {
+		parent := root
+		if domain := apex[name]; domain != "" && domain != name {
+			parent = g.node("domain", domain, domain)
+			g.edge(root, parent, "owns")
+		}
+		id := g.node("subdomain", name, name)
+		g.edge(parent, id, "has subdomain")
+		for _, ip := range result.HostAddresses(name) {
+			addIP(id, ip)
+		}
+		for _, svc := range result.Services[name] {
+			if svc.CertSHA256 == "" {
+				continue
+			}
+			label := svc.CertSubject
+			if label == "" {
+				label = "certificate"
+			}
+			label += " (" + svc.CertSHA256[:min(12, len(svc.CertSHA256))] + ")"
+			g.edge(id, g.node("cert", svc.CertSHA256, label), "presents")
+		}
+	}
+	for _, ip := range result.Addresses {
+		addIP(root, ip)
+	}
+	return g
+}
+
+// dotStyle is the shape and colour of each kind of node in DOT
+var dotStyle = map[string]string{
+	"domain":    `shape=doubleoctagon, fillcolor="#f4b942"`,
+	"subdomain": `shape=box, fillcolor="#9cc3e6"`,
+	"ip":        `shape=ellipse, fillcolor="#a8d5a2"`,
+	"asn":       `shape=hexagon, fillcolor="#d9a6e0"`,
+	"cert":      `shape=note, fillcolor="#f2b8b5"`,
+}
+
+// dotQuote quotes s as a DOT ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// dot renders the graph in the Graphviz DOT language
+func (g *assetGraph) dot() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Name))
+	b.WriteString("  graph [rankdir=LR, overlap=false];\n")
+	b.WriteString("  node [style=filled, fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=8, color=\"#777777\"];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, kind=%s, %s];\n", dotQuote(n.ID), dotQuote(n.Label), n.Kind, dotStyle[n.Kind])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Relation))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// GraphML documents; Gephi, yEd and Cytoscape show the label key as name
+type (
+	graphMLKey struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	graphMLData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	graphMLNode struct {
+		ID   string        `xml:"id,attr"`
+		Data []graphMLData `xml:"data"`
+	}
+	graphMLEdge struct {
+		Source string        `xml:"source,attr"`
+		Target string        `xml:"target,attr"`
+		Data   []graphMLData `xml:"data"`
+	}
+	graphML struct {
+		XMLName xml.Name     `xml:"graphml"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		Keys    []graphMLKey `xml:"key"`
+		Graph   struct {
+			ID          string        `xml:"id,attr"`
+			EdgeDefault string        `xml:"edgedefault,attr"`
+			Nodes       []graphMLNode `xml:"node"`
+			Edges       []graphMLEdge `xml:"edge"`
+		} `xml:"graph"`
+	}
+)
+
+// graphML renders the graph as GraphML
+func (g *assetGraph) graphML() ([]byte, error) {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "relation", For: "edge", Name: "relation", Type: "string"},
+		},
+	}
+	doc.Graph.ID, doc.Graph.EdgeDefault = g.Name, "directed"
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID,
+			Data: []graphMLData{{Key: "label", Value: n.Label}, {Key: "kind", Value: n.Kind}}})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.From, Target: e.To,
+			Data: []graphMLData{{Key: "relation", Value: e.Relation}}})
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// saveGraph writes the relationship graph of a result to <prefix>.dot or
+// <prefix>.graphml
+func saveGraph(w io.Writer, result *shodanx.Result, outputPrefix, format string) error {
+	g := resultGraph(result)
+	graphFile := outputPrefix + "." + format
+	var data []byte
+	var err error
+	if format == "dot" {
+		data = []byte(g.dot())
+	} else {
+		data, err = g.graphML()
+	}
+	if err == nil {
+		err = os.WriteFile(graphFile, data, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to save graph file %s: %v\n", graphFile, err)
+		return err
+	}
+	fmt.Fprintf(w, "[+] Graph of %d nodes and %d edges saved to %s\n", len(g.Nodes), len(g.Edges), graphFile)
+	return nil
+}
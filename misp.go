@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// mispTimeout bounds each request to MISP
+const mispTimeout = 2 * time.Minute
+
+// mispExporter keeps a MISP event per domain whose attributes are the
+// domains, hostnames and addresses found, see --export misp. Every run adds
+// what earlier runs did not, so the event holds the whole external footprint.
+type mispExporter struct {
+	url    string
+	key    string
+	client *http.Client
+
+	// name is the URL, for messages
+	name string
+}
+
+// mispAttribute is an attribute of a MISP event. The assets are the
+// organisation's own, so they are not flagged for IDS export.
+type mispAttribute struct {
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Value    string `json:"value"`
+	ToIDS    bool   `json:"to_ids"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// mispEvent is the part of a MISP event the exporter reads and writes.
+// Numeric fields are strings in MISP's responses.
+type mispEvent struct {
+	ID            string          `json:"id,omitempty"`
+	Info          string          `json:"info"`
+	Distribution  string          `json:"distribution,omitempty"`
+	ThreatLevelID string          `json:"threat_level_id,omitempty"`
+	Analysis      string          `json:"analysis,omitempty"`
+	Attribute     []mispAttribute `json:"Attribute,omitempty"`
+	Tag           []mispTag       `json:"Tag,omitempty"`
+}
+
+// mispTag is a tag of a MISP event
+type mispTag struct {
+	Name string `json:"name"`
+}
+
+// newMISPExporter checks the API key against the MISP instance at rawURL,
+// e.g. https://misp.example.com; ?insecure=true skips TLS verification for
+// self-signed certificates
+func newMISPExporter(rawURL, key string) (*mispExporter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid MISP URL %q, expected https://misp.example.com", rawURL)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if u.Query().Get("insecure") == "true" {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	u.RawQuery = ""
+	m := &mispExporter{url: strings.TrimSuffix(u.String(), "/"), key: key,
+		client: &http.Client{Timeout: mispTimeout, Transport: transport}, name: u.Redacted()}
+	var version struct {
+		Version string `json:"version"`
+	}
+	if err := m.do(context.Background(), http.MethodGet, "/servers/getVersion", nil, &version); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// do sends a request to the MISP API and decodes the JSON response into out,
+// if not nil
+func (m *mispExporter) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.url+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", m.key)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", m.name, urlErrorCause(err))
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%s rejected the API key: %s", m.name, resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, truncate(string(data), 300))
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// mispEventInfo is the title of the event of a domain, by which later runs
+// find it again
+func mispEventInfo(domain string) string {
+	return "shodanX external assets: " + domain
+}
+
+// mispAttributes returns the attributes of a result: the domains, the
+// subdomains, their addresses and the pairs of both
+func mispAttributes(result *shodanx.Result) []mispAttribute {
+	var attrs []mispAttribute
+	seen := map[string]bool{}
+	add := func(typ, value, comment string) {
+		if value == "" || seen[typ+"|"+value] {
+			return
+		}
+		seen[typ+"|"+value] = true
+		attrs = append(attrs, mispAttribute{Type: typ, Category: "Network activity", Value: value, Comment: comment})
+	}
+	add("domain", result.Domain, "apex domain")
+	for _, domain := range result.Domains {
+		add("domain", domain, "registered domain")
+	}
+	for _, name := range result.Subdomains {
+		var comment []string
+		if sources := result.Sources[name]; len(sources) > 0 {
+			comment = append(comment, "sources: "+strings.Join(sources, ", "))
+		}
+		if ports := result.HostPorts(name); len(ports) > 0 {
+			comment = append(comment, "ports: "+templateJoin(ports, ", "))
+		}
+		add("hostname", name, strings.Join(comment, "; "))
+	}
+	for _, name := range result.Subdomains {
+		for _, ip := range result.HostAddresses(name) {
+			add("domain|ip", name+"|"+ip, "")
+		}
+	}
+	addIP := func(ip string) {
+		comment := ""
+		if g := result.Geo[ip]; g != nil && g.ASN != "" {
+			comment = strings.TrimSpace(g.ASN + " " + g.Org)
+		}
+		add("ip-dst", ip, comment)
+	}
+	for _, name := range result.Subdomains {
+		for _, ip := range result.HostAddresses(name) {
+			addIP(ip)
+		}
+	}
+	for _, ip := range result.Addresses {
+		addIP(ip)
+	}
+	return attrs
+}
+
+// findEvent returns the ID of the event titled info, or "" if there is none
+func (m *mispExporter) findEvent(ctx context.Context, info string) (string, error) {
+	var resp struct {
+		Response []struct {
+			Event mispEvent `json:"Event"`
+		} `json:"response"`
+	}
+	search := map[string]interface{}{"returnFormat": "json", "eventinfo": info, "metadata": true}
+	if err := m.do(ctx, http.MethodPost, "/events/restSearch", search, &resp); err != nil {
+		return "", err
+	}
+	// eventinfo matches substrings
+	for _, r := range resp.Response {
+		if r.Event.Info == info {
+			return r.Event.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// export creates the event of the domain of a result with its attributes,
+// or adds the attributes the event lacks. It returns the event ID and the
+// number of attributes added.
+func (m *mispExporter) export(result *shodanx.Result) (string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	attrs := mispAttributes(result)
+	info := mispEventInfo(result.Domain)
+	id, err := m.findEvent(ctx, info)
+	if err != nil {
+		return "", 0, err
+	}
+	if id == "" {
+		// Distribution 0 keeps the event in the organisation, threat level
+		// 4 is undefined and analysis 1 ongoing
+		event := mispEvent{Info: info, Distribution: "0", ThreatLevelID: "4", Analysis: "1",
+			Attribute: attrs, Tag: []mispTag{{Name: "shodanx"}}}
+		var resp struct {
+			Event mispEvent `json:"Event"`
+		}
+		if err := m.do(ctx, http.MethodPost, "/events/add", map[string]mispEvent{"Event": event}, &resp); err != nil {
+			return "", 0, err
+		}
+		return resp.Event.ID, len(attrs), nil
+	}
+
+	var resp struct {
+		Event mispEvent `json:"Event"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/events/view/"+url.PathEscape(id), nil, &resp); err != nil {
+		return "", 0, err
+	}
+	existing := map[string]bool{}
+	for _, a := range resp.Event.Attribute {
+		existing[a.Type+"|"+a.Value] = true
+	}
+	var missing []mispAttribute
+	for _, a := range attrs {
+		if !existing[a.Type+"|"+a.Value] {
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) > 0 {
+		if err := m.do(ctx, http.MethodPost, "/attributes/add/"+url.PathEscape(id), missing, nil); err != nil {
+			return "", 0, err
+		}
+	}
+	return id, len(missing), nil
+}
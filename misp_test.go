@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestMISPAttributes(t *testing.T) {
+	result := &shodanx.Result{
+		Domain:     "example.com",
+		Domains:    []string{"example.org"},
+		Subdomains: []string{"www.example.com", "mail.example.com"},
+		Sources:    map[string][]string{"www.example.com": {"shodan", "crt.sh"}},
+		IPs:        map[string][]string{"mail.example.com": {"192.0.2.1"}},
+		Services: map[string][]shodanx.Service{"www.example.com": {
+			{IP: "192.0.2.1", Port: 443}, {IP: "192.0.2.1", Port: 80},
+		}},
+		Geo:       map[string]*shodanx.GeoInfo{"192.0.2.1": {ASN: "AS64500", Org: "Example"}},
+		Addresses: []string{"198.51.100.7"},
+	}
+	attr := func(typ, value, comment string) mispAttribute {
+		return mispAttribute{Type: typ, Category: "Network activity", Value: value, Comment: comment}
+	}
+	want := []mispAttribute{
+		attr("domain", "example.com", "apex domain"),
+		attr("domain", "example.org", "registered domain"),
+		attr("hostname", "www.example.com", "sources: shodan, crt.sh; ports: 80, 443"),
+		attr("hostname", "mail.example.com", ""),
+		attr("domain|ip", "www.example.com|192.0.2.1", ""),
+		attr("domain|ip", "mail.example.com|192.0.2.1", ""),
+		attr("ip-dst", "192.0.2.1", "AS64500 Example"),
+		attr("ip-dst", "198.51.100.7", ""),
+	}
+	if got := mispAttributes(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("mispAttributes =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// mongoTimeout bounds connecting to MongoDB and each write
+const mongoTimeout = 30 * time.Second
+
+// mongoStore writes results to MongoDB, see --mongo: one document per asset
+// in the assets collection and the banners Shodan returned, unmodified, in
+// the banners collection
+type mongoStore struct {
+	client *mongo.Client
+	db     *mongo.Database
+
+	// name is the URI without password, for messages
+	name string
+}
+
+// openMongo connects to the MongoDB at uri. The database is the path of the
+// URI, shodanx if it has none.
+func openMongo(uri string) (*mongoStore, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MongoDB URI: %w", err)
+	}
+	dbName := strings.Trim(u.Path, "/")
+	if dbName == "" {
+		dbName = "shodanx"
+	}
+	client, err := mongo.Connect(mongooptions.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	// Connect is lazy, so check the server before the run
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to connect to %s: %w", u.Redacted(), err)
+	}
+	return &mongoStore{client: client, db: client.Database(dbName), name: u.Redacted()}, nil
+}
+
+// Close disconnects from MongoDB
+func (s *mongoStore) Close() error {
+	return s.client.Disconnect(context.Background())
+}
+
+// rawBanner returns a banner as Shodan returned it, or as encoded by
+// shodanX for matches of other sources
+func rawBanner(m *shodanx.Match) []byte {
+	if len(m.Raw) > 0 {
+		return m.Raw
+	}
+	raw, _ := json.Marshal(m)
+	return raw
+}
+
+// bannerID identifies a banner by service and crawl time, so the same banner
+// is stored once however often it is matched, while later crawls of the
+// service are kept next to it
+func bannerID(m *shodanx.Match) string {
+	return fmt.Sprintf("%s:%d/%s@%s", m.IPStr, m.Port, m.Transport, m.Timestamp)
+}
+
+// save upserts the assets of a result and the banners its run matched
+func (s *mongoStore) save(w io.Writer, result *shodanx.Result, matches []*shodanx.Match) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	var assets []mongo.WriteModel
+	for _, name := range result.Subdomains {
+		firstSeen, ok := result.FirstSeen[name]
+		if !ok {
+			firstSeen = now
+		}
+		assets = append(assets, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": name}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"domain":       result.Domain,
+					"ips":          result.HostAddresses(name),
+					"ports":        result.HostPorts(name),
+					"services":     result.Services[name],
+					"sources":      result.Sources[name],
+					"technologies": result.HostTechnologies(name),
+					"risk":         result.RiskOf(name),
+					"last_seen":    now,
+				},
+				"$setOnInsert": bson.M{"first_seen": firstSeen},
+			}).
+			SetUpsert(true))
+	}
+	if len(assets) > 0 {
+		if _, err := s.db.Collection("assets").BulkWrite(ctx, assets, mongooptions.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to write assets: %w", err)
+		}
+	}
+
+	var banners []mongo.WriteModel
+	for _, m := range matches {
+		var doc bson.M
+		if err := bson.UnmarshalExtJSON(rawBanner(m), false, &doc); err != nil {
+			fmt.Fprintf(w, "[!] Banner of %s:%d not stored: %v\n", m.IPStr, m.Port, err)
+			continue
+		}
+		doc["_id"] = bannerID(m)
+		doc["_shodanx"] = bson.M{"domain": result.Domain, "collected": now}
+		banners = append(banners, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": doc["_id"]}).
+			SetReplacement(doc).
+			SetUpsert(true))
+	}
+	if len(banners) > 0 {
+		if _, err := s.db.Collection("banners").BulkWrite(ctx, banners, mongooptions.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to write banners: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,714 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// defaultFormats are written when neither the config nor the command line choose any
+var defaultFormats = []string{"txt", "json"}
+
+// outputFormats are the formats --format and the config file accept
+var outputFormats = []string{"txt", "json", "csv", "jsonl", "md", "html", "nmap", "targets", "burp", "urls", "web", "dot", "graphml", "xlsx", "sarif"}
+
+// exportTargets are the systems --export sends results to
+var exportTargets = []string{"elasticsearch", "kafka", "nats", "openvas", "misp"}
+
+// hasFormat reports whether name is one of the requested output formats
+func hasFormat(formats []string, name string) bool {
+	for _, f := range formats {
+		if strings.EqualFold(strings.TrimSpace(f), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IMPROVED SAVING FUNCTION WITH ERROR HANDLING AND FALLBACK
+func saveResults(w io.Writer, result *shodanx.Result, outputPrefix string, formats []string) error {
+	allSubs := result.Subdomains
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+
+	// Create output directory if it doesn't exist
+	outputDir := filepath.Dir(outputPrefix)
+	if outputDir != "." && outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			fmt.Fprintf(w, "Warning: Could not create directory %s: %v\n", outputDir, err)
+		}
+	}
+
+	// Save TXT first (most reliable format)
+	if hasFormat(formats, "txt") {
+		txtFile := outputPrefix + ".txt"
+		txtContent := strings.Join(allSubs, "\n")
+		if err := os.WriteFile(txtFile, []byte(txtContent), 0644); err != nil {
+			fmt.Fprintf(w, "Error: Failed to save TXT file %s: %v\n", txtFile, err)
+			return err
+		}
+		fmt.Fprintln(w, "[+] TXT results saved to", txtFile)
+
+		// IPs are kept apart so the TXT file only lists hostnames
+		if len(result.Addresses) > 0 {
+			ipFile := outputPrefix + "_ips.txt"
+			if err := os.WriteFile(ipFile, []byte(strings.Join(result.Addresses, "\n")), 0644); err != nil {
+				fmt.Fprintf(w, "Error: Failed to save TXT file %s: %v\n", ipFile, err)
+				return err
+			}
+			fmt.Fprintln(w, "[+] IP addresses saved to", ipFile)
+		}
+
+		if len(result.Exposed) > 0 {
+			lines := make([]string, len(result.Exposed))
+			for i := range result.Exposed {
+				lines[i] = formatExposure(&result.Exposed[i])
+			}
+			exposedFile := outputPrefix + "_exposed.txt"
+			if err := os.WriteFile(exposedFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+				fmt.Fprintf(w, "Error: Failed to save TXT file %s: %v\n", exposedFile, err)
+				return err
+			}
+			fmt.Fprintln(w, "[+] Exposed services saved to", exposedFile)
+		}
+
+		if certs := result.CertFindings(); len(certs) > 0 {
+			lines := make([]string, len(certs))
+			for i := range certs {
+				lines[i] = formatCertFinding(&certs[i])
+			}
+			certFile := outputPrefix + "_certs.txt"
+			if err := os.WriteFile(certFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+				fmt.Fprintf(w, "Error: Failed to save TXT file %s: %v\n", certFile, err)
+				return err
+			}
+			fmt.Fprintln(w, "[+] Certificate findings saved to", certFile)
+		}
+	}
+
+	if hasFormat(formats, "csv") {
+		if err := saveCSV(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "md") {
+		if err := saveMarkdown(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "html") {
+		if err := saveHTML(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "xlsx") {
+		if err := saveXLSX(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "sarif") {
+		if err := saveSARIF(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "nmap") {
+		if err := saveNmap(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "targets") {
+		if err := saveTargets(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "burp") {
+		if err := saveBurp(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "urls") {
+		if err := saveURLs(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	if hasFormat(formats, "web") {
+		if err := saveWeb(w, result, outputPrefix); err != nil {
+			return err
+		}
+	}
+
+	for _, format := range []string{"dot", "graphml"} {
+		if hasFormat(formats, format) {
+			if err := saveGraph(w, result, outputPrefix, format); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !hasFormat(formats, "json") {
+		return nil
+	}
+
+	// Try to save JSON format
+	jsonFile := outputPrefix + ".json"
+	jsonData := struct {
+		*shodanx.Result
+		Total int `json:"total"`
+	}{result, len(allSubs)}
+
+	// Attempt JSON marshaling with error handling
+	jsonBytes, err := json.MarshalIndent(jsonData, "", "  ")
+	if err != nil {
+		fmt.Fprintf(w, "Warning: JSON marshaling failed: %v\n", err)
+		fmt.Fprintln(w, "[!] Falling back to CSV format...")
+		return saveCSV(w, result, outputPrefix)
+	}
+
+	// Attempt JSON file writing with error handling
+	if err := os.WriteFile(jsonFile, jsonBytes, 0644); err != nil {
+		fmt.Fprintf(w, "Warning: Failed to save JSON file %s: %v\n", jsonFile, err)
+		fmt.Fprintln(w, "[!] Falling back to CSV format...")
+		return saveCSV(w, result, outputPrefix)
+	}
+
+	fmt.Fprintln(w, "[+] JSON results saved to", jsonFile)
+	return nil
+}
+
+// readKnown returns the subdomains saved by a previous run to <prefix>.txt
+// and <prefix>.json, as --format may leave out either, or nil when there is
+// neither
+func readKnown(outputPrefix string) map[string]bool {
+	var known map[string]bool
+	add := func(name string) {
+		if known == nil {
+			known = map[string]bool{}
+		}
+		known[name] = true
+	}
+	if data, err := os.ReadFile(outputPrefix + ".txt"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				add(line)
+			}
+		}
+	}
+	// ct-monitor appends to the TXT file even where enum only wrote JSON
+	if prev, err := loadResults(outputPrefix); err == nil && prev.Domain != "" {
+		for _, name := range prev.Subdomains {
+			add(name)
+		}
+	}
+	return known
+}
+
+// printStats prints the end-of-run statistics: subdomains per source, alive
+// and dead names, new and previously known names, top ports and credits.
+// known is nil when there is no previous run to compare against.
+func printStats(w io.Writer, result *shodanx.Result, known map[string]bool, credits int) {
+	fmt.Fprintln(w, "\n[+] Run statistics:")
+	line := fmt.Sprintf("%d", len(result.Subdomains))
+	if known != nil {
+		added := 0
+		for _, s := range result.Subdomains {
+			if !known[s] {
+				added++
+			}
+		}
+		line += fmt.Sprintf(" (%d new, %d previously known)", added, len(result.Subdomains)-added)
+	}
+	fmt.Fprintf(w, "  %-20s %s\n", "Subdomains", line)
+
+	if result.IPs != nil {
+		alive := 0
+		for _, s := range result.Subdomains {
+			if len(result.IPs[s]) > 0 {
+				alive++
+			}
+		}
+		fmt.Fprintf(w, "  %-20s %d / %d\n", "Alive / dead", alive, len(result.Subdomains)-alive)
+	}
+	if len(result.Probes) > 0 {
+		fmt.Fprintf(w, "  %-20s %d\n", "Web servers", len(result.Probes))
+	}
+	if ports := result.Summary["port"]; len(ports) > 0 {
+		var top []string
+		for i, v := range ports {
+			if i == 5 {
+				break
+			}
+			top = append(top, fmt.Sprintf("%s (%d)", v.String(), v.Count))
+		}
+		fmt.Fprintf(w, "  %-20s %s\n", "Top ports", strings.Join(top, ", "))
+	}
+	fmt.Fprintf(w, "  %-20s %d\n", "Credits used", credits)
+
+	counts := map[string]int{}
+	var sources []string
+	for _, s := range result.Subdomains {
+		for _, src := range result.Sources[s] {
+			if counts[src] == 0 {
+				sources = append(sources, src)
+			}
+			counts[src]++
+		}
+	}
+	if len(sources) == 0 {
+		return
+	}
+	sort.Strings(sources)
+	sort.SliceStable(sources, func(i, j int) bool { return counts[sources[i]] > counts[sources[j]] })
+	fmt.Fprintln(w, "  Subdomains per source:")
+	for _, src := range sources {
+		fmt.Fprintf(w, "    %-50s %d\n", src, counts[src])
+	}
+}
+
+// printExposures warns about the high-risk services open to the internet
+func printExposures(result *shodanx.Result) {
+	if len(result.Exposed) == 0 {
+		return
+	}
+	fmt.Printf("\n[!] %d HIGH-RISK SERVICES EXPOSED TO THE INTERNET:\n", len(result.Exposed))
+	for i := range result.Exposed {
+		fmt.Println(formatExposure(&result.Exposed[i]))
+	}
+}
+
+// exposureCounts summarises exposures per service, e.g. "Redis 2, RDP 1"
+func exposureCounts(exposed []shodanx.Exposure) string {
+	counts := map[string]int{}
+	var services []string
+	for _, e := range exposed {
+		if counts[e.Service] == 0 {
+			services = append(services, e.Service)
+		}
+		counts[e.Service]++
+	}
+	sort.SliceStable(services, func(i, j int) bool { return counts[services[i]] > counts[services[j]] })
+	parts := make([]string, len(services))
+	for i, s := range services {
+		parts[i] = fmt.Sprintf("%s %d", s, counts[s])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatExposure prints an exposure as
+// "Redis      1.2.3.4:6379 [cache.example.com] Redis 7.0.5"
+func formatExposure(e *shodanx.Exposure) string {
+	line := fmt.Sprintf("%-13s %s:%d", e.Service, e.IP, e.Port)
+	if len(e.Hostnames) > 0 {
+		line += " [" + strings.Join(e.Hostnames, ",") + "]"
+	}
+	if e.Product != "" {
+		line += " " + e.Product
+	}
+	if e.Source == shodanx.ExposureInternetDB {
+		line += " (InternetDB)"
+	}
+	return line
+}
+
+// printServices prints the open ports of every hostname as
+// "api.example.com -> 1.2.3.4 (AS13335, US): 443/https nginx, 22/ssh"
+func printServices(result *shodanx.Result) {
+	if len(result.Services) == 0 {
+		return
+	}
+	fmt.Printf("\n[+] Services on %d hostnames:\n", len(result.Services))
+	for _, name := range result.Subdomains {
+		if list := result.Services[name]; len(list) > 0 {
+			fmt.Printf("%s -> %s\n", displayName(name), formatServices(list, result.Geo))
+		}
+	}
+}
+
+// formatServices groups services sorted by address, separating addresses with "; "
+func formatServices(list []shodanx.Service, geo map[string]*shodanx.GeoInfo) string {
+	var groups []string
+	var ports []string
+	for i, svc := range list {
+		ports = append(ports, svc.String())
+		if i == len(list)-1 || list[i+1].IP != svc.IP {
+			addr := svc.IP
+			if g := geo[svc.IP]; g != nil {
+				if where := joinUnique([]string{g.ASN, g.CountryCode}); where != "" {
+					addr += " (" + strings.ReplaceAll(where, " | ", ", ") + ")"
+				}
+			}
+			groups = append(groups, addr+": "+strings.Join(ports, ", "))
+			ports = nil
+		}
+	}
+	return strings.Join(groups, "; ")
+}
+
+// printMail prints the email records of the domain and its subdomains,
+// their third-party hosts and weaknesses
+func printMail(result *shodanx.Result) {
+	if len(result.Mail) == 0 {
+		return
+	}
+	names := make([]string, 0, len(result.Mail))
+	for name := range result.Mail {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n[+] Email records of %d names:\n", len(names))
+	for _, name := range names {
+		rec := result.Mail[name]
+		fmt.Println(displayName(name))
+		if len(rec.MX) > 0 {
+			fmt.Printf("  MX:    %s\n", strings.Join(rec.MX, ", "))
+		}
+		if rec.SPF != "" {
+			fmt.Printf("  SPF:   %s\n", rec.SPF)
+		}
+		if rec.DMARC != "" {
+			fmt.Printf("  DMARC: %s\n", rec.DMARC)
+		}
+		if len(rec.ThirdParty) > 0 {
+			fmt.Printf("  Third-party hosts: %s\n", strings.Join(rec.ThirdParty, ", "))
+		}
+		for _, issue := range rec.Issues {
+			fmt.Printf("  [!] %s\n", issue)
+		}
+	}
+}
+
+// printBuckets lists the storage buckets found, publicly listable ones first
+func printBuckets(result *shodanx.Result) {
+	if len(result.Buckets) == 0 {
+		return
+	}
+	order := map[string]int{shodanx.BucketListable: 0, shodanx.BucketMissing: 1, shodanx.BucketPrivate: 2, "": 3}
+	buckets := append([]shodanx.Bucket(nil), result.Buckets...)
+	sort.SliceStable(buckets, func(i, j int) bool { return order[buckets[i].Access] < order[buckets[j].Access] })
+
+	fmt.Printf("\n[+] Found %d storage buckets:\n", len(buckets))
+	for _, b := range buckets {
+		access := b.Access
+		switch access {
+		case shodanx.BucketListable:
+			access = "LISTABLE"
+		case shodanx.BucketMissing:
+			access = "MISSING (takeover candidate)"
+		case "":
+			access = "unknown"
+		}
+		via := b.Host
+		if b.CNAME != "" {
+			via += " -> " + b.CNAME
+		}
+		fmt.Printf("%-6s %s [%s] %s\n", b.Provider, b.Name, access, via)
+	}
+}
+
+// printLeaks lists certificate names that point at internal infrastructure
+func printLeaks(result *shodanx.Result) {
+	if len(result.Leaks) == 0 {
+		return
+	}
+	fmt.Printf("\n[+] Found %d possible internal hostname leaks in certificates:\n", len(result.Leaks))
+	for _, l := range result.Leaks {
+		fmt.Printf("%s (%s) seen on %s\n", l.Name, l.Reason, strings.Join(l.Seen, ", "))
+	}
+}
+
+// printRisks lists the subdomains with a risk score, riskiest first, as
+// "85 a.example.com: Redis exposed on 1.2.3.4:6379 (+25), 3 CVEs (+40), ..."
+func printRisks(result *shodanx.Result) {
+	if len(result.Risks) == 0 {
+		return
+	}
+	fmt.Printf("\n[+] Ranked %d subdomains by risk:\n", len(result.Risks))
+	for _, risk := range result.Risks {
+		factors := make([]string, len(risk.Factors))
+		for i, f := range risk.Factors {
+			factors[i] = fmt.Sprintf("%s (+%d)", f.Reason, f.Points)
+		}
+		fmt.Printf("%3d %s: %s\n", risk.Score, risk.Host, strings.Join(factors, ", "))
+	}
+}
+
+// printDNSHistory lists the past addresses of the domain, flagging the
+// ones it no longer resolves to as possible origins behind a CDN
+func printDNSHistory(result *shodanx.Result) {
+	if len(result.DNSHistory) == 0 {
+		return
+	}
+	fmt.Printf("\n[+] DNS history of %s (%d records):\n", result.Domain, len(result.DNSHistory))
+	for _, h := range result.DNSHistory {
+		line := fmt.Sprintf("%s to %s %-5s %s", h.FirstSeen, h.LastSeen, strings.ToUpper(h.Type), strings.Join(h.Values, ", "))
+		if len(h.Organizations) > 0 {
+			line += " (" + strings.Join(h.Organizations, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+	// Without resolution every past address would look gone
+	if old := result.HistoricalAddresses(); len(old) > 0 && result.IPs != nil {
+		fmt.Printf("[!] %d past addresses no longer in DNS, possible origins: %s\n", len(old), strings.Join(old, ", "))
+	}
+}
+
+// printCertFindings lists the expired and self-signed certificates of a result
+func printCertFindings(result *shodanx.Result) {
+	certs := result.CertFindings()
+	if len(certs) == 0 {
+		return
+	}
+	fmt.Printf("\n[+] Found %d expired or self-signed certificates:\n", len(certs))
+	for i := range certs {
+		fmt.Println(formatCertFinding(&certs[i]))
+	}
+}
+
+// printVulnReport lists the CVEs of every host, most severe first, as
+// "9.8 critical CVE-2021-44228 1.2.3.4:443 [a.example.com] verified"
+func printVulnReport(result *shodanx.Result) {
+	report := result.VulnReport()
+	if len(report) == 0 {
+		fmt.Println("\n[+] No CVEs listed for the matched services")
+		return
+	}
+	fmt.Printf("\n[+] Vulnerability report: %d CVEs on %d hosts\n", len(report), len(result.Vulns))
+	for _, v := range report {
+		cvss := "  - "
+		if v.CVSS > 0 {
+			cvss = fmt.Sprintf("%4.1f", v.CVSS)
+		}
+		line := fmt.Sprintf("%s %-8s %-16s %s:%d", cvss, shodanx.Severity(v.CVSS), v.CVE, v.IP, v.Port)
+		if len(v.Hostnames) > 0 {
+			line += " [" + strings.Join(v.Hostnames, ",") + "]"
+		}
+		if v.Verified {
+			line += " verified"
+		}
+		fmt.Println(line)
+	}
+}
+
+// formatCertFinding prints a certificate finding as
+// "1.2.3.4:443 [a.example.com] expired 2023-01-31 self-signed CN=a.example.com"
+func formatCertFinding(f *shodanx.CertFinding) string {
+	// Probed hosts have no IP but a single hostname
+	var parts []string
+	if f.IP != "" {
+		parts = append(parts, fmt.Sprintf("%s:%d", f.IP, f.Port))
+		if len(f.Hostnames) > 0 {
+			parts = append(parts, "["+strings.Join(f.Hostnames, ",")+"]")
+		}
+	} else {
+		parts = append(parts, fmt.Sprintf("%s:%d", strings.Join(f.Hostnames, ","), f.Port))
+	}
+	if f.Expired {
+		parts = append(parts, "expired "+f.Expires)
+	}
+	if f.SelfSigned {
+		parts = append(parts, "self-signed")
+	}
+	if f.Subject != "" {
+		parts = append(parts, "CN="+f.Subject)
+	}
+	return strings.Join(parts, " ")
+}
+
+// loadResults reads the JSON file written by saveResults for outputPrefix.
+// A missing file yields an empty result so merges can start from scratch.
+func loadResults(outputPrefix string) (*shodanx.Result, error) {
+	data, err := os.ReadFile(outputPrefix + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return &shodanx.Result{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result shodanx.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s.json: %w", outputPrefix, err)
+	}
+	return &result, nil
+}
+
+// keepFirstSeen carries the first-seen times saved by a previous run to
+// <prefix>.json over to the names result found again
+func keepFirstSeen(result *shodanx.Result, outputPrefix string) {
+	prev, err := loadResults(outputPrefix)
+	if err != nil {
+		return
+	}
+	for name, t := range prev.FirstSeen {
+		if cur, ok := result.FirstSeen[name]; ok && t.Before(cur) {
+			result.FirstSeen[name] = t
+		}
+	}
+}
+
+// joinUnique joins the distinct non-empty values with " | "
+func joinUnique(values []string) string {
+	var kept []string
+	for _, v := range shodanx.Unique(values) {
+		if v != "" {
+			kept = append(kept, v)
+		}
+	}
+	return strings.Join(kept, " | ")
+}
+
+// saveCSV writes one row per subdomain with its addresses, ports, sources,
+// first-seen time, network owner, technologies and risk score. It is also
+// the fallback when the JSON file cannot be written.
+func saveCSV(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	csvFile := outputPrefix + ".csv"
+	file, err := os.Create(csvFile)
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to create CSV file %s: %v\n", csvFile, err)
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write CSV header
+	header := []string{"Domain", "Subdomain", "IPs", "Ports", "Sources", "First Seen",
+		"ASN", "Org", "Country", "Technologies", "Risk"}
+	if err := writer.Write(header); err != nil {
+		fmt.Fprintf(w, "Error: Failed to write CSV header: %v\n", err)
+		return err
+	}
+
+	// Write subdomain data, riskiest first
+	for _, sub := range result.ByRisk() {
+		sources := strings.Join(result.Sources[sub], " | ")
+		ips := result.HostAddresses(sub)
+		var asns, orgs, countries []string
+		for _, ip := range ips {
+			if g := result.Geo[ip]; g != nil {
+				asns = append(asns, g.ASN)
+				orgs = append(orgs, g.Org)
+				countries = append(countries, g.CountryCode)
+			}
+		}
+		var ports []string
+		for _, port := range result.HostPorts(sub) {
+			ports = append(ports, strconv.Itoa(port))
+		}
+		firstSeen := ""
+		if t, ok := result.FirstSeen[sub]; ok {
+			firstSeen = t.Format(time.RFC3339)
+		}
+		row := []string{result.Domain, sub, strings.Join(ips, " | "), strings.Join(ports, " | "), sources, firstSeen,
+			joinUnique(asns), joinUnique(orgs), joinUnique(countries),
+			strings.Join(result.HostTechnologies(sub), " | "), strconv.Itoa(result.RiskOf(sub))}
+		if err := writer.Write(row); err != nil {
+			fmt.Fprintf(w, "Error: Failed to write CSV row: %v\n", err)
+			return err
+		}
+	}
+
+	fmt.Fprintln(w, "[+] CSV results saved to", csvFile)
+	return nil
+}
+
+// assetLine is a line of the JSONL output, one per discovered asset
+type assetLine struct {
+	Type    string   `json:"type"` // subdomain or ip
+	Name    string   `json:"name"`
+	Domain  string   `json:"domain"`
+	Sources []string `json:"sources,omitempty"`
+	Found   string   `json:"found"` // RFC 3339
+}
+
+// assetStream writes assets as JSONL while a run is in progress, so
+// consumers don't have to wait for the final files
+type assetStream struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	file   *os.File // nil when writing to stdout
+	domain string
+	seen   map[string]bool
+	err    error
+}
+
+// newAssetStream streams to <prefix>.jsonl, or to w when prefix is empty
+func newAssetStream(prefix string, w io.Writer, domain string) (*assetStream, error) {
+	s := &assetStream{domain: domain, seen: map[string]bool{}}
+	if prefix != "" {
+		if dir := filepath.Dir(prefix); dir != "." {
+			os.MkdirAll(dir, 0755)
+		}
+		f, err := os.Create(prefix + ".jsonl")
+		if err != nil {
+			return nil, err
+		}
+		s.file, w = f, f
+	}
+	s.w = bufio.NewWriter(w)
+	return s, nil
+}
+
+// add writes an asset unless it was written before
+func (s *assetStream) add(name string, sources ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[name] || s.err != nil {
+		return
+	}
+	s.seen[name] = true
+	line := assetLine{Type: "subdomain", Name: name, Domain: s.domain, Sources: sources, Found: time.Now().UTC().Format(time.RFC3339)}
+	if net.ParseIP(name) != nil {
+		line.Type = "ip"
+	}
+	data, _ := json.Marshal(line)
+	s.w.Write(data)
+	s.w.WriteByte('\n')
+	// Flush per asset so consumers see results in real time
+	s.err = s.w.Flush()
+}
+
+// finish writes the assets of the final result that were not streamed,
+// e.g. names from reverse DNS, and closes the file
+func (s *assetStream) finish(w io.Writer, result *shodanx.Result) error {
+	for _, name := range result.Subdomains {
+		s.add(name, result.Sources[name]...)
+	}
+	for _, ip := range result.Addresses {
+		s.add(ip)
+	}
+	if s.file == nil {
+		return s.err
+	}
+	if err := s.file.Close(); s.err == nil {
+		s.err = err
+	}
+	if s.err == nil {
+		fmt.Fprintln(w, "[+] JSONL results saved to", s.file.Name())
+	}
+	return s.err
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestReadKnown(t *testing.T) {
+	tests := []struct {
+		name      string
+		txt, json string
+		want      map[string]bool
+	}{
+		{name: "no previous run"},
+		{name: "txt only", txt: "www.example.com\n\napi.example.com",
+			want: map[string]bool{"www.example.com": true, "api.example.com": true}},
+		{name: "json only", json: `{"domain": "example.com", "subdomains": ["www.example.com"]}`,
+			want: map[string]bool{"www.example.com": true}},
+		{name: "ct-monitor appended to enum's json", txt: "new.example.com",
+			json: `{"domain": "example.com", "subdomains": ["www.example.com"]}`,
+			want: map[string]bool{"www.example.com": true, "new.example.com": true}},
+		{name: "invalid json", json: `{"domain":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix := filepath.Join(t.TempDir(), "results")
+			if tt.txt != "" {
+				os.WriteFile(prefix+".txt", []byte(tt.txt), 0644)
+			}
+			if tt.json != "" {
+				os.WriteFile(prefix+".json", []byte(tt.json), 0644)
+			}
+			if got := readKnown(prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readKnown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveCSV(t *testing.T) {
+	result := &shodanx.Result{
+		Domain:     "example.com",
+		Subdomains: []string{"mail.example.com", "www.example.com"},
+		Sources:    map[string][]string{"www.example.com": {"shodan", "crt.sh"}, "mail.example.com": {"dns"}},
+		FirstSeen:  map[string]time.Time{"www.example.com": time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		IPs:        map[string][]string{"mail.example.com": {"192.0.2.9"}},
+		Services: map[string][]shodanx.Service{"www.example.com": {
+			{IP: "192.0.2.1", Port: 443, Product: "nginx", Version: "1.18.0"},
+			{IP: "192.0.2.2", Port: 80, Product: "nginx", Version: "1.18.0"},
+		}},
+		Geo: map[string]*shodanx.GeoInfo{
+			"192.0.2.1": {ASN: "AS64500", Org: "Example", CountryCode: "DE"},
+			"192.0.2.2": {ASN: "AS64500", Org: "Example", CountryCode: "FR"},
+		},
+		Risks: []shodanx.AssetRisk{{Host: "www.example.com", Score: 12}},
+	}
+	prefix := filepath.Join(t.TempDir(), "results")
+	if err := saveCSV(io.Discard, result, prefix); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(prefix + ".csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Domain", "Subdomain", "IPs", "Ports", "Sources", "First Seen", "ASN", "Org", "Country", "Technologies", "Risk"},
+		{"example.com", "www.example.com", "192.0.2.1 | 192.0.2.2", "80 | 443", "shodan | crt.sh", "2024-05-01T12:00:00Z",
+			"AS64500", "Example", "DE | FR", "nginx 1.18.0", "12"},
+		{"example.com", "mail.example.com", "192.0.2.9", "", "dns", "", "", "", "", "", "0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("CSV rows = %q, want %q", rows, want)
+	}
+}
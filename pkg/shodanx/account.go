@@ -0,0 +1,56 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrCreditBudget is returned once a request would exceed Client.MaxCredits.
+var ErrCreditBudget = errors.New("query credit budget exhausted")
+
+// APIInfo describes the plan and remaining credits of an API key.
+type APIInfo struct {
+	Plan         string      `json:"plan"`
+	QueryCredits int         `json:"query_credits"`
+	ScanCredits  int         `json:"scan_credits"`
+	MonitoredIPs int         `json:"monitored_ips"`
+	UnlockedLeft int         `json:"unlocked_left"`
+	Unlocked     bool        `json:"unlocked"`
+	HTTPS        bool        `json:"https"`
+	Telnet       bool        `json:"telnet"`
+	UsageLimits  UsageLimits `json:"usage_limits"`
+}
+
+// UsageLimits are the monthly limits of the plan.
+type UsageLimits struct {
+	QueryCredits int `json:"query_credits"`
+	ScanCredits  int `json:"scan_credits"`
+	MonitoredIPs int `json:"monitored_ips"`
+}
+
+// APIInfo returns the plan and remaining credits of the client's key. It does
+// not consume credits.
+func (c *Client) APIInfo(ctx context.Context) (*APIInfo, error) {
+	var info APIInfo
+	if err := c.getJSON(ctx, "/api-info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// CreditsUsed returns the number of query credits spent through this client
+func (c *Client) CreditsUsed() int {
+	return int(atomic.LoadInt64(&c.creditsUsed))
+}
+
+// spendCredit accounts for one query credit, failing with ErrCreditBudget
+// when MaxCredits would be exceeded
+func (c *Client) spendCredit() error {
+	used := atomic.AddInt64(&c.creditsUsed, 1)
+	if c.MaxCredits > 0 && used > int64(c.MaxCredits) {
+		atomic.AddInt64(&c.creditsUsed, -1)
+		return ErrCreditBudget
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package shodanx
+
+import (
+	"context"
+	"net/url"
+)
+
+// Alert is a Shodan network alert monitoring a set of IPs and ranges.
+type Alert struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Created    string                 `json:"created"`
+	Expires    int                    `json:"expires"`
+	Expiration string                 `json:"expiration,omitempty"`
+	Size       int                    `json:"size"`
+	Filters    AlertFilters           `json:"filters"`
+	Triggers   map[string]interface{} `json:"triggers,omitempty"`
+	Notifiers  []Notifier             `json:"notifiers,omitempty"`
+}
+
+// AlertFilters selects what an alert monitors.
+type AlertFilters struct {
+	IP []string `json:"ip"`
+}
+
+// CreateAlert creates a network alert for the given IPs/CIDRs. expires is the
+// lifetime in seconds; zero never expires.
+func (c *Client) CreateAlert(ctx context.Context, name string, ips []string, expires int) (*Alert, error) {
+	payload := struct {
+		Name    string       `json:"name"`
+		Filters AlertFilters `json:"filters"`
+		Expires int          `json:"expires"`
+	}{name, AlertFilters{IP: ips}, expires}
+
+	var alert Alert
+	if err := c.postJSON(ctx, "/shodan/alert", payload, &alert); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// Alerts lists the network alerts of the account
+func (c *Client) Alerts(ctx context.Context) ([]Alert, error) {
+	var alerts []Alert
+	if err := c.getJSON(ctx, "/shodan/alert/info", nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Alert returns a single network alert
+func (c *Client) Alert(ctx context.Context, id string) (*Alert, error) {
+	var alert Alert
+	if err := c.getJSON(ctx, "/shodan/alert/"+url.PathEscape(id)+"/info", nil, &alert); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// DeleteAlert removes a network alert
+func (c *Client) DeleteAlert(ctx context.Context, id string) error {
+	return c.delete(ctx, "/shodan/alert/"+url.PathEscape(id))
+}
+
+// EnableTrigger turns on a trigger (e.g. "malware", "open_database", "new_service") for an alert
+func (c *Client) EnableTrigger(ctx context.Context, alertID, trigger string) error {
+	return c.put(ctx, "/shodan/alert/"+url.PathEscape(alertID)+"/trigger/"+url.PathEscape(trigger))
+}
+
+// AddNotifier attaches a notifier to an alert so triggered events are delivered through it
+func (c *Client) AddNotifier(ctx context.Context, alertID, notifierID string) error {
+	return c.put(ctx, "/shodan/alert/"+url.PathEscape(alertID)+"/notifier/"+url.PathEscape(notifierID))
+}
+
+// RemoveNotifier detaches a notifier from an alert
+func (c *Client) RemoveNotifier(ctx context.Context, alertID, notifierID string) error {
+	return c.delete(ctx, "/shodan/alert/"+url.PathEscape(alertID)+"/notifier/"+url.PathEscape(notifierID))
+}
@@ -0,0 +1,189 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterSource("archives", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("archives", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			c.logf("[*] Harvesting archived URLs from the Wayback Machine and urlscan.io")
+			names, err := c.Wayback(ctx, domain)
+			if !send(SourceResult{Source: SourceWayback, Names: names, Err: err}) {
+				return
+			}
+			names, err = c.URLScan(ctx, domain, opts.MaxPages)
+			send(SourceResult{Source: SourceURLScan, Names: names, Err: err})
+		})
+	})
+	RegisterSourceCheck("archives", func(ctx context.Context, c *Client) (string, error) {
+		if err := c.ping(ctx, SourceWayback, urlOr(c.WaybackURL, DefaultWaybackURL)); err != nil {
+			return "", err
+		}
+		return c.urlScanQuota(ctx)
+	})
+}
+
+// Archive endpoints searched for URLs under a domain
+const (
+	DefaultWaybackURL = "https://web.archive.org"
+	DefaultURLScanURL = "https://urlscan.io"
+)
+
+// Sources recorded for subdomains from web archives
+const (
+	SourceWayback = "wayback"
+	SourceURLScan = "urlscan"
+)
+
+// WaybackLimit caps the archived URLs read from the Wayback CDX API, which
+// can hold millions for large sites.
+var WaybackLimit = 100000
+
+// URLScanPageSize is the number of results per urlscan.io search page.
+const URLScanPageSize = 100
+
+// Wayback returns the names of domain and its subdomains found in the URLs
+// archived by the Wayback Machine, many of which are gone from DNS. It needs
+// no API key.
+func (c *Client) Wayback(ctx context.Context, domain string) ([]string, error) {
+	base := c.WaybackURL
+	if base == "" {
+		base = DefaultWaybackURL
+	}
+	params := url.Values{
+		"url":      {"*." + domain + "/*"},
+		"output":   {"json"},
+		"fl":       {"original"},
+		"collapse": {"urlkey"},
+		"limit":    {strconv.Itoa(WaybackLimit)},
+	}
+
+	// The first row is the field header
+	var rows [][]string
+	err := c.call(ctx, request{
+		source: SourceWayback,
+		method: http.MethodGet,
+		url:    strings.TrimRight(base, "/") + "/cdx/search/cdx?" + params.Encode(),
+	}, &rows)
+	if err != nil {
+		return nil, sourceError("wayback", err)
+	}
+	var names []string
+	for i, row := range rows {
+		if i == 0 || len(row) == 0 {
+			continue
+		}
+		if name := urlHost(row[0]); InDomain(name, domain) {
+			names = append(names, name)
+		}
+	}
+	return Unique(names), nil
+}
+
+// URLScan returns the names of domain and its subdomains seen in urlscan.io
+// scans, fetching up to maxPages pages (zero or less fetches all). The API
+// key is optional but raises the rate limits.
+func (c *Client) URLScan(ctx context.Context, domain string, maxPages int) ([]string, error) {
+	base := c.URLScanURL
+	if base == "" {
+		base = DefaultURLScanURL
+	}
+	var header http.Header
+	if c.URLScanKey != "" {
+		header = http.Header{"Api-Key": {c.URLScanKey}}
+	}
+
+	var names []string
+	after := ""
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		params := url.Values{"q": {"domain:" + domain}, "size": {strconv.Itoa(URLScanPageSize)}}
+		if after != "" {
+			params.Set("search_after", after)
+		}
+		var resp struct {
+			Results []struct {
+				Page struct {
+					Domain string `json:"domain"`
+					URL    string `json:"url"`
+				} `json:"page"`
+				Task struct {
+					Domain string `json:"domain"`
+				} `json:"task"`
+				Sort []json.RawMessage `json:"sort"`
+			} `json:"results"`
+			HasMore bool `json:"has_more"`
+		}
+		err := c.call(ctx, request{
+			source: SourceURLScan,
+			method: http.MethodGet,
+			url:    strings.TrimRight(base, "/") + "/api/v1/search/?" + params.Encode(),
+			header: header,
+		}, &resp)
+		if err != nil {
+			return Unique(names), sourceError("urlscan", err)
+		}
+		for _, res := range resp.Results {
+			for _, name := range []string{res.Page.Domain, res.Task.Domain, urlHost(res.Page.URL)} {
+				if name = NormalizeHostname(name); InDomain(name, domain) {
+					names = append(names, name)
+				}
+			}
+		}
+		if !resp.HasMore || len(resp.Results) == 0 {
+			break
+		}
+		// The next page starts after the sort values of the last result
+		var keys []string
+		for _, v := range resp.Results[len(resp.Results)-1].Sort {
+			keys = append(keys, strings.Trim(string(v), `"`))
+		}
+		after = strings.Join(keys, ",")
+	}
+	return Unique(names), nil
+}
+
+// urlHost returns the normalized hostname of a URL, or "" if it has none
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return NormalizeHostname(u.Hostname())
+}
+
+// urlScanQuota returns the urlscan.io searches left today, or only checks
+// connectivity without a key
+func (c *Client) urlScanQuota(ctx context.Context) (string, error) {
+	base := strings.TrimRight(urlOr(c.URLScanURL, DefaultURLScanURL), "/")
+	if c.URLScanKey == "" {
+		return "", c.ping(ctx, SourceURLScan, base)
+	}
+	var resp struct {
+		Limits struct {
+			Search struct {
+				Day struct {
+					Limit     int `json:"limit"`
+					Remaining int `json:"remaining"`
+				} `json:"day"`
+			} `json:"search"`
+		} `json:"limits"`
+	}
+	err := c.call(ctx, request{
+		source: SourceURLScan,
+		method: http.MethodGet,
+		url:    base + "/user/quotas/",
+		header: http.Header{"Api-Key": {c.URLScanKey}},
+	}, &resp)
+	if err != nil {
+		return "", sourceError("urlscan", err)
+	}
+	day := resp.Limits.Search.Day
+	return fmt.Sprintf("urlscan.io: %d of %d searches left today", day.Remaining, day.Limit), nil
+}
@@ -0,0 +1,71 @@
+package shodanx
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWayback(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cdx/search/cdx" || r.URL.Query().Get("url") != "*.example.com/*" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`[["original"],
+			["http://www.example.com/index.html"],
+			["https://OLD.example.com:8443/login?next=/"],
+			["http://www.example.com/about"],
+			["https://example.org/"],
+			[]]`))
+	})
+	c.WaybackURL = c.BaseURL
+	names, err := c.Wayback(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"www.example.com", "old.example.com"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Wayback = %v, want %v", names, want)
+	}
+}
+
+func TestURLScan(t *testing.T) {
+	pages := map[string]string{
+		"": `{"results": [
+			{"page": {"domain": "www.example.com", "url": "https://www.example.com/"}, "task": {"domain": "example.com"}, "sort": [1700000000000, "abc"]}
+		], "has_more": true}`,
+		"1700000000000,abc": `{"results": [
+			{"page": {"domain": "cdn.other.net", "url": "https://shop.example.com/cart"}, "task": {"domain": "shop.example.com"}, "sort": [1600000000000, "def"]}
+		], "has_more": false}`,
+	}
+	tests := []struct {
+		name     string
+		maxPages int
+		want     []string
+	}{
+		{"all pages", 0, []string{"www.example.com", "example.com", "shop.example.com"}},
+		{"first page", 1, []string{"www.example.com", "example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Api-Key") != "urlscankey" {
+					t.Errorf("API key not sent")
+				}
+				page, ok := pages[r.URL.Query().Get("search_after")]
+				if !ok {
+					t.Errorf("unexpected search_after %q", r.URL.Query().Get("search_after"))
+				}
+				w.Write([]byte(page))
+			})
+			c.URLScanURL, c.URLScanKey = c.BaseURL, "urlscankey"
+			names, err := c.URLScan(context.Background(), "example.com", tt.maxPages)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("URLScan = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
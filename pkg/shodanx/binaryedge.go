@@ -0,0 +1,229 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func init() {
+	RegisterSource("binaryedge", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("binaryedge", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			c.logf("[*] Fetching subdomains and hosts from BinaryEdge")
+			names, err := c.BinaryEdgeSubdomains(ctx, domain, opts.MaxPages)
+			if !send(SourceResult{Source: SourceBinaryEdge, Names: names, Err: err}) {
+				return
+			}
+			matches, err := c.binaryEdgeHosts(ctx, domain, opts.MaxPages)
+			send(SourceResult{Source: SourceBinaryEdge, Matches: matches, Err: err})
+		})
+	})
+	RegisterSourceCheck("binaryedge", func(ctx context.Context, c *Client) (string, error) {
+		return c.binaryEdgeQuota(ctx)
+	})
+}
+
+// DefaultBinaryEdgeURL is the BinaryEdge API v2 endpoint.
+const DefaultBinaryEdgeURL = "https://api.binaryedge.io/v2"
+
+// SourceBinaryEdge is the source recorded for subdomains from BinaryEdge.
+const SourceBinaryEdge = "binaryedge"
+
+// BinaryEdgeMaxHosts caps the host lookups of an enumeration; every
+// lookup costs a BinaryEdge credit.
+var BinaryEdgeMaxHosts = 50
+
+// HasBinaryEdge reports whether the client has a BinaryEdge API key
+func (c *Client) HasBinaryEdge() bool {
+	return c.BinaryEdgeKey != ""
+}
+
+// BinaryEdgeSubdomains returns the subdomains BinaryEdge knows for domain,
+// fetching up to maxPages pages (zero or less fetches all).
+func (c *Client) BinaryEdgeSubdomains(ctx context.Context, domain string, maxPages int) ([]string, error) {
+	var names []string
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		var resp struct {
+			PageSize int      `json:"pagesize"`
+			Total    int      `json:"total"`
+			Events   []string `json:"events"`
+		}
+		path := "/query/domains/subdomain/" + url.PathEscape(domain)
+		if err := c.binaryEdgeGet(ctx, path, page, &resp); err != nil {
+			return names, err
+		}
+		names = append(names, resp.Events...)
+		if len(resp.Events) == 0 || page*resp.PageSize >= resp.Total {
+			break
+		}
+	}
+	return names, nil
+}
+
+// BinaryEdgeAddresses returns the addresses of domain and its subdomains
+// in BinaryEdge's DNS data, mapped to the names pointing at them.
+func (c *Client) BinaryEdgeAddresses(ctx context.Context, domain string, maxPages int) (map[string][]string, error) {
+	hosts := map[string][]string{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		var resp struct {
+			PageSize int `json:"pagesize"`
+			Total    int `json:"total"`
+			Events   []struct {
+				Domain string   `json:"domain"`
+				A      []string `json:"A"`
+				AAAA   []string `json:"AAAA"`
+			} `json:"events"`
+		}
+		path := "/query/domains/dns/" + url.PathEscape(domain)
+		if err := c.binaryEdgeGet(ctx, path, page, &resp); err != nil {
+			return hosts, err
+		}
+		for _, e := range resp.Events {
+			name := NormalizeHostname(e.Domain)
+			for _, ip := range append(e.A, e.AAAA...) {
+				if !contains(hosts[ip], name) {
+					hosts[ip] = append(hosts[ip], name)
+				}
+			}
+		}
+		if len(resp.Events) == 0 || page*resp.PageSize >= resp.Total {
+			break
+		}
+	}
+	return hosts, nil
+}
+
+// BinaryEdgeHost returns the services BinaryEdge has seen on ip as
+// Shodan-style Matches carrying hostnames, so they go through the same
+// result pipeline.
+func (c *Client) BinaryEdgeHost(ctx context.Context, ip string, hostnames []string) ([]Match, error) {
+	var resp struct {
+		Events []struct {
+			Port    int `json:"port"`
+			Results []struct {
+				Target struct {
+					IP       string `json:"ip"`
+					Port     int    `json:"port"`
+					Protocol string `json:"protocol"`
+				} `json:"target"`
+				Result struct {
+					Data struct {
+						Service struct {
+							Name    string `json:"name"`
+							Product string `json:"product"`
+							Version string `json:"version"`
+						} `json:"service"`
+					} `json:"data"`
+				} `json:"result"`
+			} `json:"results"`
+		} `json:"events"`
+	}
+	if err := c.binaryEdgeGet(ctx, "/query/ip/"+url.PathEscape(ip), 0, &resp); err != nil {
+		return nil, err
+	}
+
+	// Every scan module reports the port; keep the first that names the service
+	seen := map[string]bool{}
+	var matches []Match
+	for _, e := range resp.Events {
+		for _, r := range e.Results {
+			svc := r.Result.Data.Service
+			key := r.Target.Protocol + "/" + strconv.Itoa(r.Target.Port)
+			if svc.Name == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			matches = append(matches, Match{
+				IPStr:     ip,
+				Port:      r.Target.Port,
+				Transport: r.Target.Protocol,
+				Hostnames: hostnames,
+				Product:   svc.Product,
+				Version:   svc.Version,
+				Shodan:    ShodanMeta{Module: svc.Name},
+			})
+		}
+	}
+	return matches, nil
+}
+
+// binaryEdgeHosts looks up the services of up to BinaryEdgeMaxHosts of the
+// addresses BinaryEdge knows for domain
+func (c *Client) binaryEdgeHosts(ctx context.Context, domain string, maxPages int) ([]Match, error) {
+	hosts, err := c.BinaryEdgeAddresses(ctx, domain, maxPages)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]string, 0, len(hosts))
+	for ip := range hosts {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	if len(ips) > BinaryEdgeMaxHosts {
+		c.logf("[!] BinaryEdge knows %d addresses, looking up the first %d", len(ips), BinaryEdgeMaxHosts)
+		ips = ips[:BinaryEdgeMaxHosts]
+	}
+
+	// The first failed lookup stops the others
+	hostCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var mu sync.Mutex
+	var matches []Match
+	var firstErr error
+	forEach(hostCtx, ips, c.sourceConcurrency(SourceBinaryEdge), func(ip string) {
+		m, err := c.BinaryEdgeHost(hostCtx, ip, hosts[ip])
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+			return
+		}
+		matches = append(matches, m...)
+	})
+	return matches, firstErr
+}
+
+// Fetch a BinaryEdge API path with the client's key; page 0 is not sent
+func (c *Client) binaryEdgeGet(ctx context.Context, path string, page int, v interface{}) error {
+	if !c.HasBinaryEdge() {
+		return errors.New("binaryedge API key is not set")
+	}
+	base := c.BinaryEdgeURL
+	if base == "" {
+		base = DefaultBinaryEdgeURL
+	}
+	u := strings.TrimRight(base, "/") + path
+	if page > 0 {
+		u += "?page=" + strconv.Itoa(page)
+	}
+	return sourceError("binaryedge", c.call(ctx, request{
+		source: SourceBinaryEdge,
+		method: http.MethodGet,
+		url:    u,
+		header: http.Header{"X-Key": {c.BinaryEdgeKey}},
+	}, v))
+}
+
+// binaryEdgeQuota returns the BinaryEdge requests left on the plan
+func (c *Client) binaryEdgeQuota(ctx context.Context) (string, error) {
+	if !c.HasBinaryEdge() {
+		return "", ErrNotConfigured
+	}
+	var resp struct {
+		RequestsLeft int `json:"requests_left"`
+		RequestsPlan int `json:"requests_plan"`
+	}
+	if err := c.binaryEdgeGet(ctx, "/user/subscription", 0, &resp); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d of %d requests left", resp.RequestsLeft, resp.RequestsPlan), nil
+}
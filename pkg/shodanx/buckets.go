@@ -0,0 +1,165 @@
+package shodanx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Bucket is a cloud storage bucket a hostname points at.
+type Bucket struct {
+	// Host is the hostname that revealed the bucket, directly or by its CNAME
+	Host     string `json:"host"`
+	CNAME    string `json:"cname,omitempty"`
+	Provider string `json:"provider"` // S3, GCS or Azure
+	Name     string `json:"name"`     // bucket, or storage account for Azure
+
+	// Access is BucketListable, BucketPrivate or BucketMissing once checked
+	Access string `json:"access,omitempty"`
+}
+
+// Bucket access levels found by CheckBuckets
+const (
+	BucketListable = "listable" // anyone can list the objects
+	BucketPrivate  = "private"
+	BucketMissing  = "missing" // the name is unclaimed and can be taken over
+)
+
+// DetectBucket returns the bucket behind a hostname or its CNAME, or nil.
+// Endpoints without a bucket label, like a CNAME to s3-website-us-east-1.amazonaws.com
+// or c.storage.googleapis.com, serve the bucket named after the hostname.
+func DetectBucket(host, cname string) *Bucket {
+	for _, target := range []string{host, cname} {
+		if target == "" {
+			continue
+		}
+		provider, name, ok := bucketEndpoint(strings.ToLower(strings.TrimSuffix(target, ".")))
+		if !ok {
+			continue
+		}
+		if name == "" {
+			name = host
+		}
+		b := &Bucket{Host: host, Provider: provider, Name: name}
+		if target == cname {
+			b.CNAME = cname
+		}
+		return b
+	}
+	return nil
+}
+
+// bucketEndpoint splits a storage endpoint into provider and bucket name
+func bucketEndpoint(name string) (provider, bucket string, ok bool) {
+	switch {
+	case strings.HasSuffix(name, ".blob.core.windows.net"):
+		return "Azure", strings.SplitN(name, ".", 2)[0], true
+	case name == "storage.googleapis.com" || name == "c.storage.googleapis.com":
+		return "GCS", "", true
+	case strings.HasSuffix(name, ".storage.googleapis.com"):
+		return "GCS", strings.TrimSuffix(name, ".storage.googleapis.com"), true
+	}
+
+	// S3 has global, regional, website and China endpoints
+	aws := strings.TrimSuffix(name, ".cn")
+	if !strings.HasSuffix(aws, ".amazonaws.com") {
+		return "", "", false
+	}
+	labels := strings.Split(strings.TrimSuffix(aws, ".amazonaws.com"), ".")
+	for i, l := range labels {
+		if l == "s3" || strings.HasPrefix(l, "s3-") {
+			return "S3", strings.Join(labels[:i], "."), true
+		}
+	}
+	return "", "", false
+}
+
+// FindBuckets returns the buckets behind the subdomains, judged by their
+// CNAMEs, and behind the related names
+func (r *Result) FindBuckets() []Bucket {
+	var buckets []Bucket
+	seen := map[string]bool{}
+	add := func(host, cname string) {
+		if b := DetectBucket(host, cname); b != nil && !seen[b.Provider+"/"+b.Name] {
+			seen[b.Provider+"/"+b.Name] = true
+			buckets = append(buckets, *b)
+		}
+	}
+	for _, name := range r.Subdomains {
+		add(name, r.CNAMEs[name])
+	}
+	for _, name := range r.Related {
+		add(name, "")
+	}
+	return buckets
+}
+
+// CheckBuckets requests the object listing of every bucket anonymously and
+// records whether it is listable, private or missing. Buckets that can't be
+// reached keep an empty Access.
+func CheckBuckets(ctx context.Context, hc *http.Client, buckets []Bucket, workers int) {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	// Every worker writes its own element, so no locking is needed
+	idx := make([]string, len(buckets))
+	for i := range buckets {
+		idx[i] = strconv.Itoa(i)
+	}
+	forEach(ctx, idx, workers, func(s string) {
+		i, _ := strconv.Atoi(s)
+		buckets[i].Access = bucketAccess(ctx, hc, &buckets[i])
+	})
+}
+
+// bucketAccess lists a bucket and interprets the answer
+func bucketAccess(ctx context.Context, hc *http.Client, b *Bucket) string {
+	var listURL string
+	switch b.Provider {
+	case "S3":
+		// Virtual-hosted requests are redirected to the bucket's region, but
+		// names with dots don't match the certificate and need path style
+		listURL = "https://" + b.Name + ".s3.amazonaws.com/"
+		if strings.Contains(b.Name, ".") {
+			listURL = "https://s3.amazonaws.com/" + url.PathEscape(b.Name) + "/"
+		}
+	case "GCS":
+		listURL = "https://storage.googleapis.com/" + url.PathEscape(b.Name) + "/"
+	case "Azure":
+		// Only the root container can be listed without knowing its name
+		listURL = "https://" + b.Name + ".blob.core.windows.net/$root?restype=container&comp=list"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := hc.Do(req)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound && b.Provider == "Azure" {
+		// Every storage account has a DNS name, so it doesn't exist
+		return BucketMissing
+	}
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusOK && (bytes.Contains(body, []byte("<ListBucketResult")) || bytes.Contains(body, []byte("<EnumerationResults"))):
+		return BucketListable
+	case bytes.Contains(body, []byte("NoSuchBucket")):
+		return BucketMissing
+	case resp.StatusCode >= 300 && resp.StatusCode < 500:
+		// Denied, redirected to the bucket's region, or for Azure an account
+		// without a public root container
+		return BucketPrivate
+	}
+	return ""
+}
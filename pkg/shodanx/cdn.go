@@ -0,0 +1,112 @@
+package shodanx
+
+import (
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+// CDNRanges are the published address ranges of the CDNs that are detected.
+// They are a snapshot; CNAMEs pointing at a CDN (see CDNCNAMEs) catch the
+// addresses they miss.
+var CDNRanges = map[string][]string{
+	"Cloudflare": {
+		"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+		"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+		"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+		"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+		"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+		"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+	},
+	"Akamai": {
+		"2.16.0.0/13", "23.0.0.0/12", "23.32.0.0/11", "23.64.0.0/14", "23.72.0.0/13",
+		"72.246.0.0/15", "88.221.0.0/16", "92.122.0.0/15", "95.100.0.0/15",
+		"96.6.0.0/15", "96.16.0.0/15", "104.64.0.0/10", "184.24.0.0/13",
+		"184.50.0.0/15", "184.84.0.0/14",
+		"2600:1400::/24", "2a02:26f0::/29",
+	},
+	"Fastly": {
+		"23.235.32.0/20", "43.249.72.0/22", "103.244.50.0/24", "103.245.222.0/23",
+		"103.245.224.0/24", "104.156.80.0/20", "140.248.64.0/18", "140.248.128.0/17",
+		"146.75.0.0/17", "151.101.0.0/16", "157.52.64.0/18", "167.82.0.0/17",
+		"167.82.128.0/20", "167.82.160.0/20", "167.82.224.0/20", "172.111.64.0/18",
+		"185.31.16.0/22", "199.27.72.0/21", "199.232.0.0/16",
+		"2a04:4e40::/32", "2a04:4e42::/32",
+	},
+	"CloudFront": {
+		"13.32.0.0/15", "13.35.0.0/16", "13.224.0.0/14", "13.249.0.0/16",
+		"18.64.0.0/14", "18.154.0.0/15", "18.160.0.0/15", "18.164.0.0/15",
+		"18.172.0.0/15", "52.84.0.0/15", "54.182.0.0/16", "54.192.0.0/16",
+		"54.230.0.0/16", "54.239.128.0/18", "99.84.0.0/16", "99.86.0.0/16",
+		"108.156.0.0/14", "143.204.0.0/16", "204.246.164.0/22", "205.251.192.0/19",
+		"2600:9000::/28",
+	},
+}
+
+// CDNCNAMEs maps CNAME suffixes to the CDN that serves them
+var CDNCNAMEs = map[string]string{
+	"cdn.cloudflare.net": "Cloudflare",
+	"akamai.net":         "Akamai",
+	"akamaiedge.net":     "Akamai",
+	"akamaihd.net":       "Akamai",
+	"edgekey.net":        "Akamai",
+	"edgesuite.net":      "Akamai",
+	"fastly.net":         "Fastly",
+	"fastlylb.net":       "Fastly",
+	"cloudfront.net":     "CloudFront",
+}
+
+var (
+	cdnOnce     sync.Once
+	cdnPrefixes map[netip.Prefix]string
+)
+
+// CDNForIP returns the CDN whose ranges contain ip, or "" if none does
+func CDNForIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	cdnOnce.Do(func() {
+		cdnPrefixes = map[netip.Prefix]string{}
+		for cdn, ranges := range CDNRanges {
+			for _, r := range ranges {
+				if p, err := netip.ParsePrefix(r); err == nil {
+					cdnPrefixes[p] = cdn
+				}
+			}
+		}
+	})
+	addr = addr.Unmap()
+	for p, cdn := range cdnPrefixes {
+		if p.Contains(addr) {
+			return cdn
+		}
+	}
+	return ""
+}
+
+// CDNForCNAME returns the CDN a canonical name points at, or "" if none
+func CDNForCNAME(cname string) string {
+	cname = strings.TrimSuffix(strings.ToLower(cname), ".")
+	for suffix, cdn := range CDNCNAMEs {
+		if cname == suffix || strings.HasSuffix(cname, "."+suffix) {
+			return cdn
+		}
+	}
+	return ""
+}
+
+// DetectCDN returns the CDN in front of a resolved hostname, judged by its
+// CNAME first and then its addresses, or "" if it isn't CDN-fronted
+func DetectCDN(res *Resolution) string {
+	if cdn := CDNForCNAME(res.CNAME); cdn != "" {
+		return cdn
+	}
+	for _, ip := range res.Addresses {
+		if cdn := CDNForIP(ip); cdn != "" {
+			return cdn
+		}
+	}
+	return ""
+}
@@ -0,0 +1,267 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterSource("censys", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("censys", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			forEach(ctx, CensysHostQueries(domain), c.sourceConcurrency(SourceCensys), func(q string) {
+				c.logf("[*] Censys query: %s", q)
+				r := SourceResult{Source: SourceCensys + ":" + q}
+				res, err := c.CensysSearch(ctx, q, opts.MaxPages)
+				if res != nil {
+					r.Matches = res.Matches
+				}
+				r.Err = err
+				send(r)
+			})
+			c.logf("[*] Censys certificate search: %s", domain)
+			names, err := c.CensysCertNames(ctx, domain, opts.MaxPages)
+			send(SourceResult{Source: SourceCensysCerts, Names: names, Err: err})
+		})
+	})
+	RegisterSourceCheck("censys", func(ctx context.Context, c *Client) (string, error) {
+		return c.censysQuota(ctx)
+	})
+}
+
+// DefaultCensysURL is the Censys Search API v2 endpoint.
+const DefaultCensysURL = "https://search.censys.io/api/v2"
+
+// DefaultCensysRate is the Censys request rate used by NewClient, the
+// limit of free accounts.
+const DefaultCensysRate = 0.4
+
+// CensysPageSize is the number of hits per Censys search page.
+const CensysPageSize = 100
+
+// Sources recorded for subdomains from Censys; host searches record the
+// query prefixed with SourceCensys, e.g. "censys:dns.names: example.com"
+const (
+	SourceCensys      = "censys"
+	SourceCensysCerts = "censys:certificates"
+)
+
+// censysResponse is the envelope of every Censys search response; hits
+// are decoded by the caller
+type censysResponse struct {
+	Result struct {
+		Total int             `json:"total"`
+		Hits  json.RawMessage `json:"hits"`
+		Links struct {
+			Next string `json:"next"`
+		} `json:"links"`
+	} `json:"result"`
+}
+
+// censysCert is a hit of /certificates/search
+type censysCert struct {
+	Names []string `json:"names"`
+}
+
+// censysHost is a hit of /hosts/search
+type censysHost struct {
+	IP       string `json:"ip"`
+	Services []struct {
+		Port                int    `json:"port"`
+		ServiceName         string `json:"service_name"`
+		ExtendedServiceName string `json:"extended_service_name"`
+		TransportProtocol   string `json:"transport_protocol"`
+	} `json:"services"`
+	Location struct {
+		City        string `json:"city"`
+		Country     string `json:"country"`
+		CountryCode string `json:"country_code"`
+		Coordinates struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"coordinates"`
+	} `json:"location"`
+	AutonomousSystem struct {
+		ASN  int    `json:"asn"`
+		Name string `json:"name"`
+	} `json:"autonomous_system"`
+	DNS struct {
+		Names      []string `json:"names"`
+		ReverseDNS struct {
+			Names []string `json:"names"`
+		} `json:"reverse_dns"`
+	} `json:"dns"`
+}
+
+// CensysHostQueries returns the Censys host searches run for domain.
+func CensysHostQueries(domain string) []string {
+	return []string{
+		"dns.names: " + domain,
+		"services.tls.certificates.leaf_data.names: " + domain,
+	}
+}
+
+// HasCensys reports whether the client has Censys credentials
+func (c *Client) HasCensys() bool {
+	return c.CensysID != "" && c.CensysSecret != ""
+}
+
+// CensysSearch runs a Censys host search, fetching up to maxPages pages
+// (zero or less fetches all), and returns every service of the matched hosts
+// as a Shodan-style Match, so they go through the same result pipeline. Like
+// SearchAll, the matches collected so far are returned with a later error.
+func (c *Client) CensysSearch(ctx context.Context, query string, maxPages int) (*SearchResult, error) {
+	all := &SearchResult{}
+	cursor := ""
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		var resp censysResponse
+		var hits []censysHost
+		params := url.Values{"q": {query}, "per_page": {strconv.Itoa(CensysPageSize)}}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		err := c.censysGet(ctx, "/hosts/search", params, &resp, &hits)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			return all, err
+		}
+		if page == 1 {
+			all.Total = resp.Result.Total
+		}
+		for _, h := range hits {
+			all.Matches = append(all.Matches, h.matches()...)
+		}
+		if cursor = resp.Result.Links.Next; cursor == "" {
+			break
+		}
+	}
+	return all, nil
+}
+
+// CensysCertNames returns the names of domain and its subdomains found in
+// the certificates Censys has collected, fetching up to maxPages pages.
+func (c *Client) CensysCertNames(ctx context.Context, domain string, maxPages int) ([]string, error) {
+	var names []string
+	cursor := ""
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		var resp censysResponse
+		var hits []censysCert
+		params := url.Values{"q": {"names: " + domain}, "per_page": {strconv.Itoa(CensysPageSize)}}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		if err := c.censysGet(ctx, "/certificates/search", params, &resp, &hits); err != nil {
+			return Unique(names), err
+		}
+		for _, hit := range hits {
+			for _, name := range hit.Names {
+				if name = NormalizeHostname(name); InDomain(name, domain) {
+					names = append(names, name)
+				}
+			}
+		}
+		if cursor = resp.Result.Links.Next; cursor == "" {
+			break
+		}
+	}
+	return Unique(names), nil
+}
+
+// Fetch a Censys search page with the client's credentials, decoding the
+// response into resp and its hits into hits
+func (c *Client) censysGet(ctx context.Context, path string, params url.Values, resp *censysResponse, hits interface{}) error {
+	if !c.HasCensys() {
+		return errors.New("censys credentials are not set")
+	}
+	base := c.CensysURL
+	if base == "" {
+		base = DefaultCensysURL
+	}
+	err := sourceError("censys", c.call(ctx, request{
+		source:   SourceCensys,
+		method:   http.MethodGet,
+		url:      strings.TrimRight(base, "/") + path + "?" + params.Encode(),
+		limiter:  c.CensysLimiter,
+		user:     c.CensysID,
+		password: c.CensysSecret,
+	}, resp))
+	if err != nil || len(resp.Result.Hits) == 0 {
+		return err
+	}
+	if err := json.Unmarshal(resp.Result.Hits, hits); err != nil {
+		return fmt.Errorf("failed to parse Censys hits: %w", err)
+	}
+	return nil
+}
+
+// matches converts every service of a Censys host to a Match
+func (h *censysHost) matches() []Match {
+	hostnames := Unique(append(append([]string{}, h.DNS.Names...), h.DNS.ReverseDNS.Names...))
+	asn := ""
+	if h.AutonomousSystem.ASN != 0 {
+		asn = "AS" + strconv.Itoa(h.AutonomousSystem.ASN)
+	}
+	loc := Location{
+		City:        h.Location.City,
+		CountryCode: h.Location.CountryCode,
+		CountryName: h.Location.Country,
+		Latitude:    h.Location.Coordinates.Latitude,
+		Longitude:   h.Location.Coordinates.Longitude,
+	}
+
+	matches := make([]Match, 0, len(h.Services))
+	for _, s := range h.Services {
+		module := s.ExtendedServiceName
+		if module == "" || module == "UNKNOWN" {
+			module = s.ServiceName
+		}
+		if module == "UNKNOWN" {
+			module = ""
+		}
+		matches = append(matches, Match{
+			IPStr:     h.IP,
+			Port:      s.Port,
+			Transport: strings.ToLower(s.TransportProtocol),
+			Hostnames: hostnames,
+			Org:       h.AutonomousSystem.Name,
+			ASN:       asn,
+			Location:  loc,
+			Shodan:    ShodanMeta{Module: strings.ToLower(module)},
+		})
+	}
+	return matches
+}
+
+// censysQuota returns the Censys queries left this month
+func (c *Client) censysQuota(ctx context.Context) (string, error) {
+	if !c.HasCensys() {
+		return "", ErrNotConfigured
+	}
+	// The account endpoint is only part of the v1 API
+	base := strings.TrimSuffix(strings.TrimRight(urlOr(c.CensysURL, DefaultCensysURL), "/"), "/v2")
+	var resp struct {
+		Quota struct {
+			Used      int `json:"used"`
+			Allowance int `json:"allowance"`
+		} `json:"quota"`
+	}
+	err := sourceError("censys", c.call(ctx, request{
+		source:   SourceCensys,
+		method:   http.MethodGet,
+		url:      base + "/v1/account",
+		limiter:  c.CensysLimiter,
+		user:     c.CensysID,
+		password: c.CensysSecret,
+	}, &resp))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d of %d queries left this month", resp.Quota.Allowance-resp.Quota.Used, resp.Quota.Allowance), nil
+}
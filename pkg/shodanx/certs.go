@@ -0,0 +1,112 @@
+package shodanx
+
+import (
+	"bytes"
+	"crypto/x509"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CertFinding is a TLS service whose certificate is expired or self-signed.
+type CertFinding struct {
+	IP         string   `json:"ip,omitempty"`
+	Port       int      `json:"port"`
+	Hostnames  []string `json:"hostnames,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	Issuer     string   `json:"issuer,omitempty"`
+	Expires    string   `json:"expires,omitempty"` // YYYY-MM-DD
+	Expired    bool     `json:"expired,omitempty"`
+	SelfSigned bool     `json:"self_signed,omitempty"`
+}
+
+// shodanTime is the layout of certificate dates in Shodan banners
+const shodanTime = "20060102150405Z"
+
+// bannerCertFinding checks the certificate of a banner, returning nil if it
+// has none or it is neither expired nor self-signed
+func bannerCertFinding(m *Match, now time.Time) *CertFinding {
+	if m.SSL == nil {
+		return nil
+	}
+	cert := &m.SSL.Cert
+	f := &CertFinding{
+		IP:         m.IPStr,
+		Port:       m.Port,
+		Hostnames:  m.Hostnames,
+		Subject:    cert.Subject["CN"],
+		Issuer:     cert.Issuer["CN"],
+		Expired:    cert.Expired,
+		SelfSigned: len(cert.Subject) > 0 && sameName(cert.Subject, cert.Issuer),
+	}
+	if t, err := time.Parse(shodanTime, cert.Expires); err == nil {
+		f.Expires = t.Format("2006-01-02")
+		// Shodan's flag is from the time of the scan
+		f.Expired = f.Expired || t.Before(now)
+	}
+	if !f.Expired && !f.SelfSigned {
+		return nil
+	}
+	return f
+}
+
+// probeCertFinding checks the leaf certificate a probed server presented
+func probeCertFinding(cert *x509.Certificate, now time.Time) *CertFinding {
+	f := &CertFinding{
+		Subject:    cert.Subject.CommonName,
+		Issuer:     cert.Issuer.CommonName,
+		Expires:    cert.NotAfter.Format("2006-01-02"),
+		Expired:    now.After(cert.NotAfter),
+		SelfSigned: bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil,
+	}
+	if !f.Expired && !f.SelfSigned {
+		return nil
+	}
+	return f
+}
+
+// CertFindings returns the certificate findings of the matched services
+// followed by those of probed hosts
+func (r *Result) CertFindings() []CertFinding {
+	findings := append([]CertFinding(nil), r.Certificates...)
+	names := make([]string, 0, len(r.Probes))
+	for name := range r.Probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c := r.Probes[name].Cert; c != nil {
+			findings = append(findings, *c)
+		}
+	}
+	return findings
+}
+
+func sameName(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Names in the text form of a certificate, as FOFA and ZoomEye return it
+var certTextName = regexp.MustCompile(`(?:DNS:|CommonName: |CN=)\s*([*A-Za-z0-9._-]+\.[A-Za-z0-9-]+)`)
+
+// addCertText sets the subject of m from the text form of its certificate
+// and adds the names it covers to Hostnames
+func (m *Match) addCertText(text string) {
+	subject := map[string]string{}
+	for _, sm := range certTextName.FindAllStringSubmatch(text, -1) {
+		if subject["CN"] == "" && !strings.HasPrefix(sm[0], "DNS:") {
+			subject["CN"] = sm[1]
+		}
+		m.Hostnames = append(m.Hostnames, NormalizeHostname(sm[1]))
+	}
+	m.SSL = &SSL{Cert: Cert{Subject: subject}}
+}
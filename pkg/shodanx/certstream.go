@@ -0,0 +1,141 @@
+package shodanx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// DefaultCertstreamURL is the public certstream feed of newly logged
+// certificates.
+const DefaultCertstreamURL = "wss://certstream.calidog.io/"
+
+// SourceCertstream is the source recorded for subdomains from certstream.
+const SourceCertstream = "certstream"
+
+// CTEntry is a certificate logged to certificate transparency, as received
+// from certstream.
+type CTEntry struct {
+	Domains   []string  `json:"domains"`
+	Subject   string    `json:"subject,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	Log       string    `json:"log,omitempty"`
+	Seen      time.Time `json:"seen"`
+}
+
+// certstreamMessage is a certstream message; heartbeats have no data
+type certstreamMessage struct {
+	MessageType string `json:"message_type"`
+	Data        struct {
+		LeafCert struct {
+			Subject    map[string]interface{} `json:"subject"`
+			Issuer     map[string]interface{} `json:"issuer"`
+			NotBefore  float64                `json:"not_before"`
+			NotAfter   float64                `json:"not_after"`
+			AllDomains []string               `json:"all_domains"`
+		} `json:"leaf_cert"`
+		Seen   float64 `json:"seen"`
+		Source struct {
+			Name string `json:"name"`
+		} `json:"source"`
+	} `json:"data"`
+}
+
+// CertStream connects to the certstream feed and calls handle for every newly
+// logged certificate until ctx is cancelled, the connection drops or handle
+// returns an error. It needs no API key and costs no credits.
+func (c *Client) CertStream(ctx context.Context, handle func(*CTEntry) error) error {
+	endpoint := c.CertstreamURL
+	if endpoint == "" {
+		endpoint = DefaultCertstreamURL
+	}
+	config, err := websocket.NewConfig(endpoint, "https://github.com/moatasem121/shodanX")
+	if err != nil {
+		return fmt.Errorf("invalid certstream URL: %w", err)
+	}
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return fmt.Errorf("certstream connection failed: %w", err)
+	}
+	defer ws.Close()
+
+	// Closing the connection unblocks the read when ctx is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var msg certstreamMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("certstream interrupted: %w", err)
+		}
+		if msg.MessageType != "certificate_update" {
+			continue
+		}
+		cert := &msg.Data.LeafCert
+		entry := &CTEntry{
+			Subject:   certName(cert.Subject),
+			Issuer:    certName(cert.Issuer),
+			NotBefore: unixTime(cert.NotBefore),
+			NotAfter:  unixTime(cert.NotAfter),
+			Log:       msg.Data.Source.Name,
+			Seen:      unixTime(msg.Data.Seen),
+		}
+		for _, name := range cert.AllDomains {
+			if name = NormalizeHostname(name); name != "" {
+				entry.Domains = append(entry.Domains, name)
+			}
+		}
+		entry.Domains = Unique(entry.Domains)
+		if err := handle(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// Matching returns the names of the entry that are one of domains or their
+// subdomains
+func (e *CTEntry) Matching(domains []string) []string {
+	var names []string
+	for _, name := range e.Domains {
+		for _, d := range domains {
+			if InDomain(name, d) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// certName returns the common name of a certstream subject or issuer,
+// falling back to the organisation
+func certName(name map[string]interface{}) string {
+	for _, key := range []string{"CN", "O"} {
+		if s, ok := name[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// unixTime converts fractional Unix seconds to a UTC time
+func unixTime(sec float64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(sec*float64(time.Second))).UTC()
+}
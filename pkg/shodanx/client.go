@@ -0,0 +1,480 @@
+// Package shodanx implements subdomain discovery on top of the Shodan API so
+// that other Go tools can embed it instead of shelling out to the binary.
+package shodanx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Shodan REST API endpoint used by NewClient.
+const DefaultBaseURL = "https://api.shodan.io"
+
+// SourceShodan is the SourceSettings key of the requests to the Shodan REST
+// API. Names its queries find record the query as their source instead.
+const SourceShodan = "shodan"
+
+// Client talks to the Shodan API.
+type Client struct {
+	APIKey     string
+	HTTPClient *http.Client
+	BaseURL    string
+
+	// StreamURL is the Streaming API endpoint; empty uses DefaultStreamURL
+	StreamURL string
+
+	// InternetDBURL is the InternetDB endpoint; empty uses DefaultInternetDBURL
+	InternetDBURL string
+
+	// CrtShURL is the crt.sh endpoint; empty uses DefaultCrtShURL
+	CrtShURL string
+
+	// CensysID and CensysSecret are the Censys Search API credentials
+	CensysID     string
+	CensysSecret string
+
+	// CensysURL is the Censys endpoint; empty uses DefaultCensysURL
+	CensysURL string
+
+	// SecurityTrailsKey is the SecurityTrails API key
+	SecurityTrailsKey string
+
+	// SecurityTrailsURL is the SecurityTrails endpoint; empty uses DefaultSecurityTrailsURL
+	SecurityTrailsURL string
+
+	// BinaryEdgeKey is the BinaryEdge API key
+	BinaryEdgeKey string
+
+	// BinaryEdgeURL is the BinaryEdge endpoint; empty uses DefaultBinaryEdgeURL
+	BinaryEdgeURL string
+
+	// FofaKey and FofaEmail are the FOFA API credentials; older accounts
+	// also need the email
+	FofaKey   string
+	FofaEmail string
+
+	// FofaURL is the FOFA endpoint; empty uses DefaultFofaURL
+	FofaURL string
+
+	// ZoomEyeKey is the ZoomEye API key
+	ZoomEyeKey string
+
+	// ZoomEyeURL is the ZoomEye endpoint; empty uses DefaultZoomEyeURL
+	ZoomEyeURL string
+
+	// HackerTargetURL and AnubisURL are the free subdomain sources; empty
+	// uses DefaultHackerTargetURL and DefaultAnubisURL
+	HackerTargetURL string
+	AnubisURL       string
+
+	// RapidDNSURL is the RapidDNS website; empty uses DefaultRapidDNSURL
+	RapidDNSURL string
+
+	// CertstreamURL is the certstream websocket feed; empty uses
+	// DefaultCertstreamURL
+	CertstreamURL string
+
+	// WaybackURL and URLScanURL are the archive endpoints; empty uses
+	// DefaultWaybackURL and DefaultURLScanURL
+	WaybackURL string
+	URLScanURL string
+
+	// URLScanKey is the optional urlscan.io API key
+	URLScanKey string
+
+	// KeepRaw keeps every search banner as Shodan returned it in Match.Raw,
+	// for exports of the whole banners; off, only the fields of Match are
+	// decoded
+	KeepRaw bool
+
+	// Logger receives progress and non-fatal error messages. Nil disables logging.
+	Logger *log.Logger
+
+	// Limiter throttles every API request. Nil disables rate limiting.
+	Limiter *RateLimiter
+
+	// InternetDBLimiter throttles InternetDB lookups, which are not subject to API limits
+	InternetDBLimiter *RateLimiter
+
+	// CensysLimiter throttles Censys requests, which have their own quota
+	CensysLimiter *RateLimiter
+
+	// RapidDNSLimiter keeps RapidDNS scraping polite
+	RapidDNSLimiter *RateLimiter
+
+	// SourceSettings overrides the rate limit, timeout, retries and
+	// concurrency of other sources, keyed by source name (see SourceNames,
+	// plus "wayback" and "urlscan" for the archives)
+	SourceSettings map[string]SourceSettings
+
+	// Retry controls retries of network errors and 429/5xx responses
+	Retry RetryPolicy
+
+	// MaxCredits caps the query credits this client may spend. Zero means no limit.
+	MaxCredits int
+
+	creditsUsed int64
+}
+
+// SourceSettings tunes the requests of one source
+type SourceSettings struct {
+	// Limiter throttles the requests in place of the source's default
+	Limiter *RateLimiter
+
+	// Timeout bounds each request; zero uses the HTTP client's timeout
+	Timeout time.Duration
+
+	// Retry replaces the client's Retry for the source when set
+	Retry *RetryPolicy
+
+	// Concurrency is the number of requests a source making several, such
+	// as the queries of search backends, runs in parallel. Zero means one.
+	Concurrency int
+}
+
+// NewClient returns a Client for the given API key using the default base URL.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+		BaseURL:    DefaultBaseURL,
+		Limiter:    NewRateLimiter(DefaultRateLimit, 1),
+		Retry:      DefaultRetryPolicy,
+
+		InternetDBLimiter: NewRateLimiter(DefaultInternetDBRate, 1),
+		CensysLimiter:     NewRateLimiter(DefaultCensysRate, 1),
+		RapidDNSLimiter:   NewRateLimiter(DefaultRapidDNSRate, 1),
+	}
+}
+
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, args...)
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimRight(c.BaseURL, "/")
+	}
+	return DefaultBaseURL
+}
+
+// Build the request URL for an API path, adding the API key to params
+func (c *Client) endpoint(path string, params url.Values) string {
+	q := url.Values{}
+	for k, v := range params {
+		q[k] = v
+	}
+	q.Set("key", c.APIKey)
+	return c.baseURL() + path + "?" + q.Encode()
+}
+
+// request describes a single HTTP call made by the client
+type request struct {
+	method      string
+	url         string
+	contentType string
+	body        []byte
+	limiter     *RateLimiter
+
+	// source names the source for SourceSettings
+	source string
+
+	// user and password are sent as basic auth when set
+	user, password string
+
+	// header holds extra request headers, e.g. API keys
+	header http.Header
+}
+
+// Fetch an API path and decode the JSON body into v
+func (c *Client) getJSON(ctx context.Context, path string, params url.Values, v interface{}) error {
+	return c.call(ctx, request{source: SourceShodan, method: http.MethodGet, url: c.endpoint(path, params), limiter: c.Limiter}, v)
+}
+
+// POST form-encoded values to an API path and decode the JSON response into v
+func (c *Client) postForm(ctx context.Context, path string, form url.Values, v interface{}) error {
+	return c.call(ctx, request{
+		source:      SourceShodan,
+		method:      http.MethodPost,
+		url:         c.endpoint(path, nil),
+		contentType: "application/x-www-form-urlencoded",
+		body:        []byte(form.Encode()),
+		limiter:     c.Limiter,
+	}, v)
+}
+
+// POST a JSON payload to an API path and decode the JSON response into v
+func (c *Client) postJSON(ctx context.Context, path string, payload, v interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	return c.call(ctx, request{
+		source:      SourceShodan,
+		method:      http.MethodPost,
+		url:         c.endpoint(path, nil),
+		contentType: "application/json",
+		body:        body,
+		limiter:     c.Limiter,
+	}, v)
+}
+
+// Send a PUT request without a body to an API path
+func (c *Client) put(ctx context.Context, path string) error {
+	return c.call(ctx, request{source: SourceShodan, method: http.MethodPut, url: c.endpoint(path, nil), limiter: c.Limiter}, nil)
+}
+
+// Send a DELETE request to an API path
+func (c *Client) delete(ctx context.Context, path string) error {
+	return c.call(ctx, request{source: SourceShodan, method: http.MethodDelete, url: c.endpoint(path, nil), limiter: c.Limiter}, nil)
+}
+
+// Perform a request and decode the JSON response into v (if not nil),
+// retrying transient failures
+func (c *Client) call(ctx context.Context, req request, v interface{}) error {
+	body, err := c.callRaw(ctx, req)
+	if err != nil || v == nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return nil
+}
+
+// Perform a request and return the response body, retrying transient failures
+func (c *Client) callRaw(ctx context.Context, req request) ([]byte, error) {
+	settings := c.SourceSettings[req.source]
+	if settings.Limiter != nil {
+		req.limiter = settings.Limiter
+	}
+	retry := c.Retry
+	if settings.Retry != nil {
+		retry = *settings.Retry
+	}
+	for attempt := 0; ; attempt++ {
+		status, header, b, err := c.fetch(ctx, req, settings.Timeout)
+		if err == nil && !retryableStatus(status) {
+			if status >= 400 {
+				return nil, newAPIError(status, b)
+			}
+			return b, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			err = newAPIError(status, b)
+		}
+		if attempt >= retry.MaxRetries {
+			return nil, err
+		}
+
+		d := retry.delay(attempt+1, header)
+		c.logf("[!] %v, retrying in %s (%d/%d)", err, d.Round(time.Millisecond), attempt+1, retry.MaxRetries)
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Perform a single rate-limited request, bounded by timeout if set
+func (c *Client) fetch(ctx context.Context, r request, timeout time.Duration) (int, http.Header, []byte, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return 0, nil, nil, err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var bodyReader io.Reader
+	if r.body != nil {
+		bodyReader = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, bodyReader)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if r.user != "" {
+		req.SetBasicAuth(r.user, r.password)
+	}
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("request failed: %w", redactError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+// Keep the API key out of error messages
+func redactError(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		uerr.URL = redactKey(uerr.URL)
+	}
+	return err
+}
+
+// Replace the key parameter of a request URL
+func redactKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	if q.Has("key") {
+		q.Set("key", "REDACTED")
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// SearchPageSize is the number of matches Shodan returns per search page.
+const SearchPageSize = 100
+
+// Search runs a Shodan search query and returns the first page of matching banners
+func (c *Client) Search(ctx context.Context, query string) (*SearchResult, error) {
+	return c.SearchPage(ctx, query, 1)
+}
+
+// SearchPage returns a single page (starting at 1) of search results
+func (c *Client) SearchPage(ctx context.Context, query string, page int) (*SearchResult, error) {
+	// Filtered searches and every page after the first cost one query credit
+	if err := c.spendCredit(); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("page", strconv.Itoa(page))
+
+	var result SearchResult
+	if !c.KeepRaw {
+		if err := c.getJSON(ctx, "/shodan/host/search", params, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	var raw struct {
+		Matches []json.RawMessage       `json:"matches"`
+		Total   int                     `json:"total"`
+		Facets  map[string][]FacetValue `json:"facets"`
+	}
+	if err := c.getJSON(ctx, "/shodan/host/search", params, &raw); err != nil {
+		return nil, err
+	}
+	result.Total, result.Facets = raw.Total, raw.Facets
+	result.Matches = make([]Match, len(raw.Matches))
+	for i, data := range raw.Matches {
+		if err := json.Unmarshal(data, &result.Matches[i]); err != nil {
+			return nil, err
+		}
+		result.Matches[i].Raw = data
+	}
+	return &result, nil
+}
+
+// SearchAll follows the page parameter until every result reported in
+// Total has been fetched or maxPages pages were read (maxPages <= 0 means no
+// limit). Every page after the first costs a query credit. On error the
+// matches fetched so far are returned along with the error.
+func (c *Client) SearchAll(ctx context.Context, query string, maxPages int) (*SearchResult, error) {
+	all := &SearchResult{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		if page > 1 {
+			c.logf("[*] Query: %s (page %d/%d)", query, page, pageCount(all.Total))
+		}
+		res, err := c.SearchPage(ctx, query, page)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			return all, err
+		}
+		if page == 1 {
+			all.Total = res.Total
+			all.Facets = res.Facets
+		}
+		all.Matches = append(all.Matches, res.Matches...)
+
+		if len(res.Matches) < SearchPageSize || len(all.Matches) >= all.Total {
+			break
+		}
+	}
+	return all, nil
+}
+
+// DefaultFacets are the facets used to summarise a query's exposure.
+var DefaultFacets = []string{"port", "org", "country", "product"}
+
+// Count returns the total number of results for query and the top values of
+// each facet (e.g. "port", "org:20"). It does not return matches and does not
+// consume query credits.
+func (c *Client) Count(ctx context.Context, query string, facets []string) (*SearchResult, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	if len(facets) > 0 {
+		params.Set("facets", strings.Join(facets, ","))
+	}
+
+	var result SearchResult
+	if err := c.getJSON(ctx, "/shodan/host/count", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Number of pages needed to fetch total results
+func pageCount(total int) int {
+	return (total + SearchPageSize - 1) / SearchPageSize
+}
+
+// DNSDomain returns the subdomains and records Shodan knows for domain
+func (c *Client) DNSDomain(ctx context.Context, domain string) (*DNSDomainResult, error) {
+	if err := c.spendCredit(); err != nil {
+		return nil, err
+	}
+
+	var result DNSDomainResult
+	if err := c.getJSON(ctx, "/dns/domain/"+url.PathEscape(domain), nil, &result); err != nil {
+		return nil, fmt.Errorf("DNS API: %w", err)
+	}
+	if result.Domain == "" {
+		result.Domain = domain
+	}
+	return &result, nil
+}
@@ -0,0 +1,95 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testClient returns a client of the Shodan API served by handler
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := NewClient("testkey")
+	c.BaseURL = srv.URL
+	c.Limiter = nil
+	c.Retry = RetryPolicy{}
+	return c
+}
+
+func TestClientSearch(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/shodan/host/search" || q.Get("key") != "testkey" ||
+			q.Get("query") != `hostname:"example.com"` || q.Get("page") != "2" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"total": 101, "matches": [{"ip_str": "192.0.2.1", "port": 443,
+			"hostnames": ["www.example.com"], "_shodan": {"module": "https"}}]}`))
+	})
+	res, err := c.SearchPage(context.Background(), `hostname:"example.com"`, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 101 || len(res.Matches) != 1 {
+		t.Fatalf("got %d of %d matches, want 1 of 101", len(res.Matches), res.Total)
+	}
+	m := res.Matches[0]
+	if m.IPStr != "192.0.2.1" || m.Port != 443 || m.Shodan.Module != "https" ||
+		!reflect.DeepEqual(m.Hostnames, []string{"www.example.com"}) {
+		t.Errorf("unexpected match %+v", m)
+	}
+	if c.CreditsUsed() != 1 {
+		t.Errorf("CreditsUsed = %d, want 1", c.CreditsUsed())
+	}
+}
+
+func TestClientDNSDomain(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/domain/example.com" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"domain": "example.com", "subdomains": ["www", "mail"],
+			"data": [{"subdomain": "www", "type": "A", "value": "192.0.2.1"}]}`))
+	})
+	res, err := c.DNSDomain(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Hostnames(), []string{"www.example.com", "mail.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Hostnames = %v, want %v", got, want)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusUnauthorized, `{"error": "Invalid API key"}`, ErrUnauthorized},
+		{http.StatusForbidden, `{"error": "Access denied (403 Forbidden)"}`, nil},
+		{http.StatusPaymentRequired, `{"error": "Insufficient query credits"}`, ErrNoCredits},
+		{http.StatusTooManyRequests, `{"error": "Rate limit reached"}`, ErrRateLimited},
+	}
+	for _, tt := range tests {
+		c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		})
+		_, err := c.Search(context.Background(), "example.com")
+		var apiErr *APIError
+		switch {
+		case !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status:
+			t.Errorf("HTTP %d: got %v, want an APIError", tt.status, err)
+		case tt.want != nil && !errors.Is(err, tt.want):
+			t.Errorf("HTTP %d: got %v, want %v", tt.status, err, tt.want)
+		case tt.want == nil && IsFatal(err):
+			t.Errorf("HTTP %d: %v reported as fatal", tt.status, err)
+		}
+	}
+}
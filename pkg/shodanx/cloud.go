@@ -0,0 +1,269 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// CloudRange is a published address range of a cloud provider.
+type CloudRange struct {
+	Provider string `json:"provider"`
+	Region   string `json:"region,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Prefix   string `json:"prefix"`
+
+	prefix netip.Prefix
+}
+
+// CloudRanges finds the cloud provider and region of addresses.
+type CloudRanges struct {
+	ranges []CloudRange // most specific prefix first
+}
+
+// CloudRangeURLs are the published range lists downloaded by FetchCloudRanges.
+// Azure publishes its service tags under a new URL every week, so its file has
+// to be downloaded by hand and read with LoadAzureRanges.
+var CloudRangeURLs = map[string]string{
+	"AWS":          "https://ip-ranges.amazonaws.com/ip-ranges.json",
+	"GCP":          "https://www.gstatic.com/ipranges/cloud.json",
+	"DigitalOcean": "https://digitalocean.com/geo/google.csv",
+}
+
+// cloudParsers read the range list of each provider in CloudRangeURLs
+var cloudParsers = map[string]func(io.Reader) ([]CloudRange, error){
+	"AWS":          parseAWSRanges,
+	"GCP":          parseGCPRanges,
+	"DigitalOcean": parseDigitalOceanRanges,
+	"Azure":        parseAzureRanges,
+}
+
+// FetchCloudRanges downloads the range lists in CloudRangeURLs. A provider
+// whose list can't be fetched is skipped and reported in the error, so the
+// others can still be used.
+func FetchCloudRanges(ctx context.Context, hc *http.Client) (*CloudRanges, error) {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	cr := &CloudRanges{}
+	var failed []string
+	for provider, u := range CloudRangeURLs {
+		parse, ok := cloudParsers[provider]
+		if !ok {
+			failed = append(failed, provider+": unknown provider")
+			continue
+		}
+		ranges, err := fetchCloudRanges(ctx, hc, u, parse)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", provider, err))
+			continue
+		}
+		cr.add(ranges)
+	}
+	cr.sort()
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return cr, fmt.Errorf("failed to fetch cloud ranges (%s)", strings.Join(failed, "; "))
+	}
+	return cr, nil
+}
+
+// LoadAzureRanges adds the ranges of an Azure "Service Tags - Public" JSON file
+func (cr *CloudRanges) LoadAzureRanges(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ranges, err := parseAzureRanges(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	cr.add(ranges)
+	cr.sort()
+	return nil
+}
+
+// Lookup returns the most specific range containing ip, or nil
+func (cr *CloudRanges) Lookup(ip string) *CloudRange {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+	addr = addr.Unmap()
+	for i := range cr.ranges {
+		if cr.ranges[i].prefix.Contains(addr) {
+			return &cr.ranges[i]
+		}
+	}
+	return nil
+}
+
+// Annotate returns the range of every address that belongs to a cloud provider
+func (cr *CloudRanges) Annotate(ips []string) map[string]*CloudRange {
+	found := map[string]*CloudRange{}
+	for _, ip := range ips {
+		if r := cr.Lookup(ip); r != nil {
+			found[ip] = r
+		}
+	}
+	return found
+}
+
+// Len returns the number of known ranges
+func (cr *CloudRanges) Len() int {
+	return len(cr.ranges)
+}
+
+func (cr *CloudRanges) add(ranges []CloudRange) {
+	for _, r := range ranges {
+		p, err := netip.ParsePrefix(r.Prefix)
+		if err != nil {
+			continue
+		}
+		r.prefix = p.Masked()
+		cr.ranges = append(cr.ranges, r)
+	}
+}
+
+// Order ranges so the first match is the most specific one
+func (cr *CloudRanges) sort() {
+	sort.SliceStable(cr.ranges, func(i, j int) bool {
+		return cr.ranges[i].prefix.Bits() > cr.ranges[j].prefix.Bits()
+	})
+}
+
+func fetchCloudRanges(ctx context.Context, hc *http.Client, u string, parse func(io.Reader) ([]CloudRange, error)) ([]CloudRange, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return parse(resp.Body)
+}
+
+func parseAWSRanges(r io.Reader) ([]CloudRange, error) {
+	var doc struct {
+		Prefixes []struct {
+			Prefix  string `json:"ip_prefix"`
+			Region  string `json:"region"`
+			Service string `json:"service"`
+		} `json:"prefixes"`
+		IPv6Prefixes []struct {
+			Prefix  string `json:"ipv6_prefix"`
+			Region  string `json:"region"`
+			Service string `json:"service"`
+		} `json:"ipv6_prefixes"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	// Every prefix is listed under AMAZON as well as its actual service
+	byPrefix := map[string]CloudRange{}
+	add := func(prefix, region, service string) {
+		if old, ok := byPrefix[prefix]; ok && service == "AMAZON" && old.Service != "" {
+			return
+		}
+		byPrefix[prefix] = CloudRange{Provider: "AWS", Region: region, Service: service, Prefix: prefix}
+	}
+	for _, p := range doc.Prefixes {
+		add(p.Prefix, p.Region, p.Service)
+	}
+	for _, p := range doc.IPv6Prefixes {
+		add(p.Prefix, p.Region, p.Service)
+	}
+	ranges := make([]CloudRange, 0, len(byPrefix))
+	for _, r := range byPrefix {
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func parseGCPRanges(r io.Reader) ([]CloudRange, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPv4    string `json:"ipv4Prefix"`
+			IPv6    string `json:"ipv6Prefix"`
+			Service string `json:"service"`
+			Scope   string `json:"scope"`
+		} `json:"prefixes"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var ranges []CloudRange
+	for _, p := range doc.Prefixes {
+		prefix := p.IPv4
+		if prefix == "" {
+			prefix = p.IPv6
+		}
+		ranges = append(ranges, CloudRange{Provider: "GCP", Region: p.Scope, Service: p.Service, Prefix: prefix})
+	}
+	return ranges, nil
+}
+
+// The DigitalOcean list is a geofeed: prefix,country,region,city,postal code
+func parseDigitalOceanRanges(r io.Reader) ([]CloudRange, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	var ranges []CloudRange
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return ranges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		region := ""
+		if len(rec) > 3 {
+			region = rec[3]
+		}
+		if region == "" && len(rec) > 1 {
+			region = rec[1]
+		}
+		ranges = append(ranges, CloudRange{Provider: "DigitalOcean", Region: region, Prefix: rec[0]})
+	}
+}
+
+func parseAzureRanges(r io.Reader) ([]CloudRange, error) {
+	var doc struct {
+		Values []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				Region          string   `json:"region"`
+				SystemService   string   `json:"systemService"`
+				AddressPrefixes []string `json:"addressPrefixes"`
+			} `json:"properties"`
+		} `json:"values"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var ranges []CloudRange
+	for _, v := range doc.Values {
+		// Global tags repeat the regional prefixes without saying where they are
+		if v.Properties.Region == "" {
+			continue
+		}
+		for _, p := range v.Properties.AddressPrefixes {
+			ranges = append(ranges, CloudRange{Provider: "Azure", Region: v.Properties.Region, Service: v.Properties.SystemService, Prefix: p})
+		}
+	}
+	return ranges, nil
+}
@@ -0,0 +1,71 @@
+package shodanx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	RegisterSource(CrtShKey, func(c *Client, opts EnumerateOptions) Source {
+		return NewSource(CrtShKey, func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			// Certificate transparency finds names Shodan never saw served
+			c.logf("[*] Searching certificate transparency logs on crt.sh")
+			names, err := c.CrtSh(ctx, domain)
+			send(SourceResult{Source: SourceCrtSh, Names: names, Err: err})
+		})
+	})
+	RegisterSourceCheck(CrtShKey, func(ctx context.Context, c *Client) (string, error) {
+		return "", c.ping(ctx, CrtShKey, urlOr(c.CrtShURL, DefaultCrtShURL))
+	})
+}
+
+// DefaultCrtShURL is the crt.sh certificate transparency search.
+const DefaultCrtShURL = "https://crt.sh"
+
+// SourceCrtSh is the source recorded for subdomains from crt.sh.
+const SourceCrtSh = "crt.sh"
+
+// CrtShKey is the name the crt.sh source is registered under, which selects
+// it in EnumerateOptions.Sources and keys its SourceSettings. It differs from
+// SourceCrtSh, the site's name recorded in results and databases, since the
+// key follows the other registered names in being a bare word.
+const CrtShKey = "crtsh"
+
+// crtShEntry is one logged certificate of a crt.sh JSON search
+type crtShEntry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"` // SANs, one per line
+}
+
+// CrtSh returns the names of domain and its subdomains found in the
+// certificates logged to certificate transparency, as searched by crt.sh.
+// It needs no API key and costs no credits.
+func (c *Client) CrtSh(ctx context.Context, domain string) ([]string, error) {
+	base := c.CrtShURL
+	if base == "" {
+		base = DefaultCrtShURL
+	}
+	params := url.Values{"q": {"%." + domain}, "output": {"json"}}
+
+	var entries []crtShEntry
+	err := c.call(ctx, request{
+		source: CrtShKey,
+		method: http.MethodGet,
+		url:    strings.TrimRight(base, "/") + "/?" + params.Encode(),
+	}, &entries)
+	if err != nil {
+		return nil, sourceError(CrtShKey, err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		for _, name := range append(strings.Split(e.NameValue, "\n"), e.CommonName) {
+			if name = NormalizeHostname(name); InDomain(name, domain) {
+				names = append(names, name)
+			}
+		}
+	}
+	return Unique(names), nil
+}
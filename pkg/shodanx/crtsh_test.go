@@ -0,0 +1,55 @@
+package shodanx
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCrtSh(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "names and SANs",
+			status: http.StatusOK,
+			body: `[{"common_name": "www.example.com", "name_value": "www.example.com\n*.API.example.com"},
+				{"common_name": "example.com", "name_value": "example.com\nexample.org"},
+				{"common_name": "mail.example.com.", "name_value": "www.example.com"}]`,
+			want: []string{"www.example.com", "api.example.com", "example.com", "mail.example.com"},
+		},
+		{name: "no certificates", status: http.StatusOK, body: `[]`, want: []string{}},
+		{name: "overloaded", status: http.StatusBadGateway, body: "<html>502</html>", wantErr: "crtsh API error (HTTP 502)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if q := r.URL.Query(); q.Get("q") != "%.example.com" || q.Get("output") != "json" {
+					t.Errorf("unexpected query %s", r.URL.RawQuery)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			c.CrtShURL = c.BaseURL
+			names, err := c.CrtSh(context.Background(), "example.com")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("CrtSh = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
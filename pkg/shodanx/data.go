@@ -0,0 +1,111 @@
+package shodanx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Dataset is a bulk data collection available to enterprise accounts
+type Dataset struct {
+	Name        string `json:"name"`
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// DatasetFile is a downloadable file of a dataset. URL is a pre-signed link
+// that does not need the API key.
+type DatasetFile struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Size      int64  `json:"size"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Datasets lists the bulk datasets the account can download
+func (c *Client) Datasets(ctx context.Context) ([]Dataset, error) {
+	var datasets []Dataset
+	if err := c.getJSON(ctx, "/shodan/data", nil, &datasets); err != nil {
+		return nil, err
+	}
+	return datasets, nil
+}
+
+// DatasetFiles lists the files of a dataset
+func (c *Client) DatasetFiles(ctx context.Context, dataset string) ([]DatasetFile, error) {
+	var files []DatasetFile
+	if err := c.getJSON(ctx, "/shodan/data/"+url.PathEscape(dataset), nil, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// DownloadDatasetFile copies the raw (usually gzipped) content of f to w
+func (c *Client) DownloadDatasetFile(ctx context.Context, f DatasetFile, w io.Writer) error {
+	body, err := c.openDatasetFile(ctx, f)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("download of %s interrupted: %w", f.Name, err)
+	}
+	return nil
+}
+
+// ReadDatasetFile downloads f and calls handle for every banner in it,
+// decompressing .gz files on the fly so nothing is written to disk
+func (c *Client) ReadDatasetFile(ctx context.Context, f DatasetFile, handle func(*StreamBanner) error) error {
+	body, err := c.openDatasetFile(ctx, f)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if strings.HasSuffix(f.Name, ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", f.Name, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := c.readBanners(r, handle); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("download of %s interrupted: %w", f.Name, err)
+	}
+	return nil
+}
+
+// Start the download of a dataset file
+func (c *Client) openDatasetFile(ctx context.Context, f DatasetFile) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	// Dataset files are large, so the client timeout must not apply
+	hc := *c.httpClient()
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", redactError(err))
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		var buf bytes.Buffer
+		buf.ReadFrom(resp.Body)
+		return nil, newAPIError(resp.StatusCode, buf.Bytes())
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,98 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// dnsBatchSize is the number of names or IPs sent per /dns/resolve or /dns/reverse request
+const dnsBatchSize = 100
+
+// MaxCIDRSize is the largest number of addresses ExpandCIDR will produce.
+const MaxCIDRSize = 65536
+
+// Resolve looks up the IP address of each hostname through Shodan's DNS
+// resolver, batching requests. Names that don't resolve are left out.
+func (c *Client) Resolve(ctx context.Context, hostnames []string) (map[string]string, error) {
+	resolved := map[string]string{}
+	for _, batch := range batches(hostnames, dnsBatchSize) {
+		var res map[string]*string
+		params := url.Values{"hostnames": {strings.Join(batch, ",")}}
+		if err := c.getJSON(ctx, "/dns/resolve", params, &res); err != nil {
+			return resolved, err
+		}
+		for name, ip := range res {
+			if ip != nil && *ip != "" {
+				resolved[name] = *ip
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// Reverse looks up the hostnames of each IP through Shodan, batching
+// requests. IPs without PTR records are left out.
+func (c *Client) Reverse(ctx context.Context, ips []string) (map[string][]string, error) {
+	names := map[string][]string{}
+	for _, batch := range batches(ips, dnsBatchSize) {
+		var res map[string][]string
+		params := url.Values{"ips": {strings.Join(batch, ",")}}
+		if err := c.getJSON(ctx, "/dns/reverse", params, &res); err != nil {
+			return names, err
+		}
+		for ip, hosts := range res {
+			if len(hosts) > 0 {
+				names[ip] = hosts
+			}
+		}
+	}
+	return names, nil
+}
+
+// ExpandCIDR returns every IPv4 address in cidr, or the address itself for a
+// plain IP. Ranges larger than MaxCIDRSize are rejected.
+func ExpandCIDR(cidr string) ([]string, error) {
+	if ip := net.ParseIP(cidr); ip != nil {
+		return []string{ip.String()}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ip4 := ipnet.IP.To4()
+	if ip4 == nil {
+		return nil, fmt.Errorf("%s: only IPv4 ranges can be expanded", cidr)
+	}
+	ones, bits := ipnet.Mask.Size()
+	size := uint64(1) << uint(bits-ones)
+	if size > MaxCIDRSize {
+		return nil, fmt.Errorf("%s: range has %d addresses, the limit is %d", cidr, size, MaxCIDRSize)
+	}
+
+	start := binary.BigEndian.Uint32(ip4)
+	ips := make([]string, 0, size)
+	for i := uint64(0); i < size; i++ {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], start+uint32(i))
+		ips = append(ips, net.IP(b[:]).String())
+	}
+	return ips, nil
+}
+
+// batches splits items into chunks of at most size elements
+func batches(items []string, size int) [][]string {
+	var out [][]string
+	for len(items) > size {
+		out = append(out, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		out = append(out, items)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package shodanx
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// NormalizeHostname returns the canonical form of a hostname used for
+// deduplication: trimmed, without a port suffix, trailing dot or leading
+// wildcard label, lowercase and in punycode. It returns "" if nothing is left.
+func NormalizeHostname(name string) string {
+	name = strings.TrimSpace(name)
+	if host, port, ok := strings.Cut(name, ":"); ok && port != "" && strings.Trim(port, "0123456789") == "" {
+		name = host
+	}
+	name = strings.ToLower(strings.TrimRight(name, "."))
+	for strings.HasPrefix(name, "*.") {
+		name = name[2:]
+	}
+	if name == "" || name == "*" {
+		return ""
+	}
+	return ToASCII(name)
+}
+
+// RegistrableDomain returns the registrable domain (eTLD+1) of name using the
+// Public Suffix List, e.g. example.co.uk for www.example.co.uk
+func RegistrableDomain(name string) (string, error) {
+	name = strings.ToLower(strings.Trim(strings.TrimPrefix(name, "*."), "."))
+	return publicsuffix.EffectiveTLDPlusOne(name)
+}
+
+// IsApex reports whether name is a registrable domain rather than a
+// subdomain or a public suffix
+func IsApex(name string) bool {
+	apex, err := RegistrableDomain(name)
+	return err == nil && apex == strings.ToLower(strings.Trim(name, "."))
+}
+
+// GroupByDomain groups hostnames by their registrable domain. Names without
+// one, such as bare public suffixes or IPs, are left out.
+func GroupByDomain(names []string) map[string][]string {
+	groups := map[string][]string{}
+	for _, name := range names {
+		apex, err := RegistrableDomain(name)
+		if err != nil {
+			continue
+		}
+		groups[apex] = append(groups[apex], name)
+	}
+	for _, list := range groups {
+		sort.Strings(list)
+	}
+	return groups
+}
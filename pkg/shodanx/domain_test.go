@@ -0,0 +1,25 @@
+package shodanx
+
+import "testing"
+
+func TestNormalizeHostname(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"www.example.com", "www.example.com"},
+		{"  WWW.Example.COM.  ", "www.example.com"},
+		{"*.example.com", "example.com"},
+		{"*.*.example.com", "example.com"},
+		{"api.example.com:8443", "api.example.com"},
+		{"münchen.example.de", "xn--mnchen-3ya.example.de"},
+		{"_dmarc.example.com", "_dmarc.example.com"},
+		{"*", ""},
+		{".", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHostname(tt.name); got != tt.want {
+			t.Errorf("NormalizeHostname(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,657 @@
+package shodanx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Result holds the outcome of an enumeration run.
+type Result struct {
+	Domain     string   `json:"domain"`
+	Queries    []string `json:"queries_used"`
+	Subdomains []string `json:"subdomains"`
+
+	// IPs maps each resolved subdomain to its addresses
+	IPs map[string][]string `json:"ips,omitempty"`
+
+	// CNAMEs maps resolved subdomains to their canonical name
+	CNAMEs map[string]string `json:"cnames,omitempty"`
+
+	// Unresolved lists the subdomains that did not resolve
+	Unresolved []string `json:"unresolved,omitempty"`
+
+	// FaviconMatches lists the services found serving a favicon of a probed host
+	FaviconMatches []FaviconMatch `json:"favicon_matches,omitempty"`
+
+	// Services maps subdomains to the ports Shodan has seen open on them
+	Services map[string][]Service `json:"services,omitempty"`
+
+	// Geo maps the addresses of matched services to their ASN, organisation
+	// and location
+	Geo map[string]*GeoInfo `json:"geo,omitempty"`
+
+	// Exposed lists databases, remote desktops and other high-risk services
+	// open to the internet (see RiskyPorts)
+	Exposed []Exposure `json:"exposed,omitempty"`
+
+	// Vulns maps the addresses of matched services to their CVEs
+	Vulns map[string]*HostVulns `json:"vulns,omitempty"`
+
+	// Leaks lists certificate names that look like internal infrastructure
+	Leaks []Leak `json:"leaks,omitempty"`
+
+	// Certificates lists the matched TLS services with an expired or
+	// self-signed certificate
+	Certificates []CertFinding `json:"certificates,omitempty"`
+
+	// DNSHistory holds the past A/AAAA records of the domain from SecurityTrails
+	DNSHistory []DNSHistory `json:"dns_history,omitempty"`
+
+	// Risks lists the subdomains with a risk score above zero, riskiest first
+	Risks []AssetRisk `json:"risks,omitempty"`
+
+	// Cloud maps addresses in a published cloud range to the provider and region
+	Cloud map[string]*CloudRange `json:"cloud,omitempty"`
+
+	// Buckets lists the cloud storage buckets the names point at
+	Buckets []Bucket `json:"buckets,omitempty"`
+
+	// Mail holds the MX, SPF and DMARC records of the domain and of the
+	// subdomains that have any
+	Mail map[string]*MailRecords `json:"mail,omitempty"`
+
+	// CDN maps resolved subdomains fronted by a CDN to its name
+	CDN map[string]string `json:"cdn,omitempty"`
+
+	// Probes holds the web server found on each subdomain
+	Probes map[string]*Probe `json:"probes,omitempty"`
+
+	// InternetDB holds the InternetDB record of each address
+	InternetDB map[string]*InternetDBHost `json:"internetdb,omitempty"`
+
+	// Domains holds the registered domains seen by a pivot (see Pivot)
+	Domains []string `json:"domains,omitempty"`
+
+	// Netblocks groups the addresses found by a pivot by /24 or /64 network
+	Netblocks map[string][]string `json:"netblocks,omitempty"`
+
+	// Addresses holds bare IP addresses found where a hostname was expected,
+	// e.g. in certificate subjects; they never appear in Subdomains
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Related holds names found by the queries that are not under Domain,
+	// such as unrelated certificate subjects. Pivots leave it empty.
+	Related []string `json:"related,omitempty"`
+
+	// Sources maps each subdomain to the queries or data sources that found it
+	Sources map[string][]string `json:"sources,omitempty"`
+
+	// FirstSeen maps each subdomain to when it was first found. Results
+	// merged into a saved one keep the earlier times.
+	FirstSeen map[string]time.Time `json:"first_seen,omitempty"`
+
+	// Summary holds the top ports, orgs, countries and products across all
+	// unique banners matched by the queries
+	Summary map[string][]FacetValue `json:"summary,omitempty"`
+
+	// Honeyscores holds the honeypot probability of each address
+	Honeyscores map[string]float64 `json:"honeyscores,omitempty"`
+
+	// Truncated maps the queries that had more results than the
+	// EnumerateOptions.MaxPages pages fetched to the number of results left
+	// out
+	Truncated map[string]int `json:"truncated,omitempty"`
+
+	seen map[string]bool
+
+	// within restricts AddHostnames to a domain, see RestrictTo; related
+	// indexes Related
+	within  string
+	related map[string]bool
+
+	// onFound is called by AddHostnames for every new name or address
+	onFound func(name, source string)
+
+	// certNames maps certificate subject names to the services presenting them
+	certNames map[string][]string
+}
+
+// AddResolutions records the addresses and CNAMEs of resolved subdomains in
+// IPs and CNAMEs, the names without addresses in Unresolved and the
+// CDN-fronted ones in CDN
+func (r *Result) AddResolutions(res map[string]*Resolution) {
+	if r.IPs == nil {
+		r.IPs = map[string][]string{}
+	}
+	for _, name := range r.Subdomains {
+		rr, ok := res[name]
+		if !ok {
+			continue
+		}
+		if rr.CNAME != "" {
+			if r.CNAMEs == nil {
+				r.CNAMEs = map[string]string{}
+			}
+			r.CNAMEs[name] = rr.CNAME
+		}
+		if len(rr.Addresses) == 0 {
+			r.Unresolved = append(r.Unresolved, name)
+			continue
+		}
+		r.IPs[name] = rr.Addresses
+		if cdn := DetectCDN(rr); cdn != "" {
+			if r.CDN == nil {
+				r.CDN = map[string]string{}
+			}
+			r.CDN[name] = cdn
+		}
+	}
+}
+
+// Origins returns the addresses of the resolved subdomains that are not
+// fronted by a CDN, whose ports and services belong to the CDN rather than
+// the origin
+func (r *Result) Origins() map[string][]string {
+	origins := map[string][]string{}
+	for name, ips := range r.IPs {
+		if r.CDN[name] == "" {
+			origins[name] = ips
+		}
+	}
+	return origins
+}
+
+// SourceDNS is the source recorded for subdomains from the Shodan DNS API.
+const SourceDNS = "dns"
+
+// RestrictTo makes AddHostnames keep only domain and its subdomains and move
+// other names to Related. Enumerate restricts its results to the target
+// domain; pivots, whose targets are not domains, are not restricted.
+func (r *Result) RestrictTo(domain string) {
+	r.within = ToASCII(strings.ToLower(strings.Trim(domain, ".")))
+}
+
+// AddHostnames adds names found by source to Subdomains in their normalized
+// form (see NormalizeHostname), skipping names already present, and records
+// source for each of them in Sources. IP addresses go to Addresses instead,
+// and names outside the domain set with RestrictTo to Related.
+func (r *Result) AddHostnames(source string, names ...string) {
+	if r.seen == nil {
+		r.seen = make(map[string]bool, len(r.Subdomains))
+		for _, s := range r.Subdomains {
+			r.seen[s] = true
+		}
+	}
+	if r.Sources == nil {
+		r.Sources = make(map[string][]string)
+	}
+	if r.FirstSeen == nil {
+		r.FirstSeen = make(map[string]time.Time)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, name := range names {
+		if name = NormalizeHostname(name); name == "" {
+			continue
+		}
+		if net.ParseIP(name) != nil {
+			if !contains(r.Addresses, name) {
+				r.Addresses = append(r.Addresses, name)
+				if r.onFound != nil {
+					r.onFound(name, source)
+				}
+			}
+			continue
+		}
+		if r.within != "" && !InDomain(name, r.within) {
+			r.addRelated(name)
+			continue
+		}
+		if !r.seen[name] {
+			r.seen[name] = true
+			r.Subdomains = append(r.Subdomains, name)
+			if _, ok := r.FirstSeen[name]; !ok {
+				r.FirstSeen[name] = now
+			}
+			if r.onFound != nil {
+				r.onFound(name, source)
+			}
+		}
+		if !contains(r.Sources[name], source) {
+			r.Sources[name] = append(r.Sources[name], source)
+		}
+	}
+}
+
+// addRelated adds a name outside the domain to Related once
+func (r *Result) addRelated(name string) {
+	if r.related == nil {
+		r.related = make(map[string]bool, len(r.Related))
+		for _, s := range r.Related {
+			r.related[s] = true
+		}
+	}
+	if !r.related[name] {
+		r.related[name] = true
+		r.Related = append(r.Related, name)
+	}
+}
+
+// Merge adds the hostnames of other that are domain or one of its
+// subdomains, keeping their sources
+func (r *Result) Merge(other *Result, domain string) {
+	for _, name := range other.Subdomains {
+		if !InDomain(name, domain) {
+			continue
+		}
+		for _, src := range other.Sources[name] {
+			r.AddHostnames(src, name)
+		}
+	}
+}
+
+// AddReverse adds the hostnames of a reverse lookup (IP to names) that fall
+// under domain, recorded with source, and returns how many were new
+func (r *Result) AddReverse(source string, names map[string][]string, domain string) int {
+	n := len(r.Subdomains)
+	for _, hosts := range names {
+		for _, h := range hosts {
+			if InDomain(NormalizeHostname(h), domain) {
+				r.AddHostnames(source, h)
+			}
+		}
+	}
+	sort.Strings(r.Subdomains[n:])
+	return len(r.Subdomains) - n
+}
+
+// InDomain reports whether name is domain or one of its subdomains
+func InDomain(name, domain string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	domain = strings.ToLower(strings.Trim(domain, "."))
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultQueries returns the built-in Shodan queries used to discover subdomains of domain.
+func DefaultQueries(domain string) []string {
+	return []string{
+		// Basic hostname and SSL certificate queries
+		filter("hostname", domain),
+		filter("ssl.cert.subject.cn", domain),
+		filter("ssl.cert.subject.an", domain),
+		filter("ssl.cert.issuer.cn", domain),
+		filter("ssl.cert.issuer.o", domain),
+
+		// HTTP content queries
+		filter("http.title", domain),
+		filter("http.html", domain),
+		filter("http.component", domain),
+
+		// SSL Subject Alternative Names (SAN) - Critical for subdomains
+		filter("ssl.cert.subject.alt_names", domain),
+		filter("ssl.cert.extensions.subject_alt_name", domain),
+
+		// Server headers and metadata
+		filter("http.server", domain),
+		filter("http.headers", domain),
+		filter("http.location", domain),
+
+		// Mail servers and email-related services
+		filter("smtp.starttls.tls.certificate.parsed.subject.common_name", domain),
+		filter("smtp.starttls.tls.certificate.parsed.extensions.subject_alt_name.dns_names", domain),
+
+		// FTP services
+		filter("ftp.banner", domain),
+
+		// DNS-related queries
+		filter("dns.txt", domain),
+		filter("dns.mx", domain),
+
+		// Organization and ASN queries
+		filter("org", domain),
+		filter("asn.description", domain),
+
+		// Certificate transparency logs
+		filter("ssl.cert.serial", domain),
+		filter("ssl.cert.fingerprint", domain),
+
+		// Catch-all queries
+		filter("all", domain),
+
+		// Additional wildcard patterns for common subdomains
+		filter("hostname", "*."+domain),
+		filter("ssl.cert.subject.cn", "*."+domain),
+		filter("ssl.cert.subject.alt_names", "*."+domain),
+	}
+}
+
+// ExpandQueries renders query templates for domain. Templates use Go
+// text/template syntax and receive the target as {{.Domain}}; the quote
+// function escapes a value, e.g. ssl.cert.subject.cn:{{quote .Domain}}.
+func ExpandQueries(templates []string, domain string) ([]string, error) {
+	data := struct{ Domain string }{domain}
+	funcs := template.FuncMap{"quote": QuoteValue}
+	queries := make([]string, 0, len(templates))
+	for _, t := range templates {
+		tmpl, err := template.New("query").Funcs(funcs).Parse(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query template %q: %w", t, err)
+		}
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("invalid query template %q: %w", t, err)
+		}
+		queries = append(queries, sb.String())
+	}
+	return queries, nil
+}
+
+// EnumerateOptions tunes an enumeration run.
+type EnumerateOptions struct {
+	// Queries to run; nil uses DefaultQueries
+	Queries []string
+
+	// MaxPages limits the result pages fetched per query. Zero or less fetches all pages.
+	MaxPages int
+
+	// PreCount runs a free count (see Count) before each query and skips
+	// queries without results, so no credit is spent on them
+	PreCount bool
+
+	// Extra queries run in addition to Queries
+	Extra []string
+
+	// IncludeBroad keeps the built-in queries in BroadFilters, which match
+	// anywhere in a banner and return mostly unrelated hosts
+	IncludeBroad bool
+
+	// Exclude drops queries matching any of these patterns, see MatchQuery
+	Exclude []string
+
+	// Depth is the number of recursive passes that query the intermediate
+	// levels of discovered subdomains (see ParentDomains). Zero disables recursion.
+	Depth int
+
+	// SkipDNS leaves out the DNS API lookup, e.g. when the target is not a domain
+	SkipDNS bool
+
+	// SkipShodan runs only the Sources, leaving out the
+	// Shodan queries, the DNS API lookup and recursion
+	SkipShodan bool
+
+	// Sources are the registered sources (see SourceNames) run after the
+	// Shodan queries and DNS API lookup, in this order
+	Sources []string
+
+	// OnFound is called with every new subdomain or IP address as soon as
+	// a query or source finds it, e.g. to stream results. Names outside the
+	// domain, which end up in Result.Related, are not reported.
+	OnFound func(name, source string)
+
+	// OnMatch is called with every distinct banner the queries and sources
+	// match, e.g. to archive them; Match.Raw holds the banners from Shodan
+	// if the Client keeps them, see Client.KeepRaw
+	OnMatch func(m *Match)
+
+	// Filters is appended to every query to scope the search, e.g.
+	// `country:DE,FR port:443`. It does not apply to the DNS API lookup.
+	Filters string
+}
+
+// BroadFilters are the filters of built-in queries that are skipped unless
+// EnumerateOptions.IncludeBroad is set.
+var BroadFilters = []string{"all", "http.html"}
+
+// MatchQuery reports whether pattern matches query. The pattern is compared
+// to the whole query and to its first filter name; * matches any text, e.g.
+// "http.*" or "ssl.cert.serial".
+func MatchQuery(pattern, query string) bool {
+	re := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	name, _, _ := strings.Cut(query, ":")
+	for _, s := range []string{query, name} {
+		if ok, _ := regexp.MatchString(re, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the queries to run for domain, without excluded queries and
+// scoped by Filters
+func (o EnumerateOptions) queries(domain string) []string {
+	queries := o.Queries
+	if queries == nil {
+		queries = DefaultQueries(domain)
+		if !o.IncludeBroad {
+			queries = exclude(queries, BroadFilters)
+		}
+	}
+	if len(o.Extra) > 0 {
+		queries = Unique(append(append([]string(nil), queries...), o.Extra...))
+	}
+	queries = exclude(queries, o.Exclude)
+	if o.Filters == "" {
+		return queries
+	}
+	scoped := make([]string, len(queries))
+	for i, q := range queries {
+		scoped[i] = NewQuery().Raw(q).Raw(o.Filters).String()
+	}
+	return scoped
+}
+
+// Return the queries of a recursive pass for parent, without excluded
+// queries and scoped by Filters like the others
+func (o EnumerateOptions) recursiveQueries(parent string) []string {
+	return EnumerateOptions{Queries: RecursiveQueries(parent), Filters: o.Filters, Exclude: o.Exclude}.queries(parent)
+}
+
+// Drop the queries matching any pattern
+func exclude(queries, patterns []string) []string {
+	if len(patterns) == 0 {
+		return queries
+	}
+	var kept []string
+	for _, q := range queries {
+		skip := false
+		for _, p := range patterns {
+			if MatchQuery(p, q) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
+
+// Enumerate runs every query against Shodan, adds the DNS API results (and
+// those of the other sources enabled in opts) and returns the deduplicated
+// subdomains of domain; other names are moved to Result.Related. Failed
+// queries and sources are logged and skipped.
+// If ctx is cancelled, or a fatal API error (see IsFatal) occurs, the
+// subdomains collected so far are returned together with the error.
+func (c *Client) Enumerate(ctx context.Context, domain string, opts EnumerateOptions) (*Result, error) {
+	domain = ToASCII(domain)
+	queries := opts.queries(domain)
+	if opts.SkipShodan {
+		queries = []string{}
+		opts.SkipDNS, opts.Depth = true, 0
+	}
+
+	// Certificate subjects and banners often name other domains
+	result := &Result{Domain: domain, Queries: queries, Subdomains: []string{}}
+	result.RestrictTo(domain)
+	result.onFound = opts.OnFound
+	facets := newFacetCounter()
+	facets.onMatch = opts.OnMatch
+	partial := func() *Result {
+		result.Services = sortServices(facets.services)
+		for _, name := range result.Related {
+			delete(result.Services, name)
+		}
+		result.Summary = facets.top(SummaryTop)
+		result.Certificates = facets.certs
+		result.Vulns = facets.hostVulns()
+		result.Geo = facets.hostGeo()
+		result.Exposed = facets.exposed
+		sortExposures(result.Exposed)
+		result.certNames = facets.names
+		result.FindLeaks()
+		return result
+	}
+
+	// Run a query, returning only fatal errors
+	search := func(q string) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if opts.PreCount {
+			count, err := c.Count(ctx, q, nil)
+			if IsFatal(err) {
+				return err
+			}
+			if err == nil && count.Total == 0 {
+				c.logf("[*] Query: %s (no results, skipped)", q)
+				return nil
+			}
+		}
+		c.logf("[*] Query: %s", q)
+		res, err := c.SearchAll(ctx, q, opts.MaxPages)
+		if res != nil {
+			result.AddHostnames(q, res.Hostnames()...)
+			facets.add(res.Matches)
+		}
+		if IsFatal(err) {
+			return err
+		}
+		if err != nil {
+			c.logf("[!] %v", err)
+			return nil
+		}
+		if len(res.Matches) < res.Total {
+			if result.Truncated == nil {
+				result.Truncated = make(map[string]int)
+			}
+			result.Truncated[q] = res.Total - len(res.Matches)
+		}
+		return nil
+	}
+
+	for _, q := range queries {
+		if err := search(q); err != nil {
+			return partial(), err
+		}
+	}
+
+	// Add DNS API results
+	if ctx.Err() != nil {
+		return partial(), ctx.Err()
+	}
+	if !opts.SkipDNS {
+		dns, err := c.DNSDomain(ctx, domain)
+		if IsFatal(err) {
+			return partial(), err
+		}
+		if err != nil {
+			c.logf("[!] %v", err)
+		} else {
+			result.AddHostnames(SourceDNS, dns.Hostnames()...)
+		}
+	}
+
+	// The other sources, see RegisterSource
+	c.runSources(ctx, domain, opts, result, facets)
+
+	// Query the intermediate levels of newly found names, e.g.
+	// internal.example.com for dev.internal.example.com
+	queried := map[string]bool{}
+	for depth := 1; depth <= opts.Depth; depth++ {
+		parents := ParentDomains(result.Subdomains, domain, queried)
+		if len(parents) == 0 {
+			break
+		}
+		c.logf("[*] Recursion depth %d/%d: %d parent domains", depth, opts.Depth, len(parents))
+		for _, parent := range parents {
+			queried[parent] = true
+			for _, q := range opts.recursiveQueries(parent) {
+				result.Queries = append(result.Queries, q)
+				if err := search(q); err != nil {
+					return partial(), err
+				}
+			}
+		}
+	}
+
+	return partial(), ctx.Err()
+}
+
+// RecursiveQueries returns the hostname and certificate queries run against
+// an intermediate subdomain during recursive enumeration.
+func RecursiveQueries(parent string) []string {
+	return []string{
+		filter("hostname", parent),
+		filter("ssl.cert.subject.cn", "*."+parent),
+		filter("ssl.cert.subject.alt_names", "*."+parent),
+	}
+}
+
+// ParentDomains returns the intermediate levels between domain and each of
+// names, e.g. internal.example.com for dev.internal.example.com, skipping the
+// ones in exclude. The result is sorted from the shallowest level.
+func ParentDomains(names []string, domain string, exclude map[string]bool) []string {
+	domain = strings.ToLower(strings.Trim(domain, "."))
+	seen := map[string]bool{}
+	var parents []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimPrefix(name, "*."))
+		if !strings.HasSuffix(name, "."+domain) {
+			continue
+		}
+		labels := strings.Split(strings.TrimSuffix(name, "."+domain), ".")
+		// Every suffix except the full name itself is a parent
+		for i := 1; i < len(labels); i++ {
+			parent := strings.Join(labels[i:], ".") + "." + domain
+			if !seen[parent] && !exclude[parent] {
+				seen[parent] = true
+				parents = append(parents, parent)
+			}
+		}
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		if a, b := strings.Count(parents[i], "."), strings.Count(parents[j], "."); a != b {
+			return a < b
+		}
+		return parents[i] < parents[j]
+	})
+	return parents
+}
+
+// Unique removes duplicates while preserving order
+func Unique(input []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, v := range input {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
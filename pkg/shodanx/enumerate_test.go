@@ -0,0 +1,158 @@
+package shodanx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestInDomain(t *testing.T) {
+	tests := []struct {
+		name, domain string
+		want         bool
+	}{
+		{"example.com", "example.com", true},
+		{"www.example.com", "example.com", true},
+		{"a.b.example.com", "example.com", true},
+		{"WWW.Example.com.", ".example.com.", true},
+		{"notexample.com", "example.com", false},
+		{"example.com.evil.org", "example.com", false},
+		{"example.com", "www.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := InDomain(tt.name, tt.domain); got != tt.want {
+			t.Errorf("InDomain(%q, %q) = %v, want %v", tt.name, tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestAddHostnames(t *testing.T) {
+	tests := []struct {
+		name       string
+		restrict   string
+		names      []string
+		subdomains []string
+		related    []string
+		addresses  []string
+	}{
+		{
+			name:       "normalized and deduplicated",
+			names:      []string{"WWW.example.com.", "*.www.example.com", "api.example.com:443", "www.example.com"},
+			subdomains: []string{"www.example.com", "api.example.com"},
+		},
+		{
+			name:       "IPs kept apart",
+			names:      []string{"192.0.2.1", "mail.example.com", "2001:db8::1", "192.0.2.1"},
+			subdomains: []string{"mail.example.com"},
+			addresses:  []string{"192.0.2.1", "2001:db8::1"},
+		},
+		{
+			name:       "unrestricted keeps every name",
+			names:      []string{"www.example.com", "cdn.other.net"},
+			subdomains: []string{"www.example.com", "cdn.other.net"},
+		},
+		{
+			name:       "restricted moves other names to related",
+			restrict:   "Example.com.",
+			names:      []string{"www.example.com", "cdn.other.net", "example.com.evil.org", "cdn.other.net"},
+			subdomains: []string{"www.example.com"},
+			related:    []string{"cdn.other.net", "example.com.evil.org"},
+		},
+		{
+			name:       "restricted to an IDN",
+			restrict:   "münchen.de",
+			names:      []string{"www.münchen.de", "www.xn--mnchen-3ya.de"},
+			subdomains: []string{"www.xn--mnchen-3ya.de"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Result{}
+			if tt.restrict != "" {
+				r.RestrictTo(tt.restrict)
+			}
+			r.AddHostnames("test", tt.names...)
+			if !reflect.DeepEqual(r.Subdomains, tt.subdomains) {
+				t.Errorf("Subdomains = %v, want %v", r.Subdomains, tt.subdomains)
+			}
+			if !reflect.DeepEqual(r.Related, tt.related) {
+				t.Errorf("Related = %v, want %v", r.Related, tt.related)
+			}
+			if !reflect.DeepEqual(r.Addresses, tt.addresses) {
+				t.Errorf("Addresses = %v, want %v", r.Addresses, tt.addresses)
+			}
+			for _, name := range r.Subdomains {
+				if got := r.Sources[name]; !reflect.DeepEqual(got, []string{"test"}) {
+					t.Errorf("Sources[%s] = %v, want [test]", name, got)
+				}
+				if r.FirstSeen[name].IsZero() {
+					t.Errorf("FirstSeen[%s] not set", name)
+				}
+			}
+		})
+	}
+}
+
+func TestEnumerateTruncated(t *testing.T) {
+	// "big" has 250 results, "small" 50; each page holds up to 100
+	totals := map[string]int{"big": 250, "small": 50}
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		total := totals[q.Get("query")]
+		page, _ := strconv.Atoi(q.Get("page"))
+		n := total - (page-1)*SearchPageSize
+		if n > SearchPageSize {
+			n = SearchPageSize
+		}
+		matches := make([]string, n)
+		for i := range matches {
+			matches[i] = fmt.Sprintf(`{"ip_str": "192.0.2.%d", "port": %d, "hostnames": ["www.example.com"]}`, page, i)
+		}
+		fmt.Fprintf(w, `{"total": %d, "matches": [%s]}`, total, strings.Join(matches, ","))
+	})
+	tests := []struct {
+		maxPages int
+		want     map[string]int
+	}{
+		{1, map[string]int{"big": 150}},
+		{2, map[string]int{"big": 50}},
+		{0, nil},
+	}
+	for _, tt := range tests {
+		opts := EnumerateOptions{Queries: []string{"big", "small"}, MaxPages: tt.maxPages, SkipDNS: true}
+		result, err := c.Enumerate(context.Background(), "example.com", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Truncated, tt.want) {
+			t.Errorf("MaxPages %d: Truncated = %v, want %v", tt.maxPages, result.Truncated, tt.want)
+		}
+	}
+}
+
+func TestRecursiveQueries(t *testing.T) {
+	tests := []struct {
+		name string
+		opts EnumerateOptions
+		want []string
+	}{
+		{"all", EnumerateOptions{}, []string{
+			`hostname:"internal.example.com"`,
+			`ssl.cert.subject.cn:"*.internal.example.com"`,
+			`ssl.cert.subject.alt_names:"*.internal.example.com"`,
+		}},
+		{"excluded and scoped", EnumerateOptions{Exclude: []string{"ssl.cert.*"}, Filters: "country:DE"}, []string{
+			`hostname:"internal.example.com" country:DE`,
+		}},
+		{"all excluded", EnumerateOptions{Exclude: []string{"*"}}, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.recursiveQueries("internal.example.com"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: recursiveQueries = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package shodanx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for the Shodan failures callers usually need to tell
+// apart. Match them with errors.Is.
+var (
+	ErrUnauthorized = errors.New("invalid or unauthorized API key")
+	ErrNoCredits    = errors.New("insufficient API credits")
+	ErrRateLimited  = errors.New("rate limited by Shodan")
+)
+
+// APIError is a non-2xx response from the Shodan API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("shodan API error (HTTP %d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("shodan API error (HTTP %d)", e.StatusCode)
+}
+
+// Unwrap maps the response to one of the sentinel errors
+func (e *APIError) Unwrap() error {
+	msg := strings.ToLower(e.Message)
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || strings.Contains(msg, "invalid api key"):
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusPaymentRequired || strings.Contains(msg, "credits"):
+		return ErrNoCredits
+	case e.StatusCode == http.StatusTooManyRequests || strings.Contains(msg, "rate limit"):
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// newAPIError extracts the {"error": "..."} message Shodan sends with failures
+func newAPIError(status int, body []byte) *APIError {
+	var payload struct {
+		Error string `json:"error"`
+	}
+	msg := ""
+	if json.Unmarshal(body, &payload) == nil {
+		msg = payload.Error
+	}
+	if msg == "" {
+		msg = strings.TrimSpace(string(body))
+		if len(msg) > 200 || strings.HasPrefix(msg, "<") {
+			msg = http.StatusText(status)
+		}
+	}
+	return &APIError{StatusCode: status, Message: msg}
+}
+
+// IsFatal reports whether err means no further request can succeed with
+// this key, so long runs should stop instead of skipping the query.
+func IsFatal(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrNoCredits) ||
+		errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCreditBudget)
+}
+
+// sourceError names the API of another source in its errors, which would
+// otherwise read as Shodan's
+func sourceError(source string, err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("%s API error (HTTP %d): %s", source, apiErr.StatusCode, apiErr.Message)
+	}
+	return err
+}
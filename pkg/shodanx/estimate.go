@@ -0,0 +1,59 @@
+package shodanx
+
+import "context"
+
+// QueryEstimate is the expected cost of a single query of an enumeration run.
+type QueryEstimate struct {
+	Query   string `json:"query"`
+	Total   int    `json:"total"`
+	Pages   int    `json:"pages"`
+	Credits int    `json:"credits"`
+}
+
+// Estimate is the expected cost of an enumeration run.
+type Estimate struct {
+	Queries []QueryEstimate `json:"queries"`
+
+	// Credits includes the DNS API lookup unless SkipDNS was set
+	Credits int `json:"credits"`
+
+	// PerParent is the number of queries run for every intermediate
+	// subdomain found when EnumerateOptions.Depth is set. How many there
+	// are depends on the names found, so Credits leaves them out.
+	PerParent int `json:"per_parent,omitempty"`
+}
+
+// Estimate returns the queries Enumerate would run for domain with opts and
+// the query credits they would consume, based on free result counts. No
+// query credits are spent.
+func (c *Client) Estimate(ctx context.Context, domain string, opts EnumerateOptions) (*Estimate, error) {
+	est := &Estimate{}
+	if opts.SkipShodan {
+		return est, nil
+	}
+	for _, q := range opts.queries(ToASCII(domain)) {
+		res, err := c.Count(ctx, q, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		qe := QueryEstimate{Query: q, Total: res.Total, Pages: pageCount(res.Total)}
+		if opts.MaxPages > 0 && qe.Pages > opts.MaxPages {
+			qe.Pages = opts.MaxPages
+		}
+		// A search costs a credit even without results, unless PreCount skips it
+		qe.Credits = qe.Pages
+		if qe.Credits == 0 && !opts.PreCount {
+			qe.Credits = 1
+		}
+		est.Queries = append(est.Queries, qe)
+		est.Credits += qe.Credits
+	}
+	if !opts.SkipDNS {
+		est.Credits++ // DNS API
+	}
+	if opts.Depth > 0 {
+		est.PerParent = len(opts.recursiveQueries(ToASCII(domain)))
+	}
+	return est, nil
+}
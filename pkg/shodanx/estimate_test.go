@@ -0,0 +1,41 @@
+package shodanx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	totals := map[string]int{"big": 250, "small": 50, "empty": 0}
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shodan/host/count" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"total": %d, "matches": []}`, totals[r.URL.Query().Get("query")])
+	})
+	queries := []string{"big", "small", "empty"}
+	tests := []struct {
+		name      string
+		opts      EnumerateOptions
+		credits   int
+		perParent int
+	}{
+		{"every page", EnumerateOptions{Queries: queries}, 3 + 1 + 1 + 1, 0},
+		{"first page", EnumerateOptions{Queries: queries, MaxPages: 1}, 1 + 1 + 1 + 1, 0},
+		{"empty queries skipped", EnumerateOptions{Queries: queries, MaxPages: 1, PreCount: true, SkipDNS: true}, 1 + 1, 0},
+		{"recursive", EnumerateOptions{Queries: queries, MaxPages: 2, Depth: 2}, 2 + 1 + 1 + 1, 3},
+		{"recursive without certificate queries", EnumerateOptions{Queries: queries, Depth: 1, Exclude: []string{"ssl.*"}, SkipDNS: true}, 3 + 1 + 1, 1},
+		{"other sources only", EnumerateOptions{Queries: queries, Depth: 1, SkipShodan: true}, 0, 0},
+	}
+	for _, tt := range tests {
+		est, err := c.Estimate(context.Background(), "example.com", tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if est.Credits != tt.credits || est.PerParent != tt.perParent {
+			t.Errorf("%s: %d credits and %d per parent, want %d and %d", tt.name, est.Credits, est.PerParent, tt.credits, tt.perParent)
+		}
+	}
+}
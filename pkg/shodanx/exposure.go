@@ -0,0 +1,134 @@
+package shodanx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RiskyPorts maps ports that should never face the internet to the service
+// usually listening on them.
+var RiskyPorts = map[int]string{
+	9200:  "Elasticsearch",
+	27017: "MongoDB",
+	6379:  "Redis",
+	3389:  "RDP",
+	3306:  "MySQL",
+	5432:  "PostgreSQL",
+	1433:  "MSSQL",
+	11211: "Memcached",
+	5984:  "CouchDB",
+	9042:  "Cassandra",
+	2375:  "Docker API",
+	5900:  "VNC",
+	23:    "Telnet",
+	445:   "SMB",
+}
+
+// riskyModules names the same services by Shodan module, to catch them on
+// other ports
+var riskyModules = map[string]string{
+	"elastic":    "Elasticsearch",
+	"mongodb":    "MongoDB",
+	"redis":      "Redis",
+	"rdp":        "RDP",
+	"mysql":      "MySQL",
+	"postgresql": "PostgreSQL",
+	"mssql":      "MSSQL",
+	"memcache":   "Memcached",
+	"couchdb":    "CouchDB",
+	"cassandra":  "Cassandra",
+	"docker":     "Docker API",
+	"vnc":        "VNC",
+	"telnet":     "Telnet",
+	"smb":        "SMB",
+}
+
+// Exposure is a high-risk service reachable from the internet.
+type Exposure struct {
+	IP        string   `json:"ip"`
+	Port      int      `json:"port"`
+	Service   string   `json:"service"`
+	Product   string   `json:"product,omitempty"`
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// Source is "shodan" for a service Shodan banner-grabbed, "internetdb"
+	// for an open port InternetDB lists for the address
+	Source string `json:"source"`
+}
+
+// Sources of exposures
+const (
+	ExposureShodan     = "shodan"
+	ExposureInternetDB = "internetdb"
+)
+
+// riskyService returns the high-risk service of a banner, or ""
+func riskyService(m *Match) string {
+	module := strings.ToLower(m.Shodan.Module)
+	for prefix, service := range riskyModules {
+		if strings.HasPrefix(module, prefix) {
+			return service
+		}
+	}
+	return RiskyPorts[m.Port]
+}
+
+// addExposure records a banner of a high-risk service
+func addExposure(exposed []Exposure, m *Match) []Exposure {
+	service := riskyService(m)
+	if service == "" {
+		return exposed
+	}
+	return append(exposed, Exposure{
+		IP:        m.IPStr,
+		Port:      m.Port,
+		Service:   service,
+		Product:   m.Product,
+		Hostnames: m.Hostnames,
+		Source:    ExposureShodan,
+	})
+}
+
+// AddInternetDBExposures adds the high-risk ports InternetDB lists for the
+// resolved addresses that Shodan's banners did not already show
+func (r *Result) AddInternetDBExposures() {
+	known := map[string]bool{}
+	for _, e := range r.Exposed {
+		known[e.IP+":"+strconv.Itoa(e.Port)] = true
+	}
+	names := map[string][]string{}
+	for name, ips := range r.IPs {
+		for _, ip := range ips {
+			names[ip] = append(names[ip], name)
+		}
+	}
+	for ip, host := range r.InternetDB {
+		if host == nil {
+			continue
+		}
+		for _, port := range host.Ports {
+			service := RiskyPorts[port]
+			if service == "" || known[ip+":"+strconv.Itoa(port)] {
+				continue
+			}
+			sort.Strings(names[ip])
+			r.Exposed = append(r.Exposed, Exposure{IP: ip, Port: port, Service: service, Hostnames: names[ip], Source: ExposureInternetDB})
+		}
+	}
+	sortExposures(r.Exposed)
+}
+
+// sortExposures orders exposures by service, address and port
+func sortExposures(exposed []Exposure) {
+	sort.Slice(exposed, func(i, j int) bool {
+		a, b := exposed[i], exposed[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.IP != b.IP {
+			return a.IP < b.IP
+		}
+		return a.Port < b.Port
+	})
+}
@@ -0,0 +1,163 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FaviconHash returns the hash Shodan indexes as http.favicon.hash: the
+// 32-bit MurmurHash3 of the favicon encoded as MIME base64, with a newline
+// after every 76 characters and at the end.
+func FaviconHash(data []byte) int32 {
+	enc := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for len(enc) > 76 {
+		b.WriteString(enc[:76])
+		b.WriteByte('\n')
+		enc = enc[76:]
+	}
+	b.WriteString(enc)
+	b.WriteByte('\n')
+	return int32(murmur3([]byte(b.String()), 0))
+}
+
+// FaviconQuery returns the query matching services with the favicon hash
+func FaviconQuery(hash int32) string {
+	return filter("http.favicon.hash", strconv.Itoa(int(hash)))
+}
+
+// FaviconMatch is a service that serves one of the favicons of a target.
+type FaviconMatch struct {
+	Hash      int32    `json:"hash"`
+	IP        string   `json:"ip"`
+	Port      int      `json:"port"`
+	Hostnames []string `json:"hostnames,omitempty"`
+	Org       string   `json:"org,omitempty"`
+}
+
+// SearchFavicons finds the services Shodan has seen serving each favicon
+// hash, fetching up to maxPages pages per hash (zero or less fetches all).
+// Failed queries are logged and skipped; on a fatal error the matches found
+// so far are returned together with it.
+func (c *Client) SearchFavicons(ctx context.Context, hashes []int32, maxPages int) ([]FaviconMatch, error) {
+	var matches []FaviconMatch
+	seen := map[string]bool{}
+	for _, hash := range hashes {
+		if ctx.Err() != nil {
+			return matches, ctx.Err()
+		}
+		q := FaviconQuery(hash)
+		c.logf("[*] Query: %s", q)
+		res, err := c.SearchAll(ctx, q, maxPages)
+		if res != nil {
+			for _, m := range res.Matches {
+				key := m.IPStr + "/" + strconv.Itoa(m.Port)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				matches = append(matches, FaviconMatch{Hash: hash, IP: m.IPStr, Port: m.Port, Hostnames: m.Hostnames, Org: m.Org})
+			}
+		}
+		if IsFatal(err) {
+			return matches, err
+		}
+		if err != nil {
+			c.logf("[!] %v", err)
+		}
+	}
+	return matches, nil
+}
+
+// maxFavicon is the largest favicon that is downloaded
+const maxFavicon = 1 << 20
+
+var iconLinkRe = regexp.MustCompile(`(?is)<link[^>]+rel=["']?[^"'>]*icon[^>]*>`)
+var hrefRe = regexp.MustCompile(`(?is)href=["']?([^"'\s>]+)`)
+
+// faviconURL returns the icon linked from a page, or /favicon.ico
+func faviconURL(pageURL string, body []byte) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	if link := iconLinkRe.Find(body); link != nil {
+		if m := hrefRe.FindSubmatch(link); m != nil {
+			if ref, err := url.Parse(string(m[1])); err == nil && !strings.HasPrefix(string(m[1]), "data:") {
+				return base.ResolveReference(ref).String()
+			}
+		}
+	}
+	return base.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+}
+
+// fetchFavicon downloads an icon, returning nil if there is none
+func fetchFavicon(ctx context.Context, client *http.Client, iconURL string) []byte {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	// Missing icons are often answered with the site's HTML error or login page
+	if resp.StatusCode != http.StatusOK || strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFavicon))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// murmur3 is the 32-bit x86 MurmurHash3 used by Python's mmh3.hash
+func murmur3(data []byte, seed uint32) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
@@ -0,0 +1,188 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterSource("fofa", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("fofa", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			forEach(ctx, FofaQueries(domain), c.sourceConcurrency(SourceFofa), func(q string) {
+				c.logf("[*] FOFA query: %s", q)
+				r := SourceResult{Source: SourceFofa + ":" + q}
+				res, err := c.FofaSearch(ctx, q, opts.MaxPages)
+				if res != nil {
+					r.Matches = res.Matches
+				}
+				r.Err = err
+				send(r)
+			})
+		})
+	})
+	RegisterSourceCheck("fofa", func(ctx context.Context, c *Client) (string, error) {
+		return c.fofaQuota(ctx)
+	})
+}
+
+// DefaultFofaURL is the FOFA API endpoint.
+const DefaultFofaURL = "https://fofa.info"
+
+// SourceFofa prefixes the FOFA queries recorded as sources, e.g.
+// `fofa:domain="example.com"`.
+const SourceFofa = "fofa"
+
+// FofaPageSize is the number of results per FOFA search page.
+const FofaPageSize = 100
+
+// fofaFields are the fields requested for every result, in row order
+var fofaFields = []string{"host", "ip", "port", "base_protocol", "protocol", "server", "country", "as_number", "as_organization", "cert"}
+
+// FofaQueries returns the FOFA searches run for domain.
+func FofaQueries(domain string) []string {
+	return []string{
+		`domain="` + domain + `"`,
+		`cert="` + domain + `"`,
+		`host=".` + domain + `"`,
+	}
+}
+
+// HasFofa reports whether the client has a FOFA API key
+func (c *Client) HasFofa() bool {
+	return c.FofaKey != ""
+}
+
+// FofaSearch runs a FOFA search, fetching up to maxPages pages (zero or
+// less fetches all), and returns the results as Shodan-style Matches whose
+// hostnames and certificate subjects go through the same result pipeline.
+// Like SearchAll, the matches collected so far are returned with a later error.
+func (c *Client) FofaSearch(ctx context.Context, query string, maxPages int) (*SearchResult, error) {
+	if !c.HasFofa() {
+		return nil, errors.New("fofa API key is not set")
+	}
+	base := c.FofaURL
+	if base == "" {
+		base = DefaultFofaURL
+	}
+
+	all := &SearchResult{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		params := url.Values{
+			"key":     {c.FofaKey},
+			"qbase64": {base64.StdEncoding.EncodeToString([]byte(query))},
+			"fields":  {strings.Join(fofaFields, ",")},
+			"page":    {strconv.Itoa(page)},
+			"size":    {strconv.Itoa(FofaPageSize)},
+		}
+		if c.FofaEmail != "" {
+			params.Set("email", c.FofaEmail)
+		}
+		var resp struct {
+			Error   bool       `json:"error"`
+			ErrMsg  string     `json:"errmsg"`
+			Size    int        `json:"size"` // total results
+			Results [][]string `json:"results"`
+		}
+		err := sourceError("fofa", c.call(ctx, request{
+			source: SourceFofa,
+			method: http.MethodGet,
+			url:    strings.TrimRight(base, "/") + "/api/v1/search/all?" + params.Encode(),
+		}, &resp))
+		// FOFA reports most failures with HTTP 200
+		if err == nil && resp.Error {
+			err = errors.New("fofa API error: " + resp.ErrMsg)
+		}
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			return all, err
+		}
+		if page == 1 {
+			all.Total = resp.Size
+		}
+		for _, row := range resp.Results {
+			if len(row) == len(fofaFields) {
+				all.Matches = append(all.Matches, fofaMatch(row))
+			}
+		}
+		if len(resp.Results) < FofaPageSize || page*FofaPageSize >= resp.Size {
+			break
+		}
+	}
+	return all, nil
+}
+
+// fofaMatch converts a result row in the order of fofaFields to a Match
+func fofaMatch(row []string) Match {
+	field := func(name string) string {
+		for i, f := range fofaFields {
+			if f == name {
+				return row[i]
+			}
+		}
+		return ""
+	}
+
+	m := Match{
+		IPStr:     field("ip"),
+		Transport: field("base_protocol"),
+		Product:   field("server"),
+		Org:       field("as_organization"),
+		Location:  Location{CountryCode: field("country")},
+		Shodan:    ShodanMeta{Module: field("protocol")},
+	}
+	m.Port, _ = strconv.Atoi(field("port"))
+	if asn := field("as_number"); asn != "" && asn != "0" {
+		m.ASN = "AS" + asn
+	}
+
+	// host is a bare name, name:port or a URL
+	host := field("host")
+	if strings.Contains(host, "://") {
+		host = urlHost(host)
+	}
+	if host = NormalizeHostname(host); host != "" && host != m.IPStr {
+		m.Hostnames = append(m.Hostnames, host)
+	}
+
+	if cert := field("cert"); cert != "" {
+		m.addCertText(cert)
+	}
+	m.Hostnames = Unique(m.Hostnames)
+	return m
+}
+
+// fofaQuota returns the FOFA API queries left today
+func (c *Client) fofaQuota(ctx context.Context) (string, error) {
+	if !c.HasFofa() {
+		return "", ErrNotConfigured
+	}
+	params := url.Values{"key": {c.FofaKey}}
+	if c.FofaEmail != "" {
+		params.Set("email", c.FofaEmail)
+	}
+	var resp struct {
+		Error          bool   `json:"error"`
+		ErrMsg         string `json:"errmsg"`
+		RemainAPIQuery int    `json:"remain_api_query"`
+	}
+	err := sourceError("fofa", c.call(ctx, request{
+		source: SourceFofa,
+		method: http.MethodGet,
+		url:    strings.TrimRight(urlOr(c.FofaURL, DefaultFofaURL), "/") + "/api/v1/info/my?" + params.Encode(),
+	}, &resp))
+	if err == nil && resp.Error {
+		err = errors.New("fofa API error: " + resp.ErrMsg)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d queries left today", resp.RemainAPIQuery), nil
+}
@@ -0,0 +1,108 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	RegisterSource("hackertarget", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("hackertarget", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			c.logf("[*] Searching HackerTarget")
+			names, err := c.HackerTarget(ctx, domain)
+			send(SourceResult{Source: SourceHackerTarget, Names: names, Err: err})
+		})
+	})
+	RegisterSource("anubis", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("anubis", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			c.logf("[*] Searching Anubis-DB")
+			names, err := c.Anubis(ctx, domain)
+			send(SourceResult{Source: SourceAnubis, Names: names, Err: err})
+		})
+	})
+	RegisterSourceCheck("hackertarget", func(ctx context.Context, c *Client) (string, error) {
+		return "", c.ping(ctx, SourceHackerTarget, urlOr(c.HackerTargetURL, DefaultHackerTargetURL))
+	})
+	RegisterSourceCheck("anubis", func(ctx context.Context, c *Client) (string, error) {
+		return "", c.ping(ctx, SourceAnubis, urlOr(c.AnubisURL, DefaultAnubisURL))
+	})
+}
+
+// Endpoints of the free, keyless subdomain sources
+const (
+	DefaultHackerTargetURL = "https://api.hackertarget.com"
+	DefaultAnubisURL       = "https://jonlu.ca/anubis"
+)
+
+// Sources recorded for subdomains from the free sources
+const (
+	SourceHackerTarget = "hackertarget"
+	SourceAnubis       = "anubis"
+)
+
+// HackerTarget returns the names of domain and its subdomains found by the
+// HackerTarget host search. It needs no API key; the free quota is a few
+// dozen lookups a day.
+func (c *Client) HackerTarget(ctx context.Context, domain string) ([]string, error) {
+	base := c.HackerTargetURL
+	if base == "" {
+		base = DefaultHackerTargetURL
+	}
+	body, err := c.callRaw(ctx, request{
+		source: SourceHackerTarget,
+		method: http.MethodGet,
+		url:    strings.TrimRight(base, "/") + "/hostsearch/?" + url.Values{"q": {domain}}.Encode(),
+	})
+	if err != nil {
+		return nil, sourceError("hackertarget", err)
+	}
+
+	// One "name,ip" line per host; failures come back as a plain message
+	var names []string
+	for _, line := range strings.Split(string(body), "\n") {
+		name, _, ok := strings.Cut(strings.TrimSpace(line), ",")
+		if !ok {
+			if line = strings.TrimSpace(line); line != "" && len(names) == 0 {
+				return nil, errors.New("hackertarget: " + line)
+			}
+			continue
+		}
+		if name = NormalizeHostname(name); InDomain(name, domain) {
+			names = append(names, name)
+		}
+	}
+	return Unique(names), nil
+}
+
+// Anubis returns the names of domain and its subdomains in Anubis-DB. It
+// needs no API key.
+func (c *Client) Anubis(ctx context.Context, domain string) ([]string, error) {
+	base := c.AnubisURL
+	if base == "" {
+		base = DefaultAnubisURL
+	}
+	var list []string
+	err := c.call(ctx, request{
+		source: SourceAnubis,
+		method: http.MethodGet,
+		url:    strings.TrimRight(base, "/") + "/subdomains/" + url.PathEscape(domain),
+	}, &list)
+	// Unknown domains are a 404 rather than an empty list
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, sourceError("anubis", err)
+	}
+	var names []string
+	for _, name := range list {
+		if name = NormalizeHostname(name); InDomain(name, domain) {
+			names = append(names, name)
+		}
+	}
+	return Unique(names), nil
+}
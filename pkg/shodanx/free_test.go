@@ -0,0 +1,71 @@
+package shodanx
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestHackerTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{"hosts", "www.example.com,192.0.2.1\nMAIL.example.com,192.0.2.2\nexample.org,192.0.2.3\n",
+			[]string{"www.example.com", "mail.example.com"}, false},
+		{"quota message", "API count exceeded - Increase Quota with Membership", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/hostsearch/" || r.URL.Query().Get("q") != "example.com" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.Write([]byte(tt.body))
+			})
+			c.HackerTargetURL = c.BaseURL
+			names, err := c.HackerTarget(context.Background(), "example.com")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("HackerTarget = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnubis(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []string
+	}{
+		{"names", http.StatusOK, `["www.example.com", "*.dev.example.com", "example.org"]`,
+			[]string{"www.example.com", "dev.example.com"}},
+		{"unknown domain", http.StatusNotFound, `{"error": "not found"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/subdomains/example.com" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			c.AnubisURL = c.BaseURL
+			names, err := c.Anubis(context.Background(), "example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Anubis = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
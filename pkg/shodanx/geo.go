@@ -0,0 +1,61 @@
+package shodanx
+
+import "sort"
+
+// GeoInfo is the network owner and location Shodan reports for an address.
+type GeoInfo struct {
+	ASN         string  `json:"asn,omitempty"`
+	Org         string  `json:"org,omitempty"`
+	ISP         string  `json:"isp,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"`
+	CountryName string  `json:"country_name,omitempty"`
+	City        string  `json:"city,omitempty"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+}
+
+// addGeo records the owner and location of a banner's address, keeping the
+// first values seen for each field
+func addGeo(geo map[string]*GeoInfo, m *Match) {
+	g := geo[m.IPStr]
+	if g == nil {
+		g = &GeoInfo{}
+		geo[m.IPStr] = g
+	}
+	set := func(field *string, v string) {
+		if *field == "" {
+			*field = v
+		}
+	}
+	set(&g.ASN, m.ASN)
+	set(&g.Org, m.Org)
+	set(&g.ISP, m.ISP)
+	set(&g.CountryCode, m.Location.CountryCode)
+	set(&g.CountryName, m.Location.CountryName)
+	set(&g.City, m.Location.City)
+	if g.Latitude == 0 && g.Longitude == 0 {
+		g.Latitude, g.Longitude = m.Location.Latitude, m.Location.Longitude
+	}
+}
+
+// MatchedAddresses returns the sorted addresses of every matched service
+func (r *Result) MatchedAddresses() []string {
+	addrs := make([]string, 0, len(r.Geo))
+	for ip := range r.Geo {
+		addrs = append(addrs, ip)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// HostAddresses returns the sorted addresses of a hostname, both resolved
+// and seen by Shodan
+func (r *Result) HostAddresses(name string) []string {
+	addrs := append([]string(nil), r.IPs[name]...)
+	for _, svc := range r.Services[name] {
+		addrs = append(addrs, svc.IP)
+	}
+	addrs = Unique(addrs)
+	sort.Strings(addrs)
+	return addrs
+}
@@ -0,0 +1,42 @@
+package shodanx
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// HoneypotThreshold is the honeyscore from which a host is flagged as a likely honeypot.
+const HoneypotThreshold = 0.5
+
+// Honeyscore returns Shodan's probability (0.0 to 1.0) that ip is a honeypot
+func (c *Client) Honeyscore(ctx context.Context, ip string) (float64, error) {
+	var score float64
+	if err := c.getJSON(ctx, "/labs/honeyscore/"+url.PathEscape(ip), nil, &score); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// Honeyscores checks every address using workers concurrent lookups.
+// Addresses that could not be scored are left out.
+func (c *Client) Honeyscores(ctx context.Context, ips []string, workers int) map[string]float64 {
+	var mu sync.Mutex
+	scores := map[string]float64{}
+	forEach(ctx, ips, workers, func(ip string) {
+		score, err := c.Honeyscore(ctx, ip)
+		if err != nil {
+			c.logf("[!] Honeyscore %s: %v", ip, err)
+			return
+		}
+		mu.Lock()
+		scores[ip] = score
+		mu.Unlock()
+	})
+	return scores
+}
+
+// IsHoneypot reports whether a honeyscore reaches HoneypotThreshold
+func IsHoneypot(score float64) bool {
+	return score >= HoneypotThreshold
+}
@@ -0,0 +1,160 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Host is the response of /shodan/host/{ip}: everything Shodan knows about an IP.
+type Host struct {
+	IPStr       string   `json:"ip_str"`
+	Hostnames   []string `json:"hostnames"`
+	Domains     []string `json:"domains"`
+	Org         string   `json:"org"`
+	ISP         string   `json:"isp"`
+	ASN         string   `json:"asn"`
+	OS          string   `json:"os"`
+	Ports       []int    `json:"ports"`
+	Vulns       []string `json:"vulns"`
+	Tags        []string `json:"tags"`
+	City        string   `json:"city"`
+	CountryCode string   `json:"country_code"`
+	CountryName string   `json:"country_name"`
+	LastUpdate  string   `json:"last_update"`
+
+	// Data holds one banner per open service
+	Data []Match `json:"data"`
+}
+
+// Host returns the open ports, banners, hostnames and vulnerabilities Shodan
+// has recorded for ip.
+func (c *Client) Host(ctx context.Context, ip string) (*Host, error) {
+	return c.host(ctx, ip, nil)
+}
+
+// HostHistory is like Host but Data contains every banner Shodan has ever
+// collected for ip, not just the latest one per service.
+func (c *Client) HostHistory(ctx context.Context, ip string) (*Host, error) {
+	return c.host(ctx, ip, url.Values{"history": {"true"}})
+}
+
+// DefaultBulkSize is the number of IPs sent per bulk host lookup.
+const DefaultBulkSize = 100
+
+// Hosts looks up many IPs using the comma-separated bulk form of
+// /shodan/host/, batchSize IPs per request (enterprise keys only). If the key
+// may not use bulk lookups it falls back to one request per IP. IPs unknown
+// to Shodan are left out of the result.
+func (c *Client) Hosts(ctx context.Context, ips []string, batchSize int) ([]Host, error) {
+	if batchSize < 1 {
+		batchSize = DefaultBulkSize
+	}
+
+	var hosts []Host
+	bulk := true
+	for _, batch := range batches(ips, batchSize) {
+		var found []Host
+		var err error
+		if bulk && len(batch) > 1 {
+			err = c.getJSON(ctx, "/shodan/host/"+url.PathEscape(strings.Join(batch, ",")), nil, &found)
+			if bulkUnsupported(err) {
+				c.logf("[!] Bulk host lookup unavailable (%v), falling back to single lookups", err)
+				bulk = false
+			}
+		}
+		if !bulk || len(batch) == 1 {
+			found, err = c.hostsOneByOne(ctx, batch)
+		}
+		hosts = append(hosts, found...)
+		if err != nil {
+			return hosts, err
+		}
+	}
+	return hosts, nil
+}
+
+// Look up IPs one request at a time, skipping the ones Shodan doesn't know
+func (c *Client) hostsOneByOne(ctx context.Context, ips []string) ([]Host, error) {
+	var hosts []Host
+	for _, ip := range ips {
+		host, err := c.Host(ctx, ip)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return hosts, err
+		}
+		hosts = append(hosts, *host)
+	}
+	return hosts, nil
+}
+
+// bulkUnsupported reports whether a bulk lookup was refused for the plan rather than failing outright
+func bulkUnsupported(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden ||
+		apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusBadRequest) &&
+		!errors.Is(err, ErrNoCredits)
+}
+
+func (c *Client) host(ctx context.Context, ip string, params url.Values) (*Host, error) {
+	var host Host
+	if err := c.getJSON(ctx, "/shodan/host/"+url.PathEscape(ip), params, &host); err != nil {
+		return nil, err
+	}
+	return &host, nil
+}
+
+// ServiceHistory is the timeline of one port/transport on a host.
+type ServiceHistory struct {
+	Port      int
+	Transport string
+	FirstSeen string
+	LastSeen  string
+
+	// Changes holds the banners at which product or version changed, oldest first
+	Changes []Match
+}
+
+// History groups the banners of a host by service and orders them in time,
+// keeping only the banners where the product or version changed.
+func (h *Host) History() []ServiceHistory {
+	byService := map[string][]Match{}
+	var keys []string
+	for _, m := range h.Data {
+		key := fmt.Sprintf("%d/%s", m.Port, m.Transport)
+		if _, ok := byService[key]; !ok {
+			keys = append(keys, key)
+		}
+		byService[key] = append(byService[key], m)
+	}
+
+	var history []ServiceHistory
+	for _, key := range keys {
+		banners := byService[key]
+		// Shodan timestamps are ISO 8601, so they sort lexically
+		sort.Slice(banners, func(i, j int) bool { return banners[i].Timestamp < banners[j].Timestamp })
+
+		sh := ServiceHistory{
+			Port:      banners[0].Port,
+			Transport: banners[0].Transport,
+			FirstSeen: banners[0].Timestamp,
+			LastSeen:  banners[len(banners)-1].Timestamp,
+		}
+		for i, b := range banners {
+			if i == 0 || b.Product != banners[i-1].Product || b.Version != banners[i-1].Version {
+				sh.Changes = append(sh.Changes, b)
+			}
+		}
+		history = append(history, sh)
+	}
+
+	sort.Slice(history, func(i, j int) bool { return history[i].Port < history[j].Port })
+	return history
+}
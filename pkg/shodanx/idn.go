@@ -0,0 +1,26 @@
+package shodanx
+
+import "golang.org/x/net/idna"
+
+// ToASCII converts an internationalised hostname such as münchen.example.de
+// to its punycode form (xn--mnchen-3ya.example.de). Names that are not valid
+// IDNs, e.g. wildcards or names with underscores, are converted label by
+// label without validation.
+func ToASCII(name string) string {
+	if s, err := idna.Lookup.ToASCII(name); err == nil {
+		return s
+	}
+	if s, err := idna.Punycode.ToASCII(name); err == nil {
+		return s
+	}
+	return name
+}
+
+// ToUnicode converts the punycode labels of a hostname back to Unicode for
+// display, returning name unchanged if it cannot be decoded
+func ToUnicode(name string) string {
+	if s, err := idna.Punycode.ToUnicode(name); err == nil {
+		return s
+	}
+	return name
+}
@@ -0,0 +1,26 @@
+package shodanx
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"example.com", "example.com"},
+		{"münchen.example.de", "xn--mnchen-3ya.example.de"},
+		{"xn--mnchen-3ya.example.de", "xn--mnchen-3ya.example.de"},
+		{"_sip._tcp.münchen.de", "_sip._tcp.xn--mnchen-3ya.de"},
+		{"*.example.com", "*.example.com"},
+	}
+	for _, tt := range tests {
+		if got := ToASCII(tt.name); got != tt.want {
+			t.Errorf("ToASCII(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	if got := ToUnicode("xn--mnchen-3ya.example.de"); got != "münchen.example.de" {
+		t.Errorf("ToUnicode = %q, want münchen.example.de", got)
+	}
+}
@@ -0,0 +1,71 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// DefaultInternetDBURL is the free InternetDB endpoint.
+const DefaultInternetDBURL = "https://internetdb.shodan.io"
+
+// DefaultInternetDBRate is the InternetDB request rate used by NewClient.
+const DefaultInternetDBRate = 5.0
+
+// InternetDBHost is the InternetDB summary of an IP: open ports, CPEs,
+// hostnames, tags and known vulnerabilities.
+type InternetDBHost struct {
+	IP        string   `json:"ip"`
+	Ports     []int    `json:"ports"`
+	CPEs      []string `json:"cpes"`
+	Hostnames []string `json:"hostnames"`
+	Tags      []string `json:"tags"`
+	Vulns     []string `json:"vulns"`
+}
+
+// InternetDB looks up ip in the free InternetDB. It needs no API key and
+// costs no credits. IPs InternetDB knows nothing about return nil, nil.
+func (c *Client) InternetDB(ctx context.Context, ip string) (*InternetDBHost, error) {
+	base := c.InternetDBURL
+	if base == "" {
+		base = DefaultInternetDBURL
+	}
+
+	var host InternetDBHost
+	err := c.call(ctx, request{
+		method:  http.MethodGet,
+		url:     strings.TrimRight(base, "/") + "/" + url.PathEscape(ip),
+		limiter: c.InternetDBLimiter,
+	}, &host)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &host, nil
+}
+
+// LookupInternetDB looks each address up in InternetDB using workers
+// concurrent lookups. Addresses InternetDB doesn't know are left out.
+func (c *Client) LookupInternetDB(ctx context.Context, ips []string, workers int) map[string]*InternetDBHost {
+	var mu sync.Mutex
+	hosts := map[string]*InternetDBHost{}
+	forEach(ctx, ips, workers, func(ip string) {
+		host, err := c.InternetDB(ctx, ip)
+		if err != nil {
+			c.logf("[!] InternetDB %s: %v", ip, err)
+			return
+		}
+		if host != nil {
+			mu.Lock()
+			hosts[ip] = host
+			mu.Unlock()
+		}
+	})
+	return hosts
+}
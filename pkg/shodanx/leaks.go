@@ -0,0 +1,80 @@
+package shodanx
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Leak is a certificate name that looks like internal infrastructure.
+type Leak struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+
+	// Seen lists the services ("ip:port") whose certificates name it
+	Seen []string `json:"seen"`
+}
+
+// Reasons a certificate name is reported as a leak
+const (
+	LeakPrivateTLD = "private TLD"
+	LeakUnresolved = "does not resolve publicly"
+)
+
+// IsPrivateName reports whether name ends in a top-level domain that is not
+// delegated on the internet, such as .local, .corp, .internal or .lan
+func IsPrivateName(name string) bool {
+	name = strings.ToLower(strings.Trim(name, "."))
+	if name == "" || net.ParseIP(name) != nil {
+		return false
+	}
+	// Unknown TLDs come back as a single label outside the ICANN section
+	suffix, icann := publicsuffix.PublicSuffix(name)
+	return !icann && !strings.Contains(suffix, ".")
+}
+
+// addCertNames records the names in a banner's certificate subject
+func addCertNames(names map[string][]string, m *Match) {
+	if m.SSL == nil {
+		return
+	}
+	seen := net.JoinHostPort(m.IPStr, strconv.Itoa(m.Port))
+	for _, v := range m.SSL.Cert.Subject {
+		if !strings.Contains(v, ".") {
+			continue
+		}
+		if name := NormalizeHostname(v); name != "" && !contains(names[name], seen) {
+			names[name] = append(names[name], seen)
+		}
+	}
+}
+
+// FindLeaks reports the certificate names that use a private TLD and, once
+// the subdomains are resolved, those under the domain that don't resolve.
+// Results loaded from JSON keep their Leaks, as the certificates are gone.
+func (r *Result) FindLeaks() {
+	if r.certNames == nil {
+		return
+	}
+	unresolved := map[string]bool{}
+	for _, name := range r.Unresolved {
+		unresolved[name] = true
+	}
+	r.Leaks = nil
+	for name, seen := range r.certNames {
+		reason := ""
+		switch {
+		case IsPrivateName(name):
+			reason = LeakPrivateTLD
+		case unresolved[name]:
+			reason = LeakUnresolved
+		default:
+			continue
+		}
+		r.Leaks = append(r.Leaks, Leak{Name: name, Reason: reason, Seen: seen})
+	}
+	sort.Slice(r.Leaks, func(i, j int) bool { return r.Leaks[i].Name < r.Leaks[j].Name })
+}
@@ -0,0 +1,180 @@
+package shodanx
+
+import (
+	"context"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MailRecords are the email DNS records of a name and what they reveal.
+type MailRecords struct {
+	MX    []string `json:"mx,omitempty"`
+	TXT   []string `json:"txt,omitempty"`
+	SPF   string   `json:"spf,omitempty"`
+	DMARC string   `json:"dmarc,omitempty"`
+
+	// ThirdParty lists the hosts outside the domain that the MX, SPF and
+	// DMARC records point at, e.g. mail providers and report collectors
+	ThirdParty []string `json:"third_party,omitempty"`
+
+	// Issues describes weak or missing email security settings
+	Issues []string `json:"issues,omitempty"`
+}
+
+// MailRecords looks up the MX, TXT and DMARC records of every name and
+// checks their SPF and DMARC policies. Names without any of them are left
+// out, except domain itself, whose missing records are reported as issues.
+func (r *DNSResolver) MailRecords(ctx context.Context, domain string, names []string) map[string]*MailRecords {
+	resolver := r.resolver()
+	var mu sync.Mutex
+	records := map[string]*MailRecords{}
+	forEach(ctx, Unique(append([]string{domain}, names...)), r.Workers, func(name string) {
+		rec := &MailRecords{}
+		if mxs, err := resolver.LookupMX(ctx, name); err == nil {
+			for _, mx := range mxs {
+				if host := strings.TrimSuffix(mx.Host, "."); host != "" {
+					rec.MX = append(rec.MX, host)
+				}
+			}
+		}
+		if txts, err := resolver.LookupTXT(ctx, name); err == nil {
+			rec.TXT = txts
+		}
+		if txts, err := resolver.LookupTXT(ctx, "_dmarc."+name); err == nil {
+			for _, t := range txts {
+				if strings.HasPrefix(strings.ToLower(t), "v=dmarc1") {
+					rec.DMARC = t
+				}
+			}
+		}
+		if name != domain && len(rec.MX) == 0 && len(rec.TXT) == 0 && rec.DMARC == "" {
+			return
+		}
+		rec.check(domain)
+		mu.Lock()
+		records[name] = rec
+		mu.Unlock()
+	})
+	return records
+}
+
+// check finds the SPF record, the third-party hosts and the weaknesses of
+// the records of a name under domain
+func (rec *MailRecords) check(domain string) {
+	var spfs []string
+	for _, t := range rec.TXT {
+		if strings.HasPrefix(strings.ToLower(t), "v=spf1") {
+			spfs = append(spfs, t)
+		}
+	}
+	var hosts []string
+	hosts = append(hosts, rec.MX...)
+
+	switch len(spfs) {
+	case 0:
+		// A name that can't send mail should still publish "v=spf1 -all"
+		rec.Issues = append(rec.Issues, "no SPF record")
+	case 1:
+		rec.SPF = spfs[0]
+		spfHosts, issues := checkSPF(rec.SPF)
+		hosts = append(hosts, spfHosts...)
+		rec.Issues = append(rec.Issues, issues...)
+	default:
+		rec.SPF = spfs[0]
+		rec.Issues = append(rec.Issues, "multiple SPF records, receivers treat SPF as failed")
+	}
+
+	if rec.DMARC == "" {
+		rec.Issues = append(rec.Issues, "no DMARC record")
+	} else {
+		dmarcHosts, issues := checkDMARC(rec.DMARC)
+		hosts = append(hosts, dmarcHosts...)
+		rec.Issues = append(rec.Issues, issues...)
+	}
+
+	for _, h := range hosts {
+		if h = NormalizeHostname(h); h != "" && !InDomain(h, domain) {
+			rec.ThirdParty = append(rec.ThirdParty, h)
+		}
+	}
+	rec.ThirdParty = Unique(rec.ThirdParty)
+	sort.Strings(rec.ThirdParty)
+}
+
+// checkSPF returns the domains an SPF record includes and its weaknesses
+func checkSPF(spf string) (hosts, issues []string) {
+	all := ""
+	for _, term := range strings.Fields(spf)[1:] {
+		lower := strings.ToLower(term)
+		mech := strings.TrimLeft(lower, "+-~?")
+		switch {
+		case mech == "all":
+			all = strings.TrimSuffix(lower, "all")
+		case strings.HasPrefix(mech, "include:"), strings.HasPrefix(mech, "exists:"),
+			strings.HasPrefix(mech, "a:"), strings.HasPrefix(mech, "mx:"):
+			_, target, _ := strings.Cut(mech, ":")
+			hosts = append(hosts, spfDomain(target))
+		case strings.HasPrefix(mech, "redirect="):
+			hosts = append(hosts, spfDomain(strings.TrimPrefix(mech, "redirect=")))
+			all = "redirect"
+		}
+	}
+	switch all {
+	case "":
+		issues = append(issues, "SPF has no all mechanism, so any sender passes as neutral")
+	case "+":
+		issues = append(issues, "SPF ends in +all, so any sender passes")
+	case "?":
+		issues = append(issues, "SPF ends in ?all (neutral), so spoofed mail is not rejected")
+	case "~":
+		issues = append(issues, "SPF ends in ~all (softfail), so spoofed mail is usually only marked")
+	}
+	return hosts, issues
+}
+
+// spfDomain drops the CIDR suffix and macros of an SPF domain spec
+func spfDomain(spec string) string {
+	spec, _, _ = strings.Cut(spec, "/")
+	if strings.Contains(spec, "%{") {
+		return ""
+	}
+	return spec
+}
+
+// checkDMARC returns the report destinations of a DMARC record and its weaknesses
+func checkDMARC(dmarc string) (hosts, issues []string) {
+	tags := map[string]string{}
+	for _, part := range strings.Split(dmarc, ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+			tags[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+		}
+	}
+	switch strings.ToLower(tags["p"]) {
+	case "none":
+		issues = append(issues, "DMARC policy is p=none, so failing mail is delivered")
+	case "quarantine", "reject":
+	default:
+		issues = append(issues, "DMARC record has no valid policy")
+	}
+	if pct, ok := tags["pct"]; ok && pct != "100" {
+		issues = append(issues, "DMARC applies to only "+pct+"% of mail")
+	}
+	for _, key := range []string{"rua", "ruf"} {
+		for _, uri := range strings.Split(tags[key], ",") {
+			uri = strings.TrimSpace(uri)
+			if !strings.HasPrefix(strings.ToLower(uri), "mailto:") {
+				continue
+			}
+			addr, err := mail.ParseAddress(strings.SplitN(uri[len("mailto:"):], "!", 2)[0])
+			if err != nil {
+				continue
+			}
+			if _, host, ok := strings.Cut(addr.Address, "@"); ok {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts, issues
+}
@@ -0,0 +1,22 @@
+package shodanx
+
+import "context"
+
+// Ports returns the ports Shodan's crawlers are looking for
+func (c *Client) Ports(ctx context.Context) ([]int, error) {
+	var ports []int
+	if err := c.getJSON(ctx, "/shodan/ports", nil, &ports); err != nil {
+		return nil, err
+	}
+	return ports, nil
+}
+
+// Protocols returns the protocols usable for on-demand scans, keyed by name
+// with a short description
+func (c *Client) Protocols(ctx context.Context) (map[string]string, error) {
+	var protocols map[string]string
+	if err := c.getJSON(ctx, "/shodan/protocols", nil, &protocols); err != nil {
+		return nil, err
+	}
+	return protocols, nil
+}
@@ -0,0 +1,64 @@
+package shodanx
+
+import (
+	"context"
+	"net/url"
+)
+
+// Notifier is a Shodan notification channel (email, Slack, webhook, ...).
+type Notifier struct {
+	ID          string            `json:"id"`
+	Provider    string            `json:"provider"`
+	Description string            `json:"description"`
+	Args        map[string]string `json:"args"`
+}
+
+// NotifierProvider describes a notification provider and the arguments it requires.
+type NotifierProvider struct {
+	Required []string `json:"required"`
+}
+
+// Notifiers lists the notifiers of the account
+func (c *Client) Notifiers(ctx context.Context) ([]Notifier, error) {
+	var res struct {
+		Matches []Notifier `json:"matches"`
+	}
+	if err := c.getJSON(ctx, "/notifier", nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Matches, nil
+}
+
+// NotifierProviders returns the available providers keyed by name
+func (c *Client) NotifierProviders(ctx context.Context) (map[string]NotifierProvider, error) {
+	var providers map[string]NotifierProvider
+	if err := c.getJSON(ctx, "/notifier/provider", nil, &providers); err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// CreateNotifier creates a notifier for provider with its provider-specific
+// arguments (e.g. "to" for email, "url" for webhook) and returns its ID
+func (c *Client) CreateNotifier(ctx context.Context, provider, description string, args map[string]string) (string, error) {
+	form := url.Values{}
+	form.Set("provider", provider)
+	form.Set("description", description)
+	for k, v := range args {
+		form.Set(k, v)
+	}
+
+	var res struct {
+		ID      string `json:"id"`
+		Success bool   `json:"success"`
+	}
+	if err := c.postForm(ctx, "/notifier", form, &res); err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// DeleteNotifier removes a notifier
+func (c *Client) DeleteNotifier(ctx context.Context, id string) error {
+	return c.delete(ctx, "/notifier/"+url.PathEscape(id))
+}
@@ -0,0 +1,228 @@
+package shodanx
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+)
+
+// OrgQueries returns the queries that find services of an organisation by
+// its Shodan org name and the organisation in TLS certificate subjects.
+func OrgQueries(org string) []string {
+	return []string{
+		filter("org", org),
+		filter("ssl.cert.subject.o", org),
+	}
+}
+
+// ASNQueries returns one query per autonomous system, matching every service
+// Shodan has seen on its networks
+func ASNQueries(asns ...string) []string {
+	queries := make([]string, len(asns))
+	for i, a := range asns {
+		queries[i] = filter("asn", NormalizeASN(a))
+	}
+	return queries
+}
+
+// CIDRQueries returns one query per network range, matching every service
+// Shodan has seen in it
+func CIDRQueries(cidrs ...string) []string {
+	queries := make([]string, len(cidrs))
+	for i, cidr := range cidrs {
+		queries[i] = filter("net", cidr)
+	}
+	return queries
+}
+
+// NormalizeASN returns an AS number in Shodan's "AS12345" form
+func NormalizeASN(asn string) string {
+	asn = strings.ToUpper(strings.TrimSpace(asn))
+	if !strings.HasPrefix(asn, "AS") {
+		asn = "AS" + asn
+	}
+	return asn
+}
+
+// PivotOptions tunes a pivot run.
+type PivotOptions struct {
+	// MaxPages limits the result pages fetched per query. Zero or less fetches all pages.
+	MaxPages int
+
+	// Filters is appended to every query, see EnumerateOptions.Filters
+	Filters string
+
+	// Hosts lists addresses looked up with Hosts, adding the hostnames and
+	// domains Shodan has seen on them
+	Hosts []string
+
+	// Reverse lists IPv4 ranges (see ExpandCIDR) whose addresses are also
+	// looked up through /dns/reverse, finding hosts without open services
+	Reverse []string
+
+	// OnMatch is called with every distinct banner, see EnumerateOptions.OnMatch
+	OnMatch func(m *Match)
+}
+
+// Sources recorded for hostnames found by looking up addresses: Shodan host
+// records, Shodan's /dns/reverse and local PTR lookups
+const (
+	SourceHost       = "host"
+	SourceReverseDNS = "reverse-dns"
+	SourcePTR        = "ptr"
+)
+
+// Options returns the EnumerateOptions matching a pivot over queries, e.g. for Estimate
+func (o PivotOptions) Options(queries []string) EnumerateOptions {
+	return EnumerateOptions{Queries: queries, MaxPages: o.MaxPages, Filters: o.Filters, SkipDNS: true}
+}
+
+// Pivot runs queries that describe an organisation, network or range rather
+// than a domain, then looks up the addresses in opts.Hosts and opts.Reverse. The result holds the hostnames seen on the matched services
+// in Subdomains, the registered domains in Domains, the addresses of each
+// hostname in IPs and the matched addresses grouped by netblock in Netblocks.
+// Like Enumerate, the partial result is returned on fatal errors and when
+// ctx is cancelled.
+func (c *Client) Pivot(ctx context.Context, target string, queries []string, opts PivotOptions) (*Result, error) {
+	if len(queries) > 0 {
+		queries = opts.Options(queries).queries(target)
+	}
+
+	result := &Result{Domain: target, Queries: queries, Subdomains: []string{}, IPs: map[string][]string{}}
+	facets := newFacetCounter()
+	facets.onMatch = opts.OnMatch
+	var domains, addrs []string
+	partial := func() *Result {
+		for name, list := range result.IPs {
+			sort.Strings(list)
+			result.IPs[name] = Unique(list)
+		}
+		// Shodan's domains plus the registrable domains of every hostname
+		for apex := range GroupByDomain(result.Subdomains) {
+			domains = append(domains, apex)
+		}
+		result.Domains = Unique(domains)
+		sort.Strings(result.Domains)
+		result.Netblocks = Netblocks(addrs)
+		result.Summary = facets.top(SummaryTop)
+		result.Services = sortServices(facets.services)
+		result.Certificates = facets.certs
+		result.Vulns = facets.hostVulns()
+		result.Geo = facets.hostGeo()
+		result.Exposed = facets.exposed
+		sortExposures(result.Exposed)
+		result.certNames = facets.names
+		result.FindLeaks()
+		return result
+	}
+	// Keyed like Subdomains, see AddHostnames
+	addIP := func(name, ip string) {
+		if name = NormalizeHostname(name); name != "" && net.ParseIP(name) == nil {
+			result.IPs[name] = append(result.IPs[name], ip)
+		}
+	}
+	add := func(q string, res *SearchResult) {
+		result.AddHostnames(q, res.Hostnames()...)
+		facets.add(res.Matches)
+		for _, m := range res.Matches {
+			domains = append(domains, m.Domains...)
+			addrs = append(addrs, m.IPStr)
+			for _, h := range m.Hostnames {
+				addIP(h, m.IPStr)
+			}
+		}
+	}
+
+	for _, q := range queries {
+		if ctx.Err() != nil {
+			return partial(), ctx.Err()
+		}
+		c.logf("[*] Query: %s", q)
+		res, err := c.SearchAll(ctx, q, opts.MaxPages)
+		if res != nil {
+			add(q, res)
+		}
+		if IsFatal(err) {
+			return partial(), err
+		}
+		if err != nil {
+			c.logf("[!] %v", err)
+			continue
+		}
+		if len(res.Matches) < res.Total {
+			c.logf("[!] Fetched %d of %d results for %s", len(res.Matches), res.Total, q)
+		}
+	}
+
+	if len(opts.Hosts) > 0 && ctx.Err() == nil {
+		c.logf("[*] Host lookups: %d addresses", len(opts.Hosts))
+		hosts, err := c.Hosts(ctx, opts.Hosts, DefaultBulkSize)
+		for _, h := range hosts {
+			result.AddHostnames(SourceHost, h.Hostnames...)
+			domains = append(domains, h.Domains...)
+			addrs = append(addrs, h.IPStr)
+			for _, name := range h.Hostnames {
+				addIP(name, h.IPStr)
+			}
+		}
+		if IsFatal(err) {
+			return partial(), err
+		}
+		if err != nil && ctx.Err() == nil {
+			c.logf("[!] Host lookups: %v", err)
+		}
+	}
+
+	for _, cidr := range opts.Reverse {
+		if ctx.Err() != nil {
+			return partial(), ctx.Err()
+		}
+		ips, err := ExpandCIDR(cidr)
+		if err != nil {
+			c.logf("[!] %v", err)
+			continue
+		}
+		c.logf("[*] Reverse DNS: %s (%d addresses)", cidr, len(ips))
+		names, err := c.Reverse(ctx, ips)
+		for ip, hosts := range names {
+			result.AddHostnames(SourceReverseDNS, hosts...)
+			addrs = append(addrs, ip)
+			for _, h := range hosts {
+				addIP(h, ip)
+			}
+		}
+		if IsFatal(err) {
+			return partial(), err
+		}
+		if err != nil {
+			c.logf("[!] Reverse DNS: %v", err)
+		}
+	}
+	return partial(), ctx.Err()
+}
+
+// Netblocks groups addresses by their /24 (IPv4) or /64 (IPv6) network and
+// returns the sorted, distinct addresses of each block
+func Netblocks(addrs []string) map[string][]string {
+	blocks := map[string][]string{}
+	for _, a := range Unique(addrs) {
+		ip := net.ParseIP(a)
+		if ip == nil {
+			continue
+		}
+		mask := net.CIDRMask(64, 128)
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, mask = ip4, net.CIDRMask(24, 32)
+		}
+		block := (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+		blocks[block] = append(blocks[block], a)
+	}
+	for _, list := range blocks {
+		sort.Strings(list)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return blocks
+}
@@ -0,0 +1,171 @@
+package shodanx
+
+import (
+	"context"
+	"crypto/tls"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Probe is the HTTP response of a web server found on a hostname.
+type Probe struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Title      string `json:"title,omitempty"`
+	Server     string `json:"server,omitempty"`
+	Location   string `json:"location,omitempty"`
+	WAF        string `json:"waf,omitempty"`
+
+	// FaviconHash is the Shodan favicon hash of the site's icon, see FaviconHash
+	FaviconHash int32  `json:"favicon_hash,omitempty"`
+	FaviconURL  string `json:"favicon_url,omitempty"`
+
+	// Screenshot is the path of a PNG of the page, see Screenshotter
+	Screenshot string `json:"screenshot,omitempty"`
+
+	// Cert is set if the server presented an expired or self-signed certificate
+	Cert *CertFinding `json:"cert,omitempty"`
+
+	// Header holds the response headers
+	Header http.Header `json:"-"`
+
+	body []byte
+}
+
+// Prober checks hostnames for HTTP and HTTPS servers.
+type Prober struct {
+	// HTTPClient must not follow redirects; nil uses a client with Timeout
+	// that skips certificate verification
+	HTTPClient *http.Client
+
+	// Workers is the number of concurrent probes
+	Workers int
+
+	// Timeout is the per-request timeout of the default client
+	Timeout time.Duration
+
+	// WAF sends a second, attack-like request to hosts whose response names
+	// no firewall and records one if that request is blocked
+	WAF bool
+
+	// Favicon downloads the icon of every site found and records its hash
+	Favicon bool
+}
+
+// DefaultProbeTimeout is the per-request timeout used when Prober.Timeout is zero.
+const DefaultProbeTimeout = 10 * time.Second
+
+// wafProbeQuery is an obviously malicious query string that firewalls block
+const wafProbeQuery = "?id=1%20AND%201=1%20UNION%20SELECT%20NULL--&q=%3Cscript%3Ealert(1)%3C/script%3E&file=../../../../etc/passwd"
+
+// maxTitleBody is how much of a response is read to find the page title
+const maxTitleBody = 64 * 1024
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Probe requests https:// and then http:// on every hostname and returns the
+// first response per name, like httpx. Names without a web server are left out.
+func (p *Prober) Probe(ctx context.Context, hostnames []string) map[string]*Probe {
+	client := p.client()
+	var mu sync.Mutex
+	probes := map[string]*Probe{}
+	forEach(ctx, hostnames, p.Workers, func(name string) {
+		for _, scheme := range []string{"https", "http"} {
+			probe, err := probeURL(ctx, client, scheme+"://"+name+"/")
+			if err != nil {
+				continue
+			}
+			if p.WAF && probe.WAF == "" {
+				probe.WAF = provokeWAF(ctx, client, probe)
+			}
+			if p.Favicon {
+				iconURL := faviconURL(probe.URL, probe.body)
+				if icon := fetchFavicon(ctx, client, iconURL); icon != nil {
+					probe.FaviconHash, probe.FaviconURL = FaviconHash(icon), iconURL
+				}
+			}
+			probe.body = nil
+			mu.Lock()
+			probes[name] = probe
+			mu.Unlock()
+			return
+		}
+	})
+	return probes
+}
+
+func (p *Prober) client() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Recon targets often serve invalid or self-signed certificates
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Fetch a single URL and describe the response
+func probeURL(ctx context.Context, client *http.Client, rawURL string) (*Probe, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxTitleBody))
+	probe := &Probe{
+		URL:        rawURL,
+		StatusCode: resp.StatusCode,
+		Server:     resp.Header.Get("Server"),
+		Location:   resp.Header.Get("Location"),
+		Header:     resp.Header,
+		body:       body,
+	}
+	probe.WAF = DetectWAF(resp.Header, body)
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		if probe.Cert = probeCertFinding(resp.TLS.PeerCertificates[0], time.Now()); probe.Cert != nil {
+			probe.Cert.Hostnames = []string{req.URL.Hostname()}
+			probe.Cert.Port = 443
+			if port, err := strconv.Atoi(req.URL.Port()); err == nil {
+				probe.Cert.Port = port
+			}
+		}
+	}
+	if m := titleRe.FindSubmatch(body); m != nil {
+		probe.Title = strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	}
+	return probe, nil
+}
+
+// provokeWAF repeats a probe with an attack-like query and returns the
+// firewall that blocks it, or "" if it isn't blocked
+func provokeWAF(ctx context.Context, client *http.Client, probe *Probe) string {
+	blocked, err := probeURL(ctx, client, probe.URL+wafProbeQuery)
+	if err != nil || blocked.StatusCode == probe.StatusCode || !wafBlockStatus[blocked.StatusCode] {
+		return ""
+	}
+	if blocked.WAF != "" {
+		return blocked.WAF
+	}
+	return UnknownWAF
+}
@@ -0,0 +1,66 @@
+package shodanx
+
+import "fmt"
+
+// Profile is a named enumeration preset that trades query credits for coverage.
+type Profile struct {
+	Name        string
+	Description string
+
+	// Queries returns the queries of the profile; nil uses DefaultQueries
+	Queries func(domain string) []string
+
+	// MaxPages, PreCount and IncludeBroad are copied into EnumerateOptions
+	MaxPages     int
+	PreCount     bool
+	IncludeBroad bool
+}
+
+// Profiles lists the built-in profiles. "standard" matches the defaults of
+// EnumerateOptions used by the CLI.
+var Profiles = []Profile{
+	{
+		Name:        "fast",
+		Description: "hostname query and DNS API only, first page",
+		Queries: func(domain string) []string {
+			return []string{filter("hostname", domain)}
+		},
+		MaxPages: 1,
+	},
+	{
+		Name:        "standard",
+		Description: "all built-in queries, first page",
+		MaxPages:    1,
+	},
+	{
+		Name:         "thorough",
+		Description:  "all built-in queries including the broad ones, every result page",
+		MaxPages:     0,
+		IncludeBroad: true,
+	},
+	{
+		Name:        "stealth",
+		Description: "all built-in queries, first page, skipping queries a free count shows to be empty",
+		MaxPages:    1,
+		PreCount:    true,
+	},
+}
+
+// LookupProfile returns the built-in profile with the given name
+func LookupProfile(name string) (Profile, error) {
+	for _, p := range Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("unknown profile %q", name)
+}
+
+// Options returns the EnumerateOptions of the profile for domain
+func (p Profile) Options(domain string) EnumerateOptions {
+	opts := EnumerateOptions{MaxPages: p.MaxPages, PreCount: p.PreCount, IncludeBroad: p.IncludeBroad}
+	if p.Queries != nil {
+		opts.Queries = p.Queries(domain)
+	}
+	return opts
+}
@@ -0,0 +1,60 @@
+package shodanx
+
+import "strings"
+
+// Query builds a Shodan search query out of filters and free-text terms,
+// quoting values so spaces, quotes and other special characters survive.
+type Query struct {
+	parts []string
+}
+
+// NewQuery returns an empty query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Filter adds name:"value"
+func (q *Query) Filter(name, value string) *Query {
+	q.parts = append(q.parts, name+":"+QuoteValue(value))
+	return q
+}
+
+// Not adds a negated filter, -name:"value"
+func (q *Query) Not(name, value string) *Query {
+	q.parts = append(q.parts, "-"+name+":"+QuoteValue(value))
+	return q
+}
+
+// Term adds a free-text search term, quoted if it contains whitespace or quotes
+func (q *Query) Term(term string) *Query {
+	if strings.ContainsAny(term, " \t\"") {
+		term = QuoteValue(term)
+	}
+	q.parts = append(q.parts, term)
+	return q
+}
+
+// Raw appends an already formatted query fragment unchanged
+func (q *Query) Raw(fragment string) *Query {
+	if fragment = strings.TrimSpace(fragment); fragment != "" {
+		q.parts = append(q.parts, fragment)
+	}
+	return q
+}
+
+// String returns the query text
+func (q *Query) String() string {
+	return strings.Join(q.parts, " ")
+}
+
+// QuoteValue wraps a filter value in double quotes, escaping embedded quotes and backslashes
+func QuoteValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
+
+// filter is shorthand for a single-filter query
+func filter(name, value string) string {
+	return NewQuery().Filter(name, value).String()
+}
@@ -0,0 +1,69 @@
+package shodanx
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// SavedQuery is a community search query from the Shodan query directory.
+type SavedQuery struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Query       string   `json:"query"`
+	Votes       int      `json:"votes"`
+	Timestamp   string   `json:"timestamp"`
+	Tags        []string `json:"tags"`
+}
+
+// SavedQueries is a page of the query directory.
+type SavedQueries struct {
+	Total   int          `json:"total"`
+	Matches []SavedQuery `json:"matches"`
+}
+
+// TagCount is a query directory tag and the number of queries using it.
+type TagCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchQueries searches the query directory for term. Pages start at 1.
+func (c *Client) SearchQueries(ctx context.Context, term string, page int) (*SavedQueries, error) {
+	params := url.Values{}
+	params.Set("query", term)
+	params.Set("page", strconv.Itoa(page))
+
+	var res SavedQueries
+	if err := c.getJSON(ctx, "/shodan/query/search", params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListQueries returns a page of saved queries sorted by sort ("votes" or "timestamp")
+func (c *Client) ListQueries(ctx context.Context, page int, sort string) (*SavedQueries, error) {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	if sort != "" {
+		params.Set("sort", sort)
+		params.Set("order", "desc")
+	}
+
+	var res SavedQueries
+	if err := c.getJSON(ctx, "/shodan/query", params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// QueryTags returns the size most popular query directory tags
+func (c *Client) QueryTags(ctx context.Context, size int) ([]TagCount, error) {
+	var res struct {
+		Matches []TagCount `json:"matches"`
+	}
+	if err := c.getJSON(ctx, "/shodan/query/tags", url.Values{"size": {strconv.Itoa(size)}}, &res); err != nil {
+		return nil, err
+	}
+	return res.Matches, nil
+}
@@ -0,0 +1,60 @@
+package shodanx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	RegisterSource("rapiddns", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("rapiddns", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			c.logf("[*] Searching RapidDNS")
+			names, err := c.RapidDNS(ctx, domain)
+			send(SourceResult{Source: SourceRapidDNS, Names: names, Err: err})
+		})
+	})
+	RegisterSourceCheck("rapiddns", func(ctx context.Context, c *Client) (string, error) {
+		return "", c.ping(ctx, SourceRapidDNS, urlOr(c.RapidDNSURL, DefaultRapidDNSURL))
+	})
+}
+
+// DefaultRapidDNSURL is the RapidDNS website, which has no API.
+const DefaultRapidDNSURL = "https://rapiddns.io"
+
+// DefaultRapidDNSRate is the RapidDNS request rate used by NewClient, kept
+// low since the pages are scraped.
+const DefaultRapidDNSRate = 0.5
+
+// SourceRapidDNS is the source recorded for subdomains from RapidDNS.
+const SourceRapidDNS = "rapiddns"
+
+// RapidDNS returns the names of domain and its subdomains listed on the
+// RapidDNS subdomain page, scraped from its HTML table.
+func (c *Client) RapidDNS(ctx context.Context, domain string) ([]string, error) {
+	base := c.RapidDNSURL
+	if base == "" {
+		base = DefaultRapidDNSURL
+	}
+	body, err := c.callRaw(ctx, request{
+		source:  SourceRapidDNS,
+		method:  http.MethodGet,
+		url:     strings.TrimRight(base, "/") + "/subdomain/" + url.PathEscape(domain) + "?full=1",
+		limiter: c.RapidDNSLimiter,
+	})
+	if err != nil {
+		return nil, sourceError("rapiddns", err)
+	}
+
+	// Any name under domain on the page, whether in the table or a link
+	re := regexp.MustCompile(`(?i)[a-z0-9_*](?:[a-z0-9_-]*\.)*` + regexp.QuoteMeta(domain) + `\b`)
+	var names []string
+	for _, name := range re.FindAllString(string(body), -1) {
+		if name = NormalizeHostname(name); InDomain(name, domain) {
+			names = append(names, name)
+		}
+	}
+	return Unique(names), nil
+}
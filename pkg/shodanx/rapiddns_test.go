@@ -0,0 +1,31 @@
+package shodanx
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRapidDNS(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subdomain/example.com" || r.URL.Query().Get("full") != "1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`<html><body><table>
+			<tr><td>www.example.com</td><td>192.0.2.1</td><td>A</td></tr>
+			<tr><td>API.Example.com</td><td>www.example.com</td><td>CNAME</td></tr>
+			<tr><td><a href="/subdomain/dev.example.com">dev.example.com</a></td></tr>
+			<tr><td>example.community</td><td>notexample.com</td></tr>
+		</table></body></html>`))
+	})
+	c.RapidDNSURL = c.BaseURL
+	c.RapidDNSLimiter = nil
+	names, err := c.RapidDNS(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"www.example.com", "api.example.com", "dev.example.com"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("RapidDNS = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,154 @@
+package shodanx
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ResolveHosts resolves every hostname with the system resolver using
+// workers concurrent lookups and returns the sorted addresses per name.
+// Names that don't resolve are left out.
+func ResolveHosts(ctx context.Context, hostnames []string, workers int) map[string][]string {
+	addrs := map[string][]string{}
+	for name, res := range (&DNSResolver{Workers: workers}).Resolve(ctx, hostnames) {
+		if len(res.Addresses) > 0 {
+			addrs[name] = res.Addresses
+		}
+	}
+	return addrs
+}
+
+// DNSResolver resolves hostnames concurrently, through the system resolver
+// or a list of DNS servers used in turn.
+type DNSResolver struct {
+	// Servers are "host" or "host:port" DNS servers; empty uses the system resolver
+	Servers []string
+
+	// Workers is the number of concurrent lookups
+	Workers int
+
+	next uint32
+}
+
+// Resolution is the outcome of resolving a single hostname.
+type Resolution struct {
+	// Addresses are the sorted A and AAAA addresses; empty if the name doesn't resolve
+	Addresses []string `json:"addresses,omitempty"`
+
+	// CNAME is the canonical name if it differs from the hostname
+	CNAME string `json:"cname,omitempty"`
+}
+
+// Resolve looks up the A/AAAA records and CNAME of every hostname. Every
+// name gets an entry, with no addresses if it doesn't resolve.
+func (r *DNSResolver) Resolve(ctx context.Context, hostnames []string) map[string]*Resolution {
+	resolver := r.resolver()
+	var mu sync.Mutex
+	results := map[string]*Resolution{}
+	forEach(ctx, hostnames, r.Workers, func(name string) {
+		host := strings.TrimPrefix(name, "*.")
+		res := &Resolution{}
+		if ips, err := resolver.LookupIPAddr(ctx, host); err == nil {
+			for _, ip := range ips {
+				res.Addresses = append(res.Addresses, ip.IP.String())
+			}
+			sort.Strings(res.Addresses)
+		}
+		if cname, err := resolver.LookupCNAME(ctx, host); err == nil {
+			cname = strings.TrimSuffix(cname, ".")
+			if !strings.EqualFold(cname, host) {
+				res.CNAME = cname
+			}
+		}
+		mu.Lock()
+		results[name] = res
+		mu.Unlock()
+	})
+	return results
+}
+
+// Reverse looks up the PTR records of every IP. IPs without one are left out.
+func (r *DNSResolver) Reverse(ctx context.Context, ips []string) map[string][]string {
+	resolver := r.resolver()
+	var mu sync.Mutex
+	names := map[string][]string{}
+	forEach(ctx, ips, r.Workers, func(ip string) {
+		hosts, err := resolver.LookupAddr(ctx, ip)
+		if err != nil || len(hosts) == 0 {
+			return
+		}
+		for i, h := range hosts {
+			hosts[i] = strings.TrimSuffix(h, ".")
+		}
+		mu.Lock()
+		names[ip] = hosts
+		mu.Unlock()
+	})
+	return names
+}
+
+// Return the system resolver, or one that sends each query to the next server
+func (r *DNSResolver) resolver() *net.Resolver {
+	if len(r.Servers) == 0 {
+		return net.DefaultResolver
+	}
+	servers := make([]string, len(r.Servers))
+	for i, s := range r.Servers {
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			s = net.JoinHostPort(s, "53")
+		}
+		servers[i] = s
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			server := servers[int(atomic.AddUint32(&r.next, 1))%len(servers)]
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// Addresses returns the distinct addresses of a hostname-to-IPs map in a stable order
+func Addresses(ips map[string][]string) []string {
+	var all []string
+	for _, list := range ips {
+		all = append(all, list...)
+	}
+	all = Unique(all)
+	sort.Strings(all)
+	return all
+}
+
+// forEach runs fn over items with a pool of workers, stopping early when ctx is done
+func forEach(ctx context.Context, items []string, workers int, fn func(string)) {
+	if workers < 1 {
+		workers = 1
+	}
+	ch := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range ch {
+				fn(item)
+			}
+		}()
+	}
+	for _, item := range items {
+		select {
+		case ch <- item:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(ch)
+	wg.Wait()
+}
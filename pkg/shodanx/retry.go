@@ -0,0 +1,81 @@
+package shodanx
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how transient failures (network errors, 429 and 5xx
+// responses) are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles on every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and any Retry-After value
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// retryableStatus reports whether a response status is worth retrying
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// delay returns how long to wait before retry number attempt (starting at
+// 1). A Retry-After header wins over the exponential backoff.
+func (p RetryPolicy) delay(attempt int, header http.Header) time.Duration {
+	if d, ok := retryAfter(header); ok {
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+		return d
+	}
+
+	d := p.BaseDelay << (attempt - 1)
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	// Jitter keeps parallel clients from retrying in lockstep
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
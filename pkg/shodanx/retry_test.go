@@ -0,0 +1,40 @@
+package shodanx
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{"first retry", 1, "", 500 * time.Millisecond, time.Second},
+		{"doubles", 3, "", 2 * time.Second, 4 * time.Second},
+		{"capped", 5, "", 5 * time.Second, 10 * time.Second},
+		{"overflow capped", 80, "", 5 * time.Second, 10 * time.Second},
+		{"retry-after seconds", 1, "7", 7 * time.Second, 7 * time.Second},
+		{"retry-after capped", 1, "120", 10 * time.Second, 10 * time.Second},
+		{"retry-after in the past", 1, "Mon, 02 Jan 2006 15:04:05 GMT", 0, 0},
+		{"invalid retry-after", 2, "soon", time.Second, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.retryAfter != "" {
+				header.Set("Retry-After", tt.retryAfter)
+			}
+			// The backoff is jittered, so check its range a few times
+			for i := 0; i < 20; i++ {
+				if d := p.delay(tt.attempt, header); d < tt.min || d > tt.max {
+					t.Fatalf("delay(%d) = %s, want between %s and %s", tt.attempt, d, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
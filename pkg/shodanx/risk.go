@@ -0,0 +1,241 @@
+package shodanx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AssetRisk is the risk score of a subdomain and what it is made of.
+type AssetRisk struct {
+	Host    string       `json:"host"`
+	Score   int          `json:"score"` // 0 to MaxRisk
+	Factors []RiskFactor `json:"factors"`
+}
+
+// RiskFactor is one finding that adds to an asset's risk score.
+type RiskFactor struct {
+	Reason string `json:"reason"`
+	Points int    `json:"points"`
+}
+
+// MaxRisk caps the risk score of an asset.
+const MaxRisk = 100
+
+// Points added per finding
+const (
+	riskExposed       = 25 // high-risk service, see RiskyPorts
+	riskCritical      = 20 // per CVE by severity
+	riskHigh          = 10
+	riskMedium        = 5
+	riskLow           = 2
+	riskMaxCVE        = 40 // all CVEs together
+	riskExpiredCert   = 10
+	riskSelfSigned    = 5
+	riskMissingBucket = 30 // claimable by anyone
+	riskOpenBucket    = 25
+	riskDanglingCNAME = 20
+	riskOutdated      = 10 // per end-of-life product
+)
+
+// OutdatedSoftware maps lowercase product names to the oldest major.minor
+// release still supported; older versions count as end-of-life.
+var OutdatedSoftware = map[string]string{
+	"apache httpd":        "2.4",
+	"nginx":               "1.20",
+	"microsoft iis httpd": "8.5",
+	"openssh":             "8.0",
+	"lighttpd":            "1.4",
+	"apache tomcat":       "9.0",
+	"jetty":               "10.0",
+	"exim smtpd":          "4.96",
+	"vsftpd":              "3.0",
+	"mysql":               "8.0",
+	"postgresql":          "12",
+	"redis":               "6.2",
+	"elasticsearch":       "7.17",
+	"mongodb":             "5.0",
+	"php":                 "8.1",
+}
+
+// ScoreRisk computes the risk score of every subdomain from its exposed
+// services, CVEs, certificate findings, takeover indicators and outdated
+// software, and stores those above zero in Risks, riskiest first.
+func (r *Result) ScoreRisk() {
+	// Findings are keyed by address; a hostname gets those of its addresses
+	exposed := map[string][]Exposure{}
+	for _, e := range r.Exposed {
+		exposed[e.IP] = append(exposed[e.IP], e)
+	}
+	certs := map[string][]CertFinding{}
+	for _, f := range r.CertFindings() {
+		key := f.IP
+		if key == "" && len(f.Hostnames) > 0 {
+			key = f.Hostnames[0] // probed hosts
+		}
+		certs[key] = append(certs[key], f)
+	}
+	buckets := map[string]Bucket{}
+	for _, b := range r.Buckets {
+		buckets[b.Host] = b
+	}
+	unresolved := map[string]bool{}
+	for _, name := range r.Unresolved {
+		unresolved[name] = true
+	}
+
+	r.Risks = nil
+	for _, name := range r.Subdomains {
+		risk := AssetRisk{Host: name}
+		addrs := r.HostAddresses(name)
+
+		seen := map[string]bool{}
+		cves, cvePoints := 0, 0
+		for _, ip := range addrs {
+			for _, e := range exposed[ip] {
+				if key := e.Service + "/" + strconv.Itoa(e.Port); !seen[key] {
+					seen[key] = true
+					risk.add(fmt.Sprintf("%s exposed on %s:%d", e.Service, ip, e.Port), riskExposed)
+				}
+			}
+			if host := r.Vulns[ip]; host != nil {
+				for _, v := range host.CVEs {
+					if !seen[v.CVE] {
+						seen[v.CVE] = true
+						cves++
+						cvePoints += cvePointsFor(v.CVSS)
+					}
+				}
+			}
+		}
+		if cves > 0 {
+			risk.add(fmt.Sprintf("%d CVEs", cves), min(cvePoints, riskMaxCVE))
+		}
+
+		for _, key := range append(addrs, name) {
+			for _, f := range certs[key] {
+				if f.Expired && !seen["expired"] {
+					seen["expired"] = true
+					risk.add("expired certificate", riskExpiredCert)
+				}
+				if f.SelfSigned && !seen["self-signed"] {
+					seen["self-signed"] = true
+					risk.add("self-signed certificate", riskSelfSigned)
+				}
+			}
+		}
+
+		if b, ok := buckets[name]; ok {
+			switch b.Access {
+			case BucketMissing:
+				risk.add("points at unclaimed "+b.Provider+" bucket "+b.Name, riskMissingBucket)
+			case BucketListable:
+				risk.add("publicly listable "+b.Provider+" bucket "+b.Name, riskOpenBucket)
+			}
+		}
+		if cname := r.CNAMEs[name]; cname != "" && unresolved[name] {
+			risk.add("dangling CNAME to "+cname, riskDanglingCNAME)
+		}
+
+		for _, svc := range r.Services[name] {
+			if svc.Product != "" && IsOutdated(svc.Product, svc.Version) && !seen[svc.Product+svc.Version] {
+				seen[svc.Product+svc.Version] = true
+				risk.add(fmt.Sprintf("end-of-life %s %s", svc.Product, svc.Version), riskOutdated)
+			}
+		}
+
+		if risk.Score > 0 {
+			risk.Score = min(risk.Score, MaxRisk)
+			r.Risks = append(r.Risks, risk)
+		}
+	}
+	sort.SliceStable(r.Risks, func(i, j int) bool { return r.Risks[i].Score > r.Risks[j].Score })
+}
+
+// RiskOf returns the risk score of a subdomain, 0 if it has none
+func (r *Result) RiskOf(name string) int {
+	for _, risk := range r.Risks {
+		if risk.Host == name {
+			return risk.Score
+		}
+	}
+	return 0
+}
+
+// ByRisk returns the subdomains ordered by risk score, riskiest first and
+// alphabetically among equal scores
+func (r *Result) ByRisk() []string {
+	scores := make(map[string]int, len(r.Risks))
+	for _, risk := range r.Risks {
+		scores[risk.Host] = risk.Score
+	}
+	names := append([]string(nil), r.Subdomains...)
+	sort.SliceStable(names, func(i, j int) bool { return scores[names[i]] > scores[names[j]] })
+	return names
+}
+
+func (a *AssetRisk) add(reason string, points int) {
+	a.Factors = append(a.Factors, RiskFactor{Reason: reason, Points: points})
+	a.Score += points
+}
+
+func cvePointsFor(cvss float64) int {
+	switch Severity(cvss) {
+	case "critical":
+		return riskCritical
+	case "high":
+		return riskHigh
+	case "medium":
+		return riskMedium
+	}
+	return riskLow
+}
+
+// IsOutdated reports whether a product version is older than the oldest
+// supported release listed in OutdatedSoftware
+func IsOutdated(product, version string) bool {
+	supported, ok := OutdatedSoftware[strings.ToLower(product)]
+	if !ok || version == "" {
+		return false
+	}
+	return compareVersions(version, supported) < 0
+}
+
+// compareVersions compares the leading numeric components of two dotted
+// versions, ignoring suffixes like "p1" or "-ubuntu"
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pb); i++ {
+		var x int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if x != pb[i] {
+			if x < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		end := 0
+		for end < len(p) && p[end] >= '0' && p[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, _ := strconv.Atoi(p[:end])
+		parts = append(parts, n)
+		if end < len(p) {
+			break
+		}
+	}
+	return parts
+}
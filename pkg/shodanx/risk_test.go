@@ -0,0 +1,27 @@
+package shodanx
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10", "1.9", 1},
+		{"2.0", "10.0", -1},
+		{"7.4p1", "7.4", 0},
+		{"8.2p1-ubuntu", "8.9", -1},
+		{"2.4.41-ubuntu", "2.4.41", 0},
+		{"1.18.0", "1.18", 0},
+		{"", "1.0", -1},
+		{"beta", "0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package shodanx
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Scan states reported by /shodan/scan/{id}
+const (
+	ScanSubmitting = "SUBMITTING"
+	ScanQueued     = "QUEUE"
+	ScanProcessing = "PROCESSING"
+	ScanDone       = "DONE"
+)
+
+// ScanSubmission is returned when an on-demand scan is accepted.
+type ScanSubmission struct {
+	ID          string `json:"id"`
+	Count       int    `json:"count"`
+	CreditsLeft int    `json:"credits_left"`
+}
+
+// ScanStatus is the progress of an on-demand scan.
+type ScanStatus struct {
+	ID      string `json:"id"`
+	Count   int    `json:"count"`
+	Status  string `json:"status"`
+	Created string `json:"created"`
+}
+
+// SubmitScan asks Shodan to crawl the given IPs and CIDRs. It costs one scan
+// credit per IP.
+func (c *Client) SubmitScan(ctx context.Context, targets []string) (*ScanSubmission, error) {
+	form := url.Values{}
+	form.Set("ips", strings.Join(targets, ","))
+
+	var sub ScanSubmission
+	if err := c.postForm(ctx, "/shodan/scan", form, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ScanStatus returns the current state of a submitted scan
+func (c *Client) ScanStatus(ctx context.Context, id string) (*ScanStatus, error) {
+	var status ScanStatus
+	if err := c.getJSON(ctx, "/shodan/scan/"+url.PathEscape(id), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// WaitScan polls the scan every interval until it is done or ctx is cancelled
+func (c *Client) WaitScan(ctx context.Context, id string, interval time.Duration) (*ScanStatus, error) {
+	last := ""
+	for {
+		status, err := c.ScanStatus(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if status.Status != last {
+			c.logf("[*] Scan %s: %s", id, status.Status)
+			last = status.Status
+		}
+		if status.Status == ScanDone {
+			return status, nil
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return status, err
+		}
+	}
+}
+
+// ScanResults searches for the banners collected by a finished scan
+func (c *Client) ScanResults(ctx context.Context, id string, maxPages int) (*SearchResult, error) {
+	return c.SearchAll(ctx, filter("scan", id), maxPages)
+}
@@ -0,0 +1,94 @@
+package shodanx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scope decides which hostnames are in scope, like a bug bounty scope
+// definition. A hostname is in scope if it matches an include rule (or there
+// are none) and no exclude rule.
+type Scope struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// ParseScope builds a Scope from rules, one per line. A leading "!" makes a
+// rule an exclude rule. Rules starting with "re:" are regular expressions,
+// all others are globs where * matches any text, e.g.
+//
+//	*.example.com
+//	!*.staging.example.com
+//	re:^api-[0-9]+\.example\.com$
+//
+// Matching is case-insensitive.
+func ParseScope(rules []string) (*Scope, error) {
+	s := &Scope{}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+		exclude := strings.HasPrefix(rule, "!")
+		pattern := strings.TrimSpace(strings.TrimPrefix(rule, "!"))
+
+		var expr string
+		if re, ok := strings.CutPrefix(pattern, "re:"); ok {
+			expr = "(?i)" + re
+		} else {
+			expr = "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scope rule %q: %w", rule, err)
+		}
+		if exclude {
+			s.exclude = append(s.exclude, re)
+		} else {
+			s.include = append(s.include, re)
+		}
+	}
+	return s, nil
+}
+
+// InScope reports whether hostname is in scope
+func (s *Scope) InScope(hostname string) bool {
+	for _, re := range s.exclude {
+		if re.MatchString(hostname) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, re := range s.include {
+		if re.MatchString(hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter removes the subdomains for which keep returns false, along with
+// their sources, first-seen times, addresses and services, and returns the removed names
+func (r *Result) Filter(keep func(hostname string) bool) []string {
+	var kept, dropped []string
+	for _, name := range r.Subdomains {
+		if keep(name) {
+			kept = append(kept, name)
+			continue
+		}
+		dropped = append(dropped, name)
+		delete(r.Sources, name)
+		delete(r.FirstSeen, name)
+		delete(r.IPs, name)
+		delete(r.Services, name)
+		delete(r.seen, name)
+	}
+	if kept == nil {
+		kept = []string{}
+	}
+	r.Subdomains = kept
+	return dropped
+}
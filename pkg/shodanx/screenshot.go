@@ -0,0 +1,105 @@
+package shodanx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Screenshotter captures web pages with a headless Chrome or Chromium,
+// which must be installed (see ChromePath).
+type Screenshotter struct {
+	// Dir receives one PNG per page
+	Dir string
+
+	// Workers is the number of tabs loading pages at once
+	Workers int
+
+	// Timeout limits the load of a single page
+	Timeout time.Duration
+
+	// ChromePath is the browser binary; empty finds it on the PATH
+	ChromePath string
+}
+
+// DefaultScreenshotTimeout is the page load timeout used when Screenshotter.Timeout is zero.
+const DefaultScreenshotTimeout = 20 * time.Second
+
+// The browser window the pages are rendered in
+const screenshotWidth, screenshotHeight = 1440, 900
+
+// Capture screenshots the URL of every probe, records the PNG path in its
+// Screenshot field and returns how many were taken. Pages that fail to load
+// are skipped; an error is only returned if the browser can't be started.
+func (s *Screenshotter) Capture(ctx context.Context, probes map[string]*Probe) (int, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return 0, err
+	}
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("ignore-certificate-errors", true),
+		chromedp.WindowSize(screenshotWidth, screenshotHeight),
+	)
+	if s.ChromePath != "" {
+		opts = append(opts, chromedp.ExecPath(s.ChromePath))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancelAlloc()
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	defer cancelBrowser()
+	// Start the browser up front so a missing binary is reported once
+	if err := chromedp.Run(browserCtx); err != nil {
+		return 0, fmt.Errorf("failed to start headless Chrome: %w", err)
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultScreenshotTimeout
+	}
+	names := make([]string, 0, len(probes))
+	for name := range probes {
+		names = append(names, name)
+	}
+
+	var mu sync.Mutex
+	taken := 0
+	forEach(ctx, names, s.Workers, func(name string) {
+		probe := probes[name]
+		path := filepath.Join(s.Dir, screenshotFile(probe.URL))
+		if err := capturePage(browserCtx, probe.URL, path, timeout); err != nil {
+			return
+		}
+		mu.Lock()
+		probe.Screenshot = path
+		taken++
+		mu.Unlock()
+	})
+	return taken, nil
+}
+
+// capturePage loads a page in a new tab and writes a PNG of the viewport
+func capturePage(browserCtx context.Context, url, path string, timeout time.Duration) error {
+	tabCtx, cancelTab := chromedp.NewContext(browserCtx)
+	defer cancelTab()
+	tabCtx, cancel := context.WithTimeout(tabCtx, timeout)
+	defer cancel()
+
+	var png []byte
+	if err := chromedp.Run(tabCtx, chromedp.Navigate(url), chromedp.CaptureScreenshot(&png)); err != nil {
+		return err
+	}
+	return os.WriteFile(path, png, 0644)
+}
+
+// screenshotFile names the PNG of a URL, e.g. https_api.example.com_8443.png
+func screenshotFile(url string) string {
+	name := strings.TrimSuffix(url, "/")
+	name = strings.NewReplacer("://", "_", ":", "_", "/", "_").Replace(name)
+	return name + ".png"
+}
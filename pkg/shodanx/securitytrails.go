@@ -0,0 +1,160 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterSource("securitytrails", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("securitytrails", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			c.logf("[*] Fetching subdomains and DNS history from SecurityTrails")
+			names, err := c.SecurityTrailsSubdomains(ctx, domain)
+			if !send(SourceResult{Source: SourceSecurityTrails, Names: names, Err: err}) {
+				return
+			}
+			history, err := c.SecurityTrailsHistory(ctx, domain, opts.MaxPages)
+			send(SourceResult{Source: SourceSecurityTrails, DNSHistory: history, Err: err})
+		})
+	})
+	RegisterSourceCheck("securitytrails", func(ctx context.Context, c *Client) (string, error) {
+		return c.securityTrailsQuota(ctx)
+	})
+}
+
+// DefaultSecurityTrailsURL is the SecurityTrails API endpoint.
+const DefaultSecurityTrailsURL = "https://api.securitytrails.com/v1"
+
+// SourceSecurityTrails is the source recorded for subdomains from SecurityTrails.
+const SourceSecurityTrails = "securitytrails"
+
+// DNSHistory is a past DNS record of a name, as seen by SecurityTrails.
+type DNSHistory struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"` // "a" or "aaaa"
+	Values        []string `json:"values"`
+	Organizations []string `json:"organizations,omitempty"`
+	FirstSeen     string   `json:"first_seen"`
+	LastSeen      string   `json:"last_seen"`
+}
+
+// HasSecurityTrails reports whether the client has a SecurityTrails API key
+func (c *Client) HasSecurityTrails() bool {
+	return c.SecurityTrailsKey != ""
+}
+
+// SecurityTrailsSubdomains returns the subdomains SecurityTrails knows for
+// domain, including inactive ones.
+func (c *Client) SecurityTrailsSubdomains(ctx context.Context, domain string) ([]string, error) {
+	var resp struct {
+		Subdomains []string `json:"subdomains"`
+	}
+	params := url.Values{"children_only": {"false"}, "include_inactive": {"true"}}
+	if err := c.securityTrailsGet(ctx, "/domain/"+url.PathEscape(domain)+"/subdomains", params, &resp); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(resp.Subdomains))
+	for _, label := range resp.Subdomains {
+		names = append(names, label+"."+domain)
+	}
+	return names, nil
+}
+
+// SecurityTrailsHistory returns the past A and AAAA records of name, up to
+// maxPages pages per type (zero or less fetches all). Old addresses of a
+// CDN-fronted name often still reach the origin.
+func (c *Client) SecurityTrailsHistory(ctx context.Context, name string, maxPages int) ([]DNSHistory, error) {
+	var history []DNSHistory
+	for _, typ := range []string{"a", "aaaa"} {
+		for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+			var resp struct {
+				Pages   int `json:"pages"`
+				Records []struct {
+					Values []struct {
+						IP   string `json:"ip"`
+						IPv6 string `json:"ipv6"`
+					} `json:"values"`
+					Organizations []string `json:"organizations"`
+					FirstSeen     string   `json:"first_seen"`
+					LastSeen      string   `json:"last_seen"`
+				} `json:"records"`
+			}
+			path := "/history/" + url.PathEscape(name) + "/dns/" + typ
+			if err := c.securityTrailsGet(ctx, path, url.Values{"page": {strconv.Itoa(page)}}, &resp); err != nil {
+				return history, err
+			}
+			for _, rec := range resp.Records {
+				h := DNSHistory{Name: name, Type: typ, Organizations: rec.Organizations, FirstSeen: rec.FirstSeen, LastSeen: rec.LastSeen}
+				for _, v := range rec.Values {
+					if v.IP != "" {
+						h.Values = append(h.Values, v.IP)
+					} else if v.IPv6 != "" {
+						h.Values = append(h.Values, v.IPv6)
+					}
+				}
+				history = append(history, h)
+			}
+			if page >= resp.Pages {
+				break
+			}
+		}
+	}
+	return history, nil
+}
+
+// HistoricalAddresses returns the addresses in DNSHistory that no resolved
+// subdomain points to anymore
+func (r *Result) HistoricalAddresses() []string {
+	current := map[string]bool{}
+	for _, ips := range r.IPs {
+		for _, ip := range ips {
+			current[ip] = true
+		}
+	}
+	var ips []string
+	for _, h := range r.DNSHistory {
+		for _, ip := range h.Values {
+			if !current[ip] {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return Unique(ips)
+}
+
+// Fetch a SecurityTrails API path with the client's key
+func (c *Client) securityTrailsGet(ctx context.Context, path string, params url.Values, v interface{}) error {
+	if !c.HasSecurityTrails() {
+		return errors.New("securitytrails API key is not set")
+	}
+	base := c.SecurityTrailsURL
+	if base == "" {
+		base = DefaultSecurityTrailsURL
+	}
+	return sourceError("securitytrails", c.call(ctx, request{
+		source: SourceSecurityTrails,
+		method: http.MethodGet,
+		url:    strings.TrimRight(base, "/") + path + "?" + params.Encode(),
+		header: http.Header{"Apikey": {c.SecurityTrailsKey}},
+	}, v))
+}
+
+// securityTrailsQuota returns the SecurityTrails requests left this month
+func (c *Client) securityTrailsQuota(ctx context.Context) (string, error) {
+	if !c.HasSecurityTrails() {
+		return "", ErrNotConfigured
+	}
+	var resp struct {
+		Used    int `json:"current_monthly_usage"`
+		Allowed int `json:"allowed_monthly_usage"`
+	}
+	if err := c.securityTrailsGet(ctx, "/account/usage", url.Values{}, &resp); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d of %d requests left this month", resp.Allowed-resp.Used, resp.Allowed), nil
+}
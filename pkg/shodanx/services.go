@@ -0,0 +1,128 @@
+package shodanx
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Service is a port Shodan has seen open on an address of a hostname.
+type Service struct {
+	IP        string `json:"ip"`
+	Port      int    `json:"port"`
+	Transport string `json:"transport,omitempty"`
+	Module    string `json:"module,omitempty"` // Shodan's protocol name, e.g. "https" or "ssh"
+	Product   string `json:"product,omitempty"`
+	Version   string `json:"version,omitempty"`
+
+	// CertSHA256 and CertSubject identify the TLS certificate the service
+	// presented, if any, so services sharing a certificate can be linked
+	CertSHA256  string `json:"cert_sha256,omitempty"`
+	CertSubject string `json:"cert_subject,omitempty"` // common name
+}
+
+// String returns "443/https nginx 1.25", falling back to the transport
+// when the protocol is unknown
+func (s Service) String() string {
+	proto := s.Module
+	if proto == "" {
+		proto = s.Transport
+	}
+	out := strconv.Itoa(s.Port)
+	if proto != "" {
+		out += "/" + proto
+	}
+	if s.Product != "" {
+		out += " " + s.Product
+		if s.Version != "" {
+			out += " " + s.Version
+		}
+	}
+	return out
+}
+
+// addServices records the service of a banner under each of its hostnames
+func addServices(services map[string][]Service, m *Match) {
+	svc := Service{
+		IP:        m.IPStr,
+		Port:      m.Port,
+		Transport: m.Transport,
+		Module:    m.Shodan.Module,
+		Product:   m.Product,
+		Version:   m.Version,
+	}
+	if m.SSL != nil {
+		svc.CertSHA256 = m.SSL.Cert.Fingerprint.SHA256
+		svc.CertSubject = m.SSL.Cert.Subject["CN"]
+	}
+	for _, h := range m.Hostnames {
+		name := NormalizeHostname(h)
+		if name == "" {
+			continue
+		}
+		services[name] = append(services[name], svc)
+	}
+}
+
+// sortServices orders the services of every hostname by address and port
+func sortServices(services map[string][]Service) map[string][]Service {
+	if len(services) == 0 {
+		return nil
+	}
+	for _, list := range services {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].IP != list[j].IP {
+				return list[i].IP < list[j].IP
+			}
+			return list[i].Port < list[j].Port
+		})
+	}
+	return services
+}
+
+// HostPorts returns the sorted ports seen open on a hostname, by Shodan and
+// by InternetDB for its addresses
+func (r *Result) HostPorts(name string) []int {
+	seen := map[int]bool{}
+	for _, svc := range r.Services[name] {
+		seen[svc.Port] = true
+	}
+	for _, ip := range r.HostAddresses(name) {
+		if h := r.InternetDB[ip]; h != nil {
+			for _, port := range h.Ports {
+				seen[port] = true
+			}
+		}
+	}
+	ports := make([]int, 0, len(seen))
+	for port := range seen {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// HostTechnologies returns the software seen on a hostname: the products of
+// its services, the Server header of its web server and the InternetDB CPEs
+// of its addresses
+func (r *Result) HostTechnologies(name string) []string {
+	var techs []string
+	for _, svc := range r.Services[name] {
+		if svc.Product == "" {
+			continue
+		}
+		tech := svc.Product
+		if svc.Version != "" {
+			tech += " " + svc.Version
+		}
+		techs = append(techs, tech)
+	}
+	if p := r.Probes[name]; p != nil && p.Server != "" {
+		techs = append(techs, p.Server)
+	}
+	for _, ip := range r.HostAddresses(name) {
+		if h := r.InternetDB[ip]; h != nil {
+			techs = append(techs, h.CPEs...)
+		}
+	}
+	return Unique(techs)
+}
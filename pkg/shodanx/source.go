@@ -0,0 +1,164 @@
+package shodanx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// Source is a data source Enumerate adds to the Shodan queries. Enumerate
+// sends what the source finds for domain on the returned channel and closes
+// it when done or when ctx is cancelled.
+type Source interface {
+	Name() string
+	Enumerate(ctx context.Context, domain string) <-chan SourceResult
+}
+
+// SourceResult is a batch of findings of a Source, e.g. the names of one page
+// or query. Matches are merged like Shodan matches, so backends that search
+// banners also contribute services, certificates and geo data. Err is a
+// non-fatal error, logged before the rest of the result is added.
+type SourceResult struct {
+	// Source is recorded as the source of the names, e.g. "fofa:<query>"
+	Source     string
+	Names      []string
+	Matches    []Match
+	DNSHistory []DNSHistory
+	Err        error
+}
+
+// SourceFactory creates a source for a client and the options of a run
+type SourceFactory func(c *Client, opts EnumerateOptions) Source
+
+var sourceRegistry = map[string]SourceFactory{}
+
+// RegisterSource makes a source available to EnumerateOptions.Sources under
+// name. Sources register themselves from an init function, so a new source,
+// built in or loaded as a Go plugin, is a single self-contained file.
+// Registering a name twice replaces the earlier source.
+func RegisterSource(name string, factory SourceFactory) {
+	sourceRegistry[name] = factory
+}
+
+// SourceCheck verifies the credentials and connectivity of a source without
+// running it, returning its remaining quota when the API reports one
+type SourceCheck func(ctx context.Context, c *Client) (quota string, err error)
+
+var sourceChecks = map[string]SourceCheck{}
+
+// RegisterSourceCheck registers the check CheckSource runs for a source
+func RegisterSourceCheck(name string, check SourceCheck) {
+	sourceChecks[name] = check
+}
+
+// ErrNotConfigured is returned by the checks of sources whose credentials
+// are not set
+var ErrNotConfigured = errors.New("credentials not configured")
+
+// ErrNoCheck is returned by CheckSource for sources without a check
+var ErrNoCheck = errors.New("no health check available")
+
+// CheckSource runs the check of a registered source, see SourceCheck
+func (c *Client) CheckSource(ctx context.Context, name string) (string, error) {
+	check, ok := sourceChecks[name]
+	if !ok {
+		return "", ErrNoCheck
+	}
+	return check(ctx, c)
+}
+
+// ping checks that a keyless source answers at all. Client errors such as
+// 404 still prove connectivity.
+func (c *Client) ping(ctx context.Context, source, rawURL string) error {
+	_, err := c.callRaw(ctx, request{source: source, method: http.MethodGet, url: rawURL})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+		return nil
+	}
+	return sourceError(source, err)
+}
+
+// sourceConcurrency returns the requests a source may run in parallel, see
+// SourceSettings.Concurrency
+func (c *Client) sourceConcurrency(source string) int {
+	if n := c.SourceSettings[source].Concurrency; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// urlOr returns rawURL, or def when it is empty
+func urlOr(rawURL, def string) string {
+	if rawURL == "" {
+		return def
+	}
+	return rawURL
+}
+
+// SourceNames returns the sorted names of the registered sources
+func SourceNames() []string {
+	names := make([]string, 0, len(sourceRegistry))
+	for name := range sourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSource returns a Source that runs fn in a goroutine and closes the
+// channel when it returns. fn sends its findings with send, which reports
+// false once ctx is cancelled and fn should return.
+func NewSource(name string, fn func(ctx context.Context, domain string, send func(SourceResult) bool)) Source {
+	return &funcSource{name: name, fn: fn}
+}
+
+type funcSource struct {
+	name string
+	fn   func(ctx context.Context, domain string, send func(SourceResult) bool)
+}
+
+func (s *funcSource) Name() string { return s.name }
+
+func (s *funcSource) Enumerate(ctx context.Context, domain string) <-chan SourceResult {
+	ch := make(chan SourceResult)
+	go func() {
+		defer close(ch)
+		s.fn(ctx, domain, func(r SourceResult) bool {
+			select {
+			case ch <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+// runSources runs the sources of opts one after another, adding their
+// findings to result and facets. Unknown sources and errors are logged.
+func (c *Client) runSources(ctx context.Context, domain string, opts EnumerateOptions, result *Result, facets *facetCounter) {
+	for _, name := range opts.Sources {
+		if ctx.Err() != nil {
+			return
+		}
+		factory, ok := sourceRegistry[name]
+		if !ok {
+			c.logf("[!] Unknown source %q, skipped", name)
+			continue
+		}
+		for r := range factory(c, opts).Enumerate(ctx, domain) {
+			if r.Err != nil {
+				c.logf("[!] %v", r.Err)
+			}
+			result.AddHostnames(r.Source, r.Names...)
+			if len(r.Matches) > 0 {
+				res := SearchResult{Matches: r.Matches}
+				result.AddHostnames(r.Source, res.Hostnames()...)
+				facets.add(r.Matches)
+			}
+			result.DNSHistory = append(result.DNSHistory, r.DNSHistory...)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package shodanx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultStreamURL is the Shodan Streaming API endpoint.
+const DefaultStreamURL = "https://stream.shodan.io"
+
+// StreamBanner is a banner received from the Streaming API. Raw keeps the
+// complete JSON document so no field is lost when re-emitting it.
+type StreamBanner struct {
+	Match
+	Raw json.RawMessage `json:"-"`
+}
+
+// Streaming API paths
+const (
+	StreamFirehose = "/shodan/banners"
+	StreamAlerts   = "/shodan/alert"
+)
+
+// StreamAlert returns the stream path for a single network alert
+func StreamAlert(id string) string {
+	return "/shodan/alert/" + url.PathEscape(id)
+}
+
+// StreamFilter returns the stream path for a filtered firehose, e.g.
+// StreamFilter("ports", "22", "443") or StreamFilter("countries", "DE")
+func StreamFilter(kind string, values ...string) string {
+	return "/shodan/" + kind + "/" + url.PathEscape(strings.Join(values, ","))
+}
+
+// Stream connects to a Streaming API path and calls handle for every banner
+// until ctx is cancelled, the connection drops or handle returns an error.
+func (c *Client) Stream(ctx context.Context, path string, handle func(*StreamBanner) error) error {
+	base := c.StreamURL
+	if base == "" {
+		base = DefaultStreamURL
+	}
+	rawURL := strings.TrimRight(base, "/") + path + "?" + url.Values{"key": {c.APIKey}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	// The stream never ends on its own, so the client timeout must not apply
+	hc := *c.httpClient()
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("stream request failed: %w", redactError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var buf bytes.Buffer
+		buf.ReadFrom(resp.Body)
+		return newAPIError(resp.StatusCode, buf.Bytes())
+	}
+
+	err = c.readBanners(resp.Body, handle)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("stream interrupted: %w", err)
+	}
+	return nil
+}
+
+// Decode newline-delimited banners from r and call handle for each one,
+// skipping keep-alives and malformed lines
+func (c *Client) readBanners(r io.Reader, handle func(*StreamBanner) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue // keep-alive
+		}
+
+		banner := &StreamBanner{Raw: append(json.RawMessage(nil), line...)}
+		if err := json.Unmarshal(line, &banner.Match); err != nil {
+			c.logf("[!] Skipping malformed banner: %v", err)
+			continue
+		}
+		if err := handle(banner); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
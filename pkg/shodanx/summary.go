@@ -0,0 +1,120 @@
+package shodanx
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SummaryTop is the number of values kept per facet in Result.Summary.
+const SummaryTop = 10
+
+// facetCounter tallies the DefaultFacets over unique banners and collects
+// their services, locations, high-risk exposures, certificate findings and
+// vulnerabilities
+type facetCounter struct {
+	seen     map[string]bool
+	counts   map[string]map[string]int
+	services map[string][]Service
+	geo      map[string]*GeoInfo
+	exposed  []Exposure
+	certs    []CertFinding
+	names    map[string][]string // certificate names
+	vulns    map[string]*HostVulns
+	now      time.Time
+
+	// onMatch is called with every banner not seen before
+	onMatch func(m *Match)
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{
+		seen:     map[string]bool{},
+		counts:   map[string]map[string]int{},
+		services: map[string][]Service{},
+		geo:      map[string]*GeoInfo{},
+		vulns:    map[string]*HostVulns{},
+		names:    map[string][]string{},
+		now:      time.Now(),
+	}
+}
+
+// Count the banners of matches that were not seen before, since the same
+// service usually shows up in several queries
+func (f *facetCounter) add(matches []Match) {
+	for i := range matches {
+		m := &matches[i]
+		key := m.IPStr + "/" + m.Transport + "/" + strconv.Itoa(m.Port)
+		if f.seen[key] {
+			continue
+		}
+		f.seen[key] = true
+		if f.onMatch != nil {
+			f.onMatch(m)
+		}
+
+		f.inc("port", strconv.Itoa(m.Port))
+		f.inc("org", m.Org)
+		f.inc("country", m.Location.CountryCode)
+		f.inc("product", m.Product)
+		if cf := bannerCertFinding(m, f.now); cf != nil {
+			f.certs = append(f.certs, *cf)
+		}
+		addServices(f.services, m)
+		addGeo(f.geo, m)
+		f.exposed = addExposure(f.exposed, m)
+		addVulns(f.vulns, m)
+		addCertNames(f.names, m)
+	}
+}
+
+func (f *facetCounter) inc(facet, value string) {
+	if value == "" {
+		return
+	}
+	if f.counts[facet] == nil {
+		f.counts[facet] = map[string]int{}
+	}
+	f.counts[facet][value]++
+}
+
+// Return the top n values of every facet, most common first
+func (f *facetCounter) top(n int) map[string][]FacetValue {
+	if len(f.counts) == 0 {
+		return nil
+	}
+	summary := map[string][]FacetValue{}
+	for facet, counts := range f.counts {
+		values := make([]FacetValue, 0, len(counts))
+		for v, c := range counts {
+			values = append(values, FacetValue{Value: v, Count: c})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].String() < values[j].String()
+		})
+		if len(values) > n {
+			values = values[:n]
+		}
+		summary[facet] = values
+	}
+	return summary
+}
+
+// Return the collected locations, or nil if there are none
+func (f *facetCounter) hostGeo() map[string]*GeoInfo {
+	if len(f.geo) == 0 {
+		return nil
+	}
+	return f.geo
+}
+
+// Return the collected vulnerabilities, or nil if there are none
+func (f *facetCounter) hostVulns() map[string]*HostVulns {
+	if len(f.vulns) == 0 {
+		return nil
+	}
+	return f.vulns
+}
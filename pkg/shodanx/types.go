@@ -0,0 +1,147 @@
+package shodanx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SearchResult is the response of /shodan/host/search.
+type SearchResult struct {
+	Matches []Match                 `json:"matches"`
+	Total   int                     `json:"total"`
+	Facets  map[string][]FacetValue `json:"facets,omitempty"`
+}
+
+// FacetValue is a single bucket of a search facet.
+type FacetValue struct {
+	Value interface{} `json:"value"`
+	Count int         `json:"count"`
+}
+
+// String formats the bucket value, printing whole numbers without decimals
+func (f FacetValue) String() string {
+	if n, ok := f.Value.(float64); ok && n == float64(int64(n)) {
+		return fmt.Sprintf("%d", int64(n))
+	}
+	return fmt.Sprintf("%v", f.Value)
+}
+
+// Match is a single banner returned by a search.
+type Match struct {
+	IPStr     string   `json:"ip_str"`
+	Port      int      `json:"port"`
+	Transport string   `json:"transport"`
+	Hostnames []string `json:"hostnames"`
+	Domains   []string `json:"domains"`
+	Org       string   `json:"org"`
+	ISP       string   `json:"isp"`
+	ASN       string   `json:"asn"`
+	OS        string   `json:"os"`
+	Product   string   `json:"product"`
+	Version   string   `json:"version"`
+	Data      string   `json:"data"`
+	Timestamp string   `json:"timestamp"`
+	Location  Location `json:"location"`
+	SSL       *SSL     `json:"ssl,omitempty"`
+
+	// Vulns maps CVE IDs to their details
+	Vulns map[string]Vuln `json:"vulns,omitempty"`
+
+	Shodan ShodanMeta `json:"_shodan"`
+
+	// Raw is the banner as Shodan returned it, with the fields Match leaves
+	// out; only set by searches of a Client with KeepRaw
+	Raw json.RawMessage `json:"-"`
+}
+
+// ShodanMeta describes how Shodan collected a banner.
+type ShodanMeta struct {
+	// Module is the protocol the crawler spoke, e.g. "https" or "ssh"
+	Module string `json:"module"`
+}
+
+// Location is the geolocation Shodan attaches to a banner.
+type Location struct {
+	City        string  `json:"city"`
+	CountryCode string  `json:"country_code"`
+	CountryName string  `json:"country_name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+}
+
+// SSL holds the TLS details of a banner.
+type SSL struct {
+	Cert     Cert     `json:"cert"`
+	Versions []string `json:"versions"`
+	Chain    []string `json:"chain"`
+}
+
+// Cert is the parsed leaf certificate of a TLS service.
+type Cert struct {
+	Subject     map[string]string `json:"subject"`
+	Issuer      map[string]string `json:"issuer"`
+	Serial      json.Number       `json:"serial"`
+	Expired     bool              `json:"expired"`
+	Expires     string            `json:"expires"`
+	Issued      string            `json:"issued"`
+	SigAlg      string            `json:"sig_alg"`
+	Fingerprint Fingerprint       `json:"fingerprint"`
+	Extensions  []CertExtension   `json:"extensions"`
+}
+
+// Fingerprint holds certificate digests.
+type Fingerprint struct {
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+}
+
+// CertExtension is a raw X.509 extension as reported by Shodan.
+type CertExtension struct {
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+	Critical bool   `json:"critical"`
+}
+
+// Hostnames extracts hostnames and certificate subject names from the matches
+func (r *SearchResult) Hostnames() []string {
+	subs := []string{}
+	for _, m := range r.Matches {
+		subs = append(subs, m.Hostnames...)
+		// SSL subject values that look like names
+		if m.SSL != nil {
+			for _, v := range m.SSL.Cert.Subject {
+				if strings.Contains(v, ".") {
+					subs = append(subs, v)
+				}
+			}
+		}
+	}
+	return subs
+}
+
+// DNSDomainResult is the response of /dns/domain/{domain}.
+type DNSDomainResult struct {
+	Domain     string      `json:"domain"`
+	Tags       []string    `json:"tags"`
+	Subdomains []string    `json:"subdomains"`
+	Data       []DNSRecord `json:"data"`
+	More       bool        `json:"more"`
+}
+
+// DNSRecord is a single record known to Shodan for a domain.
+type DNSRecord struct {
+	Subdomain string `json:"subdomain"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	LastSeen  string `json:"last_seen"`
+}
+
+// Hostnames returns the fully qualified subdomains
+func (r *DNSDomainResult) Hostnames() []string {
+	subs := []string{}
+	for _, s := range r.Subdomains {
+		subs = append(subs, fmt.Sprintf("%s.%s", s, r.Domain))
+	}
+	return subs
+}
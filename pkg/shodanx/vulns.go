@@ -0,0 +1,134 @@
+package shodanx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Vuln is a vulnerability Shodan associates with a banner, keyed by CVE in
+// Match.Vulns.
+type Vuln struct {
+	Verified   bool     `json:"verified"`
+	CVSS       float64  `json:"cvss"`
+	Summary    string   `json:"summary"`
+	References []string `json:"references,omitempty"`
+}
+
+// HostVulns holds the CVEs found on the services of a host.
+type HostVulns struct {
+	Hostnames []string   `json:"hostnames,omitempty"`
+	CVEs      []HostVuln `json:"cves"`
+}
+
+// HostVuln is a CVE found on one of the services of a host.
+type HostVuln struct {
+	CVE      string  `json:"cve"`
+	CVSS     float64 `json:"cvss,omitempty"`
+	Verified bool    `json:"verified,omitempty"`
+	Port     int     `json:"port"`
+	Summary  string  `json:"summary,omitempty"`
+}
+
+// Severity returns the CVSS v3 rating of a score, or "unknown" without one
+func Severity(cvss float64) string {
+	switch {
+	case cvss >= 9:
+		return "critical"
+	case cvss >= 7:
+		return "high"
+	case cvss >= 4:
+		return "medium"
+	case cvss > 0:
+		return "low"
+	}
+	return "unknown"
+}
+
+// VulnEntry is a line of a vulnerability report.
+type VulnEntry struct {
+	IP        string
+	Hostnames []string
+	HostVuln
+}
+
+// VulnReport lists every CVE of every host, most severe first
+func (r *Result) VulnReport() []VulnEntry {
+	var report []VulnEntry
+	for ip, host := range r.Vulns {
+		for _, v := range host.CVEs {
+			report = append(report, VulnEntry{IP: ip, Hostnames: host.Hostnames, HostVuln: v})
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.CVSS != b.CVSS {
+			return a.CVSS > b.CVSS
+		}
+		if a.CVE != b.CVE {
+			return cveLess(b.CVE, a.CVE) // newer CVEs first
+		}
+		return a.IP < b.IP
+	})
+	return report
+}
+
+// cveLess orders CVE IDs by year, then by sequence number, so CVE-2021-9999
+// comes before CVE-2021-10000. Other IDs sort as text after them.
+func cveLess(a, b string) bool {
+	ya, na, okA := cveNumbers(a)
+	yb, nb, okB := cveNumbers(b)
+	switch {
+	case okA && okB:
+		if ya != yb {
+			return ya < yb
+		}
+		if na != nb {
+			return na < nb
+		}
+	case okA != okB:
+		return okA
+	}
+	return a < b
+}
+
+// cveNumbers returns the year and sequence number of a CVE ID
+func cveNumbers(id string) (year, seq int, ok bool) {
+	parts := strings.Split(strings.ToUpper(id), "-")
+	if len(parts) != 3 || parts[0] != "CVE" {
+		return 0, 0, false
+	}
+	year, err1 := strconv.Atoi(parts[1])
+	seq, err2 := strconv.Atoi(parts[2])
+	return year, seq, err1 == nil && err2 == nil
+}
+
+// addVulns records the CVEs of a banner, each once per host
+func addVulns(vulns map[string]*HostVulns, m *Match) {
+	if len(m.Vulns) == 0 {
+		return
+	}
+	host := vulns[m.IPStr]
+	if host == nil {
+		host = &HostVulns{}
+		vulns[m.IPStr] = host
+	}
+	host.Hostnames = Unique(append(host.Hostnames, m.Hostnames...))
+
+	known := map[string]bool{}
+	for _, v := range host.CVEs {
+		known[v.CVE] = true
+	}
+	cves := make([]string, 0, len(m.Vulns))
+	for cve := range m.Vulns {
+		cves = append(cves, cve)
+	}
+	sort.Slice(cves, func(i, j int) bool { return cveLess(cves[i], cves[j]) })
+	for _, cve := range cves {
+		if known[cve] {
+			continue
+		}
+		v := m.Vulns[cve]
+		host.CVEs = append(host.CVEs, HostVuln{CVE: cve, CVSS: v.CVSS, Verified: v.Verified, Port: m.Port, Summary: v.Summary})
+	}
+}
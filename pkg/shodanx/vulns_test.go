@@ -0,0 +1,16 @@
+package shodanx
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCVELess(t *testing.T) {
+	ids := []string{"CVE-2021-10000", "OSVDB-1", "CVE-2019-0708", "CVE-2021-9999", "cve-2020-1472", "CVE-2021-44228"}
+	sort.Slice(ids, func(i, j int) bool { return cveLess(ids[i], ids[j]) })
+	want := []string{"CVE-2019-0708", "cve-2020-1472", "CVE-2021-9999", "CVE-2021-10000", "CVE-2021-44228", "OSVDB-1"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("sorted CVEs = %v, want %v", ids, want)
+	}
+}
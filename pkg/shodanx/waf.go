@@ -0,0 +1,98 @@
+package shodanx
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// WAFSignature identifies a web application firewall by the headers,
+// cookies or block page it adds to responses.
+type WAFSignature struct {
+	Name string
+
+	// Headers maps header names to a lowercase substring of their value;
+	// an empty substring matches any value
+	Headers map[string]string
+
+	// Cookies are prefixes of the cookie names it sets
+	Cookies []string
+
+	// Body holds phrases of its block page
+	Body []string
+}
+
+// WAFSignatures are the firewalls DetectWAF recognises, checked in order
+var WAFSignatures = []WAFSignature{
+	{
+		Name:    "Cloudflare",
+		Headers: map[string]string{"Cf-Ray": "", "Server": "cloudflare", "Cf-Mitigated": ""},
+		Cookies: []string{"__cf_bm", "cf_clearance", "__cfduid", "__cflb"},
+		Body:    []string{"Attention Required! | Cloudflare", "cf-error-details"},
+	},
+	{
+		Name:    "Imperva",
+		Headers: map[string]string{"X-Iinfo": "", "X-Cdn": "incapsula"},
+		Cookies: []string{"incap_ses_", "visid_incap_", "nlbi_"},
+		Body:    []string{"Incapsula incident ID", "_Incapsula_Resource"},
+	},
+	{
+		Name:    "Akamai",
+		Headers: map[string]string{"Server": "akamaighost", "Akamai-Grn": "", "X-Akamai-Transformed": ""},
+		Cookies: []string{"ak_bmsc", "bm_sz", "_abck"},
+		Body:    []string{"Reference&#32;&#35;", "errors.edgesuite.net"},
+	},
+	{
+		Name:    "AWS WAF",
+		Headers: map[string]string{"X-Amzn-Waf-Action": ""},
+		Cookies: []string{"aws-waf-token", "awswaf_"},
+		Body:    []string{"Request blocked.", "Generated by cloudfront (CloudFront)"},
+	},
+}
+
+// wafBlockStatus are the status codes firewalls answer blocked requests with
+var wafBlockStatus = map[int]bool{
+	http.StatusForbidden:      true,
+	http.StatusNotAcceptable:  true,
+	http.StatusNotImplemented: true,
+	419:                       true,
+	999:                       true,
+}
+
+// UnknownWAF is recorded for hosts that block attack-like requests without
+// revealing which firewall does it
+const UnknownWAF = "unknown"
+
+// DetectWAF returns the name of the first WAFSignatures entry that matches
+// a response, or "" if none does
+func DetectWAF(header http.Header, body []byte) string {
+	for _, sig := range WAFSignatures {
+		if sig.matches(header, body) {
+			return sig.Name
+		}
+	}
+	return ""
+}
+
+func (s *WAFSignature) matches(header http.Header, body []byte) bool {
+	for name, want := range s.Headers {
+		for _, v := range header.Values(name) {
+			if strings.Contains(strings.ToLower(v), want) {
+				return true
+			}
+		}
+	}
+	for _, cookie := range header.Values("Set-Cookie") {
+		for _, prefix := range s.Cookies {
+			if strings.HasPrefix(strings.TrimSpace(cookie), prefix) {
+				return true
+			}
+		}
+	}
+	for _, phrase := range s.Body {
+		if bytes.Contains(body, []byte(phrase)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package shodanx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterSource("zoomeye", func(c *Client, opts EnumerateOptions) Source {
+		return NewSource("zoomeye", func(ctx context.Context, domain string, send func(SourceResult) bool) {
+			forEach(ctx, ZoomEyeQueries(domain), c.sourceConcurrency(SourceZoomEye), func(q string) {
+				c.logf("[*] ZoomEye query: %s", q)
+				r := SourceResult{Source: SourceZoomEye + ":" + q}
+				res, err := c.ZoomEyeSearch(ctx, q, opts.MaxPages)
+				if res != nil {
+					r.Matches = res.Matches
+				}
+				r.Err = err
+				send(r)
+			})
+		})
+	})
+	RegisterSourceCheck("zoomeye", func(ctx context.Context, c *Client) (string, error) {
+		return c.zoomEyeQuota(ctx)
+	})
+}
+
+// DefaultZoomEyeURL is the ZoomEye API endpoint.
+const DefaultZoomEyeURL = "https://api.zoomeye.hk"
+
+// SourceZoomEye prefixes the ZoomEye queries recorded as sources, e.g.
+// "zoomeye:hostname:example.com".
+const SourceZoomEye = "zoomeye"
+
+// ZoomEyePageSize is the number of results per ZoomEye host search page.
+const ZoomEyePageSize = 20
+
+// zoomEyeHost is a match of /host/search
+type zoomEyeHost struct {
+	IP       string `json:"ip"`
+	RDNS     string `json:"rdns"`
+	PortInfo struct {
+		Port      int    `json:"port"`
+		Service   string `json:"service"`
+		App       string `json:"app"`
+		Version   string `json:"version"`
+		Hostname  string `json:"hostname"`
+		Transport string `json:"transport"`
+	} `json:"portinfo"`
+	GeoInfo struct {
+		Country struct {
+			Code  string            `json:"code"`
+			Names map[string]string `json:"names"`
+		} `json:"country"`
+		City struct {
+			Names map[string]string `json:"names"`
+		} `json:"city"`
+		Location struct {
+			Lat float64 `json:"lat"`
+			Lon float64 `json:"lon"`
+		} `json:"location"`
+		ASN          json.Number `json:"asn"`
+		Organization string      `json:"organization"`
+		ISP          string      `json:"isp"`
+	} `json:"geoinfo"`
+	SSL string `json:"ssl"` // certificate in text form
+}
+
+// ZoomEyeQueries returns the ZoomEye host searches run for domain.
+func ZoomEyeQueries(domain string) []string {
+	return []string{
+		"hostname:" + domain,
+		`ssl:"` + domain + `"`,
+	}
+}
+
+// HasZoomEye reports whether the client has a ZoomEye API key
+func (c *Client) HasZoomEye() bool {
+	return c.ZoomEyeKey != ""
+}
+
+// ZoomEyeSearch runs a ZoomEye host search, fetching up to maxPages pages
+// (zero or less fetches all), and returns the results as Shodan-style
+// Matches, so they go through the same result pipeline. Like SearchAll, the
+// matches collected so far are returned with a later error.
+func (c *Client) ZoomEyeSearch(ctx context.Context, query string, maxPages int) (*SearchResult, error) {
+	if !c.HasZoomEye() {
+		return nil, errors.New("zoomeye API key is not set")
+	}
+	base := c.ZoomEyeURL
+	if base == "" {
+		base = DefaultZoomEyeURL
+	}
+
+	all := &SearchResult{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		params := url.Values{"query": {query}, "page": {strconv.Itoa(page)}}
+		var resp struct {
+			Total   int           `json:"total"`
+			Matches []zoomEyeHost `json:"matches"`
+		}
+		err := sourceError("zoomeye", c.call(ctx, request{
+			source: SourceZoomEye,
+			method: http.MethodGet,
+			url:    strings.TrimRight(base, "/") + "/host/search?" + params.Encode(),
+			header: http.Header{"Api-Key": {c.ZoomEyeKey}},
+		}, &resp))
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			return all, err
+		}
+		if page == 1 {
+			all.Total = resp.Total
+		}
+		for i := range resp.Matches {
+			all.Matches = append(all.Matches, resp.Matches[i].match())
+		}
+		if len(resp.Matches) < ZoomEyePageSize || page*ZoomEyePageSize >= resp.Total {
+			break
+		}
+	}
+	return all, nil
+}
+
+// match converts a ZoomEye host to a Match
+func (h *zoomEyeHost) match() Match {
+	geo := &h.GeoInfo
+	m := Match{
+		IPStr:     h.IP,
+		Port:      h.PortInfo.Port,
+		Transport: h.PortInfo.Transport,
+		Product:   h.PortInfo.App,
+		Version:   h.PortInfo.Version,
+		Org:       geo.Organization,
+		ISP:       geo.ISP,
+		Location: Location{
+			City:        geo.City.Names["en"],
+			CountryCode: geo.Country.Code,
+			CountryName: geo.Country.Names["en"],
+			Latitude:    geo.Location.Lat,
+			Longitude:   geo.Location.Lon,
+		},
+		Shodan: ShodanMeta{Module: h.PortInfo.Service},
+	}
+	if asn := geo.ASN.String(); asn != "" && asn != "0" {
+		m.ASN = "AS" + asn
+	}
+	for _, name := range []string{h.PortInfo.Hostname, h.RDNS} {
+		if name = NormalizeHostname(name); name != "" && name != h.IP {
+			m.Hostnames = append(m.Hostnames, name)
+		}
+	}
+	if h.SSL != "" {
+		m.addCertText(h.SSL)
+	}
+	m.Hostnames = Unique(m.Hostnames)
+	return m
+}
+
+// zoomEyeQuota returns the ZoomEye search credits left
+func (c *Client) zoomEyeQuota(ctx context.Context) (string, error) {
+	if !c.HasZoomEye() {
+		return "", ErrNotConfigured
+	}
+	var resp struct {
+		Plan      string `json:"plan"`
+		QuotaInfo struct {
+			RemainTotalQuota int `json:"remain_total_quota"`
+		} `json:"quota_info"`
+	}
+	err := sourceError("zoomeye", c.call(ctx, request{
+		source: SourceZoomEye,
+		method: http.MethodGet,
+		url:    strings.TrimRight(urlOr(c.ZoomEyeURL, DefaultZoomEyeURL), "/") + "/resources-info",
+		header: http.Header{"Api-Key": {c.ZoomEyeKey}},
+	}, &resp))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d credits left (%s plan)", resp.QuotaInfo.RemainTotalQuota, resp.Plan), nil
+}
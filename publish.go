@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// assetEvent is the message published for each asset of a run
+type assetEvent struct {
+	// Event is "new" for assets first found by this run, else "seen"
+	Event        string    `json:"event"`
+	Name         string    `json:"name"`
+	Domain       string    `json:"domain"`
+	IPs          []string  `json:"ips,omitempty"`
+	Ports        []int     `json:"ports,omitempty"`
+	Sources      []string  `json:"sources,omitempty"`
+	Technologies []string  `json:"technologies,omitempty"`
+	Risk         int       `json:"risk"`
+	FirstSeen    time.Time `json:"first_seen"`
+	Seen         time.Time `json:"seen"`
+}
+
+// assetEvents returns the events of the subdomains of a result found by a
+// run started at started
+func assetEvents(result *shodanx.Result, started time.Time) []assetEvent {
+	now := time.Now().UTC().Truncate(time.Second)
+	started = started.UTC().Truncate(time.Second)
+	events := make([]assetEvent, 0, len(result.Subdomains))
+	for _, name := range result.Subdomains {
+		e := assetEvent{
+			Event:        "seen",
+			Name:         name,
+			Domain:       result.Domain,
+			IPs:          result.HostAddresses(name),
+			Ports:        result.HostPorts(name),
+			Sources:      result.Sources[name],
+			Technologies: result.HostTechnologies(name),
+			Risk:         result.RiskOf(name),
+			FirstSeen:    started,
+			Seen:         now,
+		}
+		if t, ok := result.FirstSeen[name]; ok {
+			e.FirstSeen = t
+		}
+		if !e.FirstSeen.Before(started) {
+			e.Event = "new"
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// kafkaPublisher publishes an event per asset to a Kafka topic, see
+// --export kafka. Messages are keyed by asset name, so all events of an
+// asset land in the same partition and compacted topics keep its latest state.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+	name   string
+}
+
+// newKafkaPublisher publishes to topic on the comma-separated brokers
+func newKafkaPublisher(brokers, topic string) *kafkaPublisher {
+	addrs := splitList(brokers)
+	return &kafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(addrs...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+		name: fmt.Sprintf("Kafka topic %s on %s", topic, strings.Join(addrs, ",")),
+	}
+}
+
+// Close flushes pending messages and closes the connections
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// publish sends the asset events of a run
+func (p *kafkaPublisher) publish(events []assetEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	msgs := make([]kafka.Message, len(events))
+	for i, e := range events {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		msgs[i] = kafka.Message{Key: []byte(e.Name), Value: value}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return p.writer.WriteMessages(ctx, msgs...)
+}
+
+// natsPublisher publishes an event per asset to NATS, see --export nats.
+// Events go to <subject>.new and <subject>.seen, so consumers that only
+// react to new findings subscribe to <subject>.new.
+type natsPublisher struct {
+	conn    *nats.Conn
+	subject string
+	name    string
+}
+
+// newNATSPublisher connects to the NATS server at url
+func newNATSPublisher(url, subject string) (*natsPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("shodanX"))
+	if err != nil {
+		return nil, err
+	}
+	name := "NATS subject " + subject + " on " + conn.ConnectedUrlRedacted()
+	return &natsPublisher{conn: conn, subject: subject, name: name}, nil
+}
+
+// Close disconnects; publish already flushed the messages
+func (p *natsPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
+
+// publish sends the asset events of a run and waits until the server has
+// received them
+func (p *natsPublisher) publish(events []assetEvent) error {
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if err := p.conn.Publish(p.subject+"."+e.Event, data); err != nil {
+			return err
+		}
+	}
+	return p.conn.FlushTimeout(time.Minute)
+}
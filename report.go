@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// assetGroup is a set of subdomains with the same status in a report
+type assetGroup struct {
+	Title string
+	Names []string
+}
+
+// statusGroups groups the subdomains of a result by what is known about
+// them: a live web server, addresses, no addresses, or not resolved at all.
+// Empty groups are left out.
+func statusGroups(result *shodanx.Result) []assetGroup {
+	unresolved := map[string]bool{}
+	for _, name := range result.Unresolved {
+		unresolved[name] = true
+	}
+	groups := []assetGroup{{Title: "Live web servers"}, {Title: "Resolving"}, {Title: "Not resolving"}, {Title: "Not checked"}}
+	for _, name := range result.Subdomains {
+		switch {
+		case result.Probes[name] != nil:
+			groups[0].Names = append(groups[0].Names, name)
+		case len(result.IPs[name]) > 0:
+			groups[1].Names = append(groups[1].Names, name)
+		case unresolved[name]:
+			groups[2].Names = append(groups[2].Names, name)
+		default:
+			groups[3].Names = append(groups[3].Names, name)
+		}
+	}
+	var kept []assetGroup
+	for _, g := range groups {
+		if len(g.Names) > 0 {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
+
+// reportSummary returns the figures at the top of reports as label/count rows
+func reportSummary(result *shodanx.Result, groups []assetGroup) [][]string {
+	summary := [][]string{{"Subdomains", strconv.Itoa(len(result.Subdomains))}}
+	for _, g := range groups {
+		summary = append(summary, []string{g.Title, strconv.Itoa(len(g.Names))})
+	}
+	if n := len(result.Addresses); n > 0 {
+		summary = append(summary, []string{"Bare IP addresses", strconv.Itoa(n)})
+	}
+	return append(summary,
+		[]string{"Subdomains at risk", strconv.Itoa(len(result.Risks))},
+		[]string{"High-risk services exposed", strconv.Itoa(len(result.Exposed))},
+		[]string{"CVEs", strconv.Itoa(len(result.VulnReport()))},
+		[]string{"Certificate issues", strconv.Itoa(len(result.CertFindings()))},
+	)
+}
+
+// mdCell escapes a value for a Markdown table cell
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// mdTable writes a Markdown table
+func mdTable(b *strings.Builder, header []string, rows [][]string) {
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = mdCell(c)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	b.WriteString("\n")
+}
+
+// markdownReport renders a result as a Markdown report: summary figures,
+// the subdomains grouped by status and a section per kind of finding
+func markdownReport(result *shodanx.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# shodanX report: %s\n\n", result.Domain)
+	fmt.Fprintf(&b, "Generated %s\n\n", time.Now().UTC().Format(time.RFC3339))
+
+	b.WriteString("## Summary\n\n")
+	groups := statusGroups(result)
+	mdTable(&b, []string{"", "Count"}, reportSummary(result, groups))
+
+	for _, g := range groups {
+		fmt.Fprintf(&b, "## %s (%d)\n\n", g.Title, len(g.Names))
+		var rows [][]string
+		for _, name := range g.Names {
+			var ports []string
+			for _, port := range result.HostPorts(name) {
+				ports = append(ports, strconv.Itoa(port))
+			}
+			web := ""
+			if p := result.Probes[name]; p != nil {
+				web = formatProbe(p)
+			}
+			rows = append(rows, []string{displayName(name), strings.Join(result.HostAddresses(name), ", "),
+				strings.Join(ports, ", "), strings.Join(result.HostTechnologies(name), ", "), web,
+				strconv.Itoa(result.RiskOf(name))})
+		}
+		mdTable(&b, []string{"Subdomain", "IPs", "Ports", "Technologies", "Web server", "Risk"}, rows)
+	}
+
+	b.WriteString("## Findings\n\n")
+	findings := false
+	if len(result.Risks) > 0 {
+		findings = true
+		b.WriteString("### Riskiest subdomains\n\n")
+		var rows [][]string
+		for _, risk := range result.Risks {
+			factors := make([]string, len(risk.Factors))
+			for i, f := range risk.Factors {
+				factors[i] = fmt.Sprintf("%s (+%d)", f.Reason, f.Points)
+			}
+			rows = append(rows, []string{risk.Host, strconv.Itoa(risk.Score), strings.Join(factors, ", ")})
+		}
+		mdTable(&b, []string{"Subdomain", "Score", "Factors"}, rows)
+	}
+	if len(result.Exposed) > 0 {
+		findings = true
+		b.WriteString("### Exposed high-risk services\n\n")
+		var rows [][]string
+		for _, e := range result.Exposed {
+			rows = append(rows, []string{e.Service, fmt.Sprintf("%s:%d", e.IP, e.Port),
+				strings.Join(e.Hostnames, ", "), e.Product, e.Source})
+		}
+		mdTable(&b, []string{"Service", "Address", "Hostnames", "Product", "Source"}, rows)
+	}
+	if report := result.VulnReport(); len(report) > 0 {
+		findings = true
+		b.WriteString("### Vulnerabilities\n\n")
+		var rows [][]string
+		for _, v := range report {
+			cvss := "-"
+			if v.CVSS > 0 {
+				cvss = fmt.Sprintf("%.1f", v.CVSS)
+			}
+			verified := ""
+			if v.Verified {
+				verified = "yes"
+			}
+			rows = append(rows, []string{v.CVE, cvss, shodanx.Severity(v.CVSS), fmt.Sprintf("%s:%d", v.IP, v.Port),
+				strings.Join(v.Hostnames, ", "), verified})
+		}
+		mdTable(&b, []string{"CVE", "CVSS", "Severity", "Address", "Hostnames", "Verified"}, rows)
+	}
+	if certs := result.CertFindings(); len(certs) > 0 {
+		findings = true
+		b.WriteString("### Certificate issues\n\n")
+		for i := range certs {
+			fmt.Fprintf(&b, "- %s\n", formatCertFinding(&certs[i]))
+		}
+		b.WriteString("\n")
+	}
+	if len(result.Leaks) > 0 {
+		findings = true
+		b.WriteString("### Internal hostname leaks\n\n")
+		for _, l := range result.Leaks {
+			fmt.Fprintf(&b, "- `%s` (%s) seen on %s\n", l.Name, l.Reason, strings.Join(l.Seen, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if len(result.Buckets) > 0 {
+		findings = true
+		b.WriteString("### Storage buckets\n\n")
+		var rows [][]string
+		for _, bk := range result.Buckets {
+			access := bk.Access
+			if access == "" {
+				access = "unknown"
+			}
+			rows = append(rows, []string{bk.Provider, bk.Name, access, bk.Host, bk.CNAME})
+		}
+		mdTable(&b, []string{"Provider", "Bucket", "Access", "Host", "CNAME"}, rows)
+	}
+	var mailNames []string
+	for name, rec := range result.Mail {
+		if len(rec.Issues) > 0 {
+			mailNames = append(mailNames, name)
+		}
+	}
+	if len(mailNames) > 0 {
+		findings = true
+		sort.Strings(mailNames)
+		b.WriteString("### Email security\n\n")
+		for _, name := range mailNames {
+			for _, issue := range result.Mail[name].Issues {
+				fmt.Fprintf(&b, "- %s: %s\n", name, issue)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if !findings {
+		b.WriteString("No findings.\n")
+	}
+	return b.String()
+}
+
+// saveMarkdown writes the Markdown report of a result to <prefix>.md
+func saveMarkdown(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	mdFile := outputPrefix + ".md"
+	if err := os.WriteFile(mdFile, []byte(markdownReport(result)), 0644); err != nil {
+		fmt.Fprintf(w, "Error: Failed to save Markdown file %s: %v\n", mdFile, err)
+		return err
+	}
+	fmt.Fprintln(w, "[+] Markdown report saved to", mdFile)
+	return nil
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// htmlChartTop is the number of bars in the charts of the HTML report
+const htmlChartTop = 15
+
+// htmlTable is a sortable, filterable table of the HTML report
+type htmlTable struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// htmlBar is a bar of a chart, Width in percent of the largest bar
+type htmlBar struct {
+	Label string
+	Count int
+	Width int
+}
+
+// htmlChart is a horizontal bar chart
+type htmlChart struct {
+	Title string
+	Bars  []htmlBar
+}
+
+// htmlScreenshot is a screenshot embedded in the report
+type htmlScreenshot struct {
+	Name string
+	URL  string
+	Data template.URL
+}
+
+type htmlReport struct {
+	Domain      string
+	Generated   string
+	Summary     [][]string
+	Charts      []htmlChart
+	Tables      []htmlTable
+	Screenshots []htmlScreenshot
+}
+
+// newChart counts values and keeps the htmlChartTop most frequent as bars
+func newChart(title string, values []string) htmlChart {
+	counts := map[string]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+	chart := htmlChart{Title: title}
+	for label, n := range counts {
+		chart.Bars = append(chart.Bars, htmlBar{Label: label, Count: n})
+	}
+	sort.Slice(chart.Bars, func(i, j int) bool {
+		a, b := chart.Bars[i], chart.Bars[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Label < b.Label
+	})
+	if len(chart.Bars) > htmlChartTop {
+		chart.Bars = chart.Bars[:htmlChartTop]
+	}
+	for i := range chart.Bars {
+		chart.Bars[i].Width = chart.Bars[i].Count * 100 / chart.Bars[0].Count
+	}
+	return chart
+}
+
+// htmlReportData collects what the HTML report shows. Screenshots are read
+// from disk and embedded, so the report is a single file.
+func htmlReportData(w io.Writer, result *shodanx.Result) *htmlReport {
+	groups := statusGroups(result)
+	report := &htmlReport{
+		Domain:    result.Domain,
+		Generated: time.Now().UTC().Format(time.RFC3339),
+		Summary:   reportSummary(result, groups),
+	}
+
+	assets := htmlTable{Title: "Subdomains", Header: []string{"Subdomain", "Status", "IPs", "Ports", "Technologies", "Web server", "Sources", "Risk"}}
+	var ports, techs []string
+	for _, g := range groups {
+		for _, name := range g.Names {
+			var list []string
+			for _, port := range result.HostPorts(name) {
+				list = append(list, strconv.Itoa(port))
+			}
+			ports = append(ports, list...)
+			tech := result.HostTechnologies(name)
+			techs = append(techs, tech...)
+			web := ""
+			if p := result.Probes[name]; p != nil {
+				web = formatProbe(p)
+			}
+			assets.Rows = append(assets.Rows, []string{displayName(name), g.Title, strings.Join(result.HostAddresses(name), ", "),
+				strings.Join(list, ", "), strings.Join(tech, ", "), web, strings.Join(result.Sources[name], ", "),
+				strconv.Itoa(result.RiskOf(name))})
+		}
+	}
+	report.Tables = append(report.Tables, assets)
+	if len(ports) > 0 {
+		report.Charts = append(report.Charts, newChart("Open ports", ports))
+	}
+	if len(techs) > 0 {
+		report.Charts = append(report.Charts, newChart("Technologies", techs))
+	}
+
+	if len(result.Risks) > 0 {
+		t := htmlTable{Title: "Riskiest subdomains", Header: []string{"Subdomain", "Score", "Factors"}}
+		for _, risk := range result.Risks {
+			factors := make([]string, len(risk.Factors))
+			for i, f := range risk.Factors {
+				factors[i] = fmt.Sprintf("%s (+%d)", f.Reason, f.Points)
+			}
+			t.Rows = append(t.Rows, []string{risk.Host, strconv.Itoa(risk.Score), strings.Join(factors, ", ")})
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	if len(result.Exposed) > 0 {
+		t := htmlTable{Title: "Exposed high-risk services", Header: []string{"Service", "Address", "Hostnames", "Product", "Source"}}
+		for _, e := range result.Exposed {
+			t.Rows = append(t.Rows, []string{e.Service, fmt.Sprintf("%s:%d", e.IP, e.Port),
+				strings.Join(e.Hostnames, ", "), e.Product, e.Source})
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	if vulns := result.VulnReport(); len(vulns) > 0 {
+		t := htmlTable{Title: "Vulnerabilities", Header: []string{"CVE", "CVSS", "Severity", "Address", "Hostnames", "Summary"}}
+		for _, v := range vulns {
+			cvss := ""
+			if v.CVSS > 0 {
+				cvss = fmt.Sprintf("%.1f", v.CVSS)
+			}
+			t.Rows = append(t.Rows, []string{v.CVE, cvss, shodanx.Severity(v.CVSS), fmt.Sprintf("%s:%d", v.IP, v.Port),
+				strings.Join(v.Hostnames, ", "), v.Summary})
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	if certs := result.CertFindings(); len(certs) > 0 {
+		t := htmlTable{Title: "Certificate issues", Header: []string{"Finding"}}
+		for i := range certs {
+			t.Rows = append(t.Rows, []string{formatCertFinding(&certs[i])})
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	if len(result.Buckets) > 0 {
+		t := htmlTable{Title: "Storage buckets", Header: []string{"Provider", "Bucket", "Access", "Host", "CNAME"}}
+		for _, b := range result.Buckets {
+			t.Rows = append(t.Rows, []string{b.Provider, b.Name, b.Access, b.Host, b.CNAME})
+		}
+		report.Tables = append(report.Tables, t)
+	}
+
+	for _, name := range result.Subdomains {
+		p := result.Probes[name]
+		if p == nil || p.Screenshot == "" {
+			continue
+		}
+		data, err := os.ReadFile(p.Screenshot)
+		if err != nil {
+			fmt.Fprintf(w, "[!] Screenshot of %s not embedded: %v\n", name, err)
+			continue
+		}
+		report.Screenshots = append(report.Screenshots, htmlScreenshot{Name: name, URL: p.URL,
+			Data: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(data))})
+	}
+	return report
+}
+
+// saveHTML writes the standalone HTML report of a result to <prefix>.html
+func saveHTML(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	htmlFile := outputPrefix + ".html"
+	file, err := os.Create(htmlFile)
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to create HTML file %s: %v\n", htmlFile, err)
+		return err
+	}
+	err = htmlReportTemplate.Execute(file, htmlReportData(w, result))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to save HTML file %s: %v\n", htmlFile, err)
+		return err
+	}
+	fmt.Fprintln(w, "[+] HTML report saved to", htmlFile)
+	return nil
+}
+
+// htmlReportTemplate needs no external assets so the report can be mailed
+// or attached to a ticket as is
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>shodanX report: {{.Domain}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0; }
+.meta { color: #777; margin-bottom: 2em; }
+table { border-collapse: collapse; margin: 0.5em 0 2em; font-size: 0.9em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; cursor: pointer; user-select: none; white-space: nowrap; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:nth-child(even) td { background: #fafafa; }
+input.filter { padding: 4px; width: 20em; }
+.charts { display: flex; flex-wrap: wrap; gap: 3em; }
+.chart { min-width: 24em; }
+.bar { display: flex; align-items: center; margin: 2px 0; font-size: 0.85em; }
+.bar .label { width: 12em; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.bar .fill { background: #3b7dd8; height: 1em; margin-right: 0.5em; }
+.shots { display: flex; flex-wrap: wrap; gap: 1em; }
+.shots figure { margin: 0; width: 320px; }
+.shots img { width: 100%; border: 1px solid #ddd; }
+.shots figcaption { font-size: 0.85em; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>shodanX report: {{.Domain}}</h1>
+<div class="meta">Generated {{.Generated}}</div>
+
+<h2>Summary</h2>
+<table>
+{{range .Summary}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+{{if .Charts}}<div class="charts">
+{{range .Charts}}<div class="chart">
+<h2>{{.Title}}</h2>
+{{range .Bars}}<div class="bar"><span class="label" title="{{.Label}}">{{.Label}}</span><span class="fill" style="width: {{.Width}}%; max-width: 20em"></span>{{.Count}}</div>
+{{end}}</div>
+{{end}}</div>
+{{end}}
+{{range .Tables}}<h2>{{.Title}} ({{len .Rows}})</h2>
+<input class="filter" type="search" placeholder="Filter">
+<table class="sortable">
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}
+{{if .Screenshots}}<h2>Screenshots ({{len .Screenshots}})</h2>
+<div class="shots">
+{{range .Screenshots}}<figure><a href="{{.URL}}"><img src="{{.Data}}" alt="{{.Name}}" loading="lazy"></a><figcaption>{{.Name}}</figcaption></figure>
+{{end}}</div>
+{{end}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  var body = table.tBodies[0];
+  var filter = table.previousElementSibling;
+  filter.addEventListener("input", function () {
+    var q = filter.value.toLowerCase();
+    Array.from(body.rows).forEach(function (row) {
+      row.style.display = row.textContent.toLowerCase().indexOf(q) >= 0 ? "" : "none";
+    });
+  });
+  table.querySelectorAll("th").forEach(function (th, col) {
+    th.addEventListener("click", function () {
+      var asc = !th.classList.contains("asc");
+      table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+      th.classList.add(asc ? "asc" : "desc");
+      var rows = Array.from(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[col].textContent, y = b.cells[col].textContent;
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var cmp = !isNaN(nx) && !isNaN(ny) ? nx - ny : x.localeCompare(y);
+        return asc ? cmp : -cmp;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// SARIF 2.1.0 documents, as much of the format as GitHub and GitLab read
+type (
+	sarifText struct {
+		Text string `json:"text"`
+	}
+	sarifRule struct {
+		ID                   string    `json:"id"`
+		Name                 string    `json:"name"`
+		ShortDescription     sarifText `json:"shortDescription"`
+		FullDescription      sarifText `json:"fullDescription"`
+		Help                 sarifText `json:"help"`
+		DefaultConfiguration struct {
+			Level string `json:"level"`
+		} `json:"defaultConfiguration"`
+		Properties struct {
+			Tags []string `json:"tags"`
+			// SecuritySeverity is the 0-10 score GitHub ranks alerts by
+			SecuritySeverity string `json:"security-severity"`
+		} `json:"properties"`
+	}
+	sarifLocation struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine int `json:"startLine"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	}
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		Level               string            `json:"level"`
+		Message             sarifText         `json:"message"`
+		Locations           []sarifLocation   `json:"locations"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+	}
+	sarifRun struct {
+		Tool struct {
+			Driver struct {
+				Name           string      `json:"name"`
+				InformationURI string      `json:"informationUri"`
+				Rules          []sarifRule `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		AutomationDetails struct {
+			ID string `json:"id"`
+		} `json:"automationDetails"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+)
+
+// sarifRules describe the kinds of findings; CVEs get a rule each, see
+// sarifReport
+var sarifRules = map[string]struct {
+	name, short, full, level, severity string
+}{
+	"takeover": {"SubdomainTakeover", "Subdomain takeover candidate",
+		"The subdomain points at a resource nobody holds: a CNAME that no longer resolves or an unclaimed storage bucket. Whoever claims it serves content under the subdomain.",
+		"error", "9.0"},
+	"exposed-service": {"ExposedService", "High-risk service exposed to the internet",
+		"A database, remote desktop, file share or other service that should not be reachable from the internet is open.",
+		"error", "8.0"},
+	"public-bucket": {"PublicBucket", "Publicly listable storage bucket",
+		"Anyone can list the objects of a storage bucket the subdomain points at.",
+		"error", "7.5"},
+	"expired-certificate": {"ExpiredCertificate", "Expired TLS certificate",
+		"A service presents an expired TLS certificate; clients warn or refuse to connect.",
+		"warning", "5.0"},
+	"self-signed-certificate": {"SelfSignedCertificate", "Self-signed TLS certificate",
+		"A service presents a self-signed TLS certificate, which clients cannot verify.",
+		"warning", "4.0"},
+}
+
+// sarifLevel maps a CVSS score to a SARIF level
+func sarifLevel(cvss float64) string {
+	switch {
+	case cvss >= 7:
+		return "error"
+	case cvss >= 4:
+		return "warning"
+	}
+	return "note"
+}
+
+// sarifReport converts the findings of a result to a SARIF log. Each
+// finding is located at its host, and fingerprinted by rule and host so code
+// scanning tracks it across scheduled runs instead of reopening it.
+func sarifReport(result *shodanx.Result) *sarifLog {
+	var run sarifRun
+	run.Tool.Driver.Name = "shodanX"
+	run.Tool.Driver.InformationURI = "https://github.com/moatasem121/shodanX"
+	run.AutomationDetails.ID = "shodanx/" + result.Domain + "/"
+	run.Results = []sarifResult{}
+	run.Tool.Driver.Rules = []sarifRule{}
+
+	rules := map[string]bool{}
+	addRule := func(id, name, short, full, level, severity string, tags ...string) {
+		if rules[id] {
+			return
+		}
+		rules[id] = true
+		rule := sarifRule{ID: id, Name: name, ShortDescription: sarifText{short}, FullDescription: sarifText{full},
+			Help: sarifText{full}}
+		rule.DefaultConfiguration.Level = level
+		rule.Properties.Tags = append([]string{"security", "attack-surface"}, tags...)
+		rule.Properties.SecuritySeverity = severity
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	}
+	add := func(ruleID, level, host, message string) {
+		if r, ok := sarifRules[ruleID]; ok {
+			addRule(ruleID, r.name, r.short, r.full, r.level, r.severity)
+			if level == "" {
+				level = r.level
+			}
+		}
+		sum := sha256.Sum256([]byte(ruleID + "|" + host))
+		res := sarifResult{RuleID: ruleID, Level: level, Message: sarifText{message},
+			PartialFingerprints: map[string]string{"shodanxFinding/v1": hex.EncodeToString(sum[:16])}}
+		// A relative path, as host:port alone would parse as a URI scheme
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = "hosts/" + host
+		loc.PhysicalLocation.Region.StartLine = 1
+		res.Locations = []sarifLocation{loc}
+		run.Results = append(run.Results, res)
+	}
+
+	unresolved := map[string]bool{}
+	for _, name := range result.Unresolved {
+		unresolved[name] = true
+	}
+	for _, name := range result.Subdomains {
+		if cname := result.CNAMEs[name]; cname != "" && unresolved[name] {
+			add("takeover", "", name, fmt.Sprintf("%s is a CNAME to %s, which does not resolve", name, cname))
+		}
+	}
+	for _, b := range result.Buckets {
+		switch b.Access {
+		case shodanx.BucketMissing:
+			add("takeover", "", b.Host, fmt.Sprintf("%s points at the unclaimed %s bucket %s", b.Host, b.Provider, b.Name))
+		case shodanx.BucketListable:
+			add("public-bucket", "", b.Host, fmt.Sprintf("%s points at the publicly listable %s bucket %s", b.Host, b.Provider, b.Name))
+		}
+	}
+	for _, e := range result.Exposed {
+		msg := fmt.Sprintf("%s is exposed on %s:%d", e.Service, e.IP, e.Port)
+		if e.Product != "" {
+			msg += " (" + e.Product + ")"
+		}
+		if len(e.Hostnames) > 0 {
+			msg += ", serving " + strings.Join(e.Hostnames, ", ")
+		}
+		add("exposed-service", "", fmt.Sprintf("%s:%d", e.IP, e.Port), msg)
+	}
+	for _, f := range result.CertFindings() {
+		host := f.IP
+		if host == "" {
+			host = f.Hostnames[0] // probed hosts
+		}
+		host += ":" + strconv.Itoa(f.Port)
+		if f.Expired {
+			add("expired-certificate", "", host, fmt.Sprintf("The certificate of %s (CN=%s) expired on %s", host, f.Subject, f.Expires))
+		}
+		if f.SelfSigned {
+			add("self-signed-certificate", "", host, fmt.Sprintf("The certificate of %s (CN=%s) is self-signed", host, f.Subject))
+		}
+	}
+	for _, v := range result.VulnReport() {
+		severity := "0.0"
+		if v.CVSS > 0 {
+			severity = strconv.FormatFloat(v.CVSS, 'f', 1, 64)
+		}
+		addRule(v.CVE, v.CVE, v.CVE, v.Summary, sarifLevel(v.CVSS), severity, "vulnerability")
+		host := fmt.Sprintf("%s:%d", v.IP, v.Port)
+		add(v.CVE, sarifLevel(v.CVSS), host, fmt.Sprintf("%s (%s) on %s, according to Shodan", v.CVE, shodanx.Severity(v.CVSS), host))
+	}
+	return &sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
+
+// saveSARIF writes the findings of a result to <prefix>.sarif
+func saveSARIF(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	sarifFile := outputPrefix + ".sarif"
+	report := sarifReport(result)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(sarifFile, data, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to save SARIF file %s: %v\n", sarifFile, err)
+		return err
+	}
+	fmt.Fprintf(w, "[+] %d findings saved to %s\n", len(report.Runs[0].Results), sarifFile)
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestSARIFReport(t *testing.T) {
+	result := &shodanx.Result{
+		Domain:     "example.com",
+		Subdomains: []string{"old.example.com", "www.example.com"},
+		CNAMEs:     map[string]string{"old.example.com": "old-app.herokuapp.com", "www.example.com": "cdn.example.net"},
+		Unresolved: []string{"old.example.com"},
+		Buckets: []shodanx.Bucket{
+			{Host: "assets.example.com", Provider: "S3", Name: "example-assets", Access: shodanx.BucketListable},
+			{Host: "files.example.com", Provider: "S3", Name: "example-files", Access: shodanx.BucketPrivate},
+		},
+		Exposed: []shodanx.Exposure{{IP: "192.0.2.1", Port: 6379, Service: "Redis"}},
+		Probes: map[string]*shodanx.Probe{"www.example.com": {
+			Cert: &shodanx.CertFinding{Port: 443, Hostnames: []string{"www.example.com"}, SelfSigned: true},
+		}},
+		Vulns: map[string]*shodanx.HostVulns{"192.0.2.1": {CVEs: []shodanx.HostVuln{
+			{CVE: "CVE-2021-44228", CVSS: 10, Port: 8080},
+			{CVE: "CVE-2020-0001", CVSS: 5.5, Port: 22},
+		}}},
+	}
+	run := sarifReport(result).Runs[0]
+
+	type finding struct{ rule, level, uri string }
+	var got []finding
+	fingerprints := map[string]bool{}
+	for _, r := range run.Results {
+		got = append(got, finding{r.RuleID, r.Level, r.Locations[0].PhysicalLocation.ArtifactLocation.URI})
+		fingerprints[r.PartialFingerprints["shodanxFinding/v1"]] = true
+	}
+	want := []finding{
+		{"takeover", "error", "hosts/old.example.com"},
+		{"public-bucket", "error", "hosts/assets.example.com"},
+		{"exposed-service", "error", "hosts/192.0.2.1:6379"},
+		{"self-signed-certificate", "warning", "hosts/www.example.com:443"},
+		{"CVE-2021-44228", "error", "hosts/192.0.2.1:8080"},
+		{"CVE-2020-0001", "warning", "hosts/192.0.2.1:22"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if len(fingerprints) != len(want) {
+		t.Errorf("%d distinct fingerprints for %d results", len(fingerprints), len(want))
+	}
+	if again := sarifReport(result).Runs[0].Results[0].PartialFingerprints; !reflect.DeepEqual(again, run.Results[0].PartialFingerprints) {
+		t.Error("fingerprints differ between runs")
+	}
+
+	var rules []string
+	for _, r := range run.Tool.Driver.Rules {
+		rules = append(rules, r.ID)
+	}
+	if want := []string{"takeover", "public-bucket", "exposed-service", "self-signed-certificate", "CVE-2021-44228", "CVE-2020-0001"}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+	if run.AutomationDetails.ID != "shodanx/example.com/" {
+		t.Errorf("automation ID = %q", run.AutomationDetails.ID)
+	}
+}
+
+func TestSARIFLevel(t *testing.T) {
+	tests := []struct {
+		cvss float64
+		want string
+	}{
+		{0, "note"}, {3.9, "note"}, {4, "warning"}, {6.9, "warning"}, {7, "error"}, {10, "error"},
+	}
+	for _, tt := range tests {
+		if got := sarifLevel(tt.cvss); got != tt.want {
+			t.Errorf("sarifLevel(%v) = %q, want %q", tt.cvss, got, tt.want)
+		}
+	}
+}
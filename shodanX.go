@@ -1,306 +1,423 @@
 package main
 
 import (
-	"encoding/csv"
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
 )
 
-var shodanAPI = "https://api.shodan.io"
+// Exit codes, so scripts can tell API failures apart
+const (
+	exitError        = 1
+	exitUnauthorized = 3
+	exitNoCredits    = 4
+	exitRateLimited  = 5
+	exitCreditBudget = 6
+)
 
-// Search Shodan for a query and return hostnames
-func searchShodan(query, apiKey string) []string {
-	url := fmt.Sprintf("%s/shodan/host/search?key=%s&query=%s", shodanAPI, apiKey, query)
-	resp, err := http.Get(url)
-	if err != nil {
-		fmt.Println("Request failed:", err)
-		return nil
+// exitCode maps an error to the process exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, shodanx.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, shodanx.ErrNoCredits):
+		return exitNoCredits
+	case errors.Is(err, shodanx.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, shodanx.ErrCreditBudget):
+		return exitCreditBudget
 	}
-	defer resp.Body.Close()
+	return exitError
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Failed to read response:", err)
-		return nil
-	}
+// fatal prints err and exits with the matching exit code
+func fatal(err error) {
+	fatalTo(os.Stdout, err)
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Println("Failed to parse JSON response:", err)
-		return nil
+// fatalTo is fatal printing to w
+func fatalTo(w io.Writer, err error) {
+	fmt.Fprintln(w, "Error:", err)
+	switch {
+	case errors.Is(err, shodanx.ErrUnauthorized):
+		fmt.Fprintln(w, "Check that your Shodan API key is valid.")
+	case errors.Is(err, shodanx.ErrNoCredits):
+		fmt.Fprintln(w, "Your Shodan account has run out of query credits.")
+	case errors.Is(err, shodanx.ErrRateLimited):
+		fmt.Fprintln(w, "Shodan is rate limiting this key, try again later or lower --rate.")
+	case errors.Is(err, shodanx.ErrCreditBudget):
+		fmt.Fprintln(w, "The --max-credits budget for this run was reached.")
 	}
+	os.Exit(exitCode(err))
+}
 
-	subs := []string{}
-	if matches, ok := result["matches"].([]interface{}); ok {
-		for _, m := range matches {
-			if rec, ok := m.(map[string]interface{}); ok {
-				// Hostnames field
-				if hostnames, exists := rec["hostnames"].([]interface{}); exists {
-					for _, h := range hostnames {
-						if hostname, ok := h.(string); ok {
-							subs = append(subs, hostname)
-						}
-					}
-				}
-				// SSL SANs
-				if sslData, exists := rec["ssl"].(map[string]interface{}); exists {
-					if cert, exists := sslData["cert"].(map[string]interface{}); exists {
-						if san, exists := cert["subject"].(map[string]interface{}); exists {
-							for _, v := range san {
-								if s, ok := v.(string); ok && strings.Contains(s, ".") {
-									subs = append(subs, s)
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-	return subs
+// command is a single shodanx subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
 }
 
-// Get subdomains from Shodan DNS API
-func getDNSSubs(domain, apiKey string) []string {
-	url := fmt.Sprintf("%s/dns/domain/%s?key=%s", shodanAPI, domain, apiKey)
-	resp, err := http.Get(url)
-	if err != nil {
-		fmt.Println("DNS API request failed:", err)
-		return nil
+func commands() []command {
+	return []command{
+		{"enum", "Enumerate subdomains of a domain (default)", runEnum},
+		{"search", "Run a raw Shodan search query", runSearch},
+		{"host", "Show ports, banners, hostnames and vulns of an IP", runHost},
+		{"scan", "Submit IPs/CIDRs for on-demand scanning and collect the results", runScan},
+		{"alert", "Create, list and delete network alerts", runAlert},
+		{"data", "List and download bulk datasets (enterprise)", runData},
+		{"stream", "Emit live banners from the Streaming API as JSONL", runStream},
+		{"sources", "List sources or check their credentials, connectivity and quota", runSources},
+		{"ct-monitor", "Watch certificate transparency for new subdomains in real time", runCTMonitor},
+		// monitor is the short name; ct-monitor says what is watched, since
+		// the Shodan network alerts are under alert
+		{"monitor", "Same as ct-monitor", runCTMonitor},
+		{"internetdb", "Look up IPs or hostnames in the free InternetDB (no API key)", runInternetDB},
+		{"queries", "Search the community query directory", runQueries},
+		{"notifier", "Manage Shodan notifiers used to deliver alerts", runNotifier},
+		{"ports", "List the ports Shodan crawls", runPorts},
+		{"protocols", "List the protocols available for on-demand scans", runProtocols},
+		{"count", "Preview a domain's exposure with free result counts and facets", runCount},
+		{"dns", "List subdomains/records of a domain, or resolve/reverse via Shodan", runDNS},
+		{"auth", "Store or remove the API key in the OS keyring", runAuth},
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Failed to read DNS response:", err)
-		return nil
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [OPTIONS] <args>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [OPTIONS] <domain>   (same as enum)\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command options.\n", os.Args[0])
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Println("Failed to parse DNS JSON response:", err)
-		return nil
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		usage()
+		os.Exit(1)
 	}
-
-	subs := []string{}
-	if data, ok := result["subdomains"].([]interface{}); ok {
-		for _, s := range data {
-			if subdomain, ok := s.(string); ok {
-				subs = append(subs, fmt.Sprintf("%s.%s", subdomain, domain))
-			}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	}
+	for _, c := range commands() {
+		if c.name == args[0] {
+			c.run(args[1:])
+			return
 		}
 	}
-	return subs
+
+	// No subcommand: keep the classic `shodanx [OPTIONS] <domain>` form working
+	runEnum(args)
+}
+
+// options holds the flags shared by all subcommands plus the loaded config
+type options struct {
+	// keyOptional is set by commands that work without an API key
+	keyOptional bool
+
+	apiKey       string
+	apiKeyOrigin string // where apiKey was found, see lookupAPIKey
+	configPath   string
+	rate         float64
+	retries      int
+	maxCredits   int
+	cfg          *Config
+
+	// log receives the client's progress and warnings; nil means stdout
+	log io.Writer
 }
 
-// Remove duplicates
-func unique(input []string) []string {
-	seen := make(map[string]bool)
-	result := []string{}
-	for _, v := range input {
-		if !seen[v] {
-			seen[v] = true
-			result = append(result, v)
+// newFlagSet creates the flag set for a subcommand with the shared flags
+func newFlagSet(name, argsUsage string, examples ...string) (*flag.FlagSet, *options) {
+	opts := &options{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&opts.apiKey, "apikey", "", "Shodan API key (default $"+apiKeyEnv+", config file or OS keyring)")
+	fs.StringVar(&opts.configPath, "config", "", "YAML config file, or TOML if it ends in .toml (default "+defaultConfigPath()+")")
+	fs.Float64Var(&opts.rate, "rate", shodanx.DefaultRateLimit, "Maximum API requests per second (0 = unlimited)")
+	fs.IntVar(&opts.retries, "retries", shodanx.DefaultRetryPolicy.MaxRetries, "Retries for network errors and 429/5xx responses")
+	fs.IntVar(&opts.maxCredits, "max-credits", 0, "Abort before spending more than N query credits (0 = no limit)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s [OPTIONS] %s\n", os.Args[0], name, argsUsage)
+		for _, e := range examples {
+			fmt.Fprintf(os.Stderr, "Example: %s %s\n", os.Args[0], e)
 		}
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
 	}
-	return result
+	return fs, opts
 }
 
-// IMPROVED SAVING FUNCTION WITH ERROR HANDLING AND FALLBACK
-func saveResults(domain string, allSubs []string, queries []string, outputPrefix string) error {
-	// Create output directory if it doesn't exist
-	outputDir := filepath.Dir(outputPrefix)
-	if outputDir != "." && outputDir != "" {
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			fmt.Printf("Warning: Could not create directory %s: %v\n", outputDir, err)
+// parse parses the flags, loads the config and checks the positional
+// argument (unless argName is empty) and API key
+func (o *options) parse(fs *flag.FlagSet, args []string, argName string) {
+	fs.Parse(args)
+
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	o.cfg = cfg
+
+	// Explicit flags win over the config file, where the shodan provider
+	// entry wins over the top-level keys
+	shodan := cfg.provider("shodan")
+	if !isFlagSet(fs, "rate") {
+		if shodan.RateLimit != 0 {
+			o.rate = shodan.RateLimit
+		} else if cfg.RateLimit != 0 {
+			o.rate = cfg.RateLimit
 		}
 	}
-
-	// Always save TXT first (most reliable format)
-	txtFile := outputPrefix + ".txt"
-	txtContent := strings.Join(allSubs, "\n")
-	if err := os.WriteFile(txtFile, []byte(txtContent), 0644); err != nil {
-		fmt.Printf("Error: Failed to save TXT file %s: %v\n", txtFile, err)
-		return err
+	if !isFlagSet(fs, "retries") {
+		if shodan.Retries != nil {
+			o.retries = *shodan.Retries
+		} else if cfg.Retries != nil {
+			o.retries = *cfg.Retries
+		}
 	}
-	fmt.Println("[+] TXT results saved to", txtFile)
 
-	// Try to save JSON format
-	jsonFile := outputPrefix + ".json"
-	jsonData := map[string]interface{}{
-		"domain":       domain,
-		"total":        len(allSubs),
-		"queries_used": queries,
-		"subdomains":   allSubs,
-	}
+	o.apiKey, o.apiKeyOrigin = lookupAPIKey(o.apiKey, cfg)
 
-	// Attempt JSON marshaling with error handling
-	jsonBytes, err := json.MarshalIndent(jsonData, "", "  ")
-	if err != nil {
-		fmt.Printf("Warning: JSON marshaling failed: %v\n", err)
-		fmt.Println("[!] Falling back to CSV format...")
-		return saveCSVFallback(domain, allSubs, outputPrefix)
+	if argName != "" && fs.NArg() < 1 {
+		fmt.Printf("Error: %s argument is required!\n", argName)
+		fs.Usage()
+		os.Exit(1)
 	}
 
-	// Attempt JSON file writing with error handling
-	if err := os.WriteFile(jsonFile, jsonBytes, 0644); err != nil {
-		fmt.Printf("Warning: Failed to save JSON file %s: %v\n", jsonFile, err)
-		fmt.Println("[!] Falling back to CSV format...")
-		return saveCSVFallback(domain, allSubs, outputPrefix)
+	// Validate API key is provided (after parsing)
+	if o.apiKey == "" && !o.keyOptional {
+		fmt.Println("Error: Shodan API key is required!")
+		fs.Usage()
+		os.Exit(1)
 	}
+}
 
-	fmt.Println("[+] JSON results saved to", jsonFile)
-	return nil
+// isFlagSet reports whether the named flag was given on the command line
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
-// Fallback function to save as CSV if JSON fails
-func saveCSVFallback(domain string, allSubs []string, outputPrefix string) error {
-	csvFile := outputPrefix + ".csv"
-	file, err := os.Create(csvFile)
-	if err != nil {
-		fmt.Printf("Error: Failed to create CSV file %s: %v\n", csvFile, err)
-		return err
+// client builds a library client that logs progress to o.log
+func (o *options) client() *shodanx.Client {
+	w := o.log
+	if w == nil {
+		w = os.Stdout
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write CSV header
-	if err := writer.Write([]string{"Domain", "Subdomain"}); err != nil {
-		fmt.Printf("Error: Failed to write CSV header: %v\n", err)
-		return err
+	client := shodanx.NewClient(o.apiKey)
+	client.Logger = log.New(w, "", 0)
+	client.Limiter = shodanx.NewRateLimiter(o.rate, 1)
+	client.Retry.MaxRetries = o.retries
+	client.MaxCredits = o.maxCredits
+
+	// Other sources' credentials come from the environment, then the config file
+	censys := o.cfg.provider("censys")
+	client.CensysID, client.CensysSecret = os.Getenv(censysIDEnv), os.Getenv(censysSecretEnv)
+	if client.CensysID == "" {
+		client.CensysID, client.CensysSecret = censys.APIID, censys.Secret
+	}
+	if client.SecurityTrailsKey = os.Getenv(securityTrailsKeyEnv); client.SecurityTrailsKey == "" {
+		client.SecurityTrailsKey = o.cfg.provider("securitytrails").APIKey
+	}
+	if client.URLScanKey = os.Getenv(urlScanKeyEnv); client.URLScanKey == "" {
+		client.URLScanKey = o.cfg.provider("urlscan").APIKey
+	}
+	if client.BinaryEdgeKey = os.Getenv(binaryEdgeKeyEnv); client.BinaryEdgeKey == "" {
+		client.BinaryEdgeKey = o.cfg.provider("binaryedge").APIKey
+	}
+	client.FofaKey, client.FofaEmail = os.Getenv(fofaKeyEnv), os.Getenv(fofaEmailEnv)
+	if client.FofaKey == "" {
+		fofa := o.cfg.provider("fofa")
+		client.FofaKey, client.FofaEmail = fofa.APIKey, fofa.Email
+	}
+	if client.ZoomEyeKey = os.Getenv(zoomEyeKeyEnv); client.ZoomEyeKey == "" {
+		client.ZoomEyeKey = o.cfg.provider("zoomeye").APIKey
 	}
 
-	// Write subdomain data
-	for _, sub := range allSubs {
-		if err := writer.Write([]string{domain, sub}); err != nil {
-			fmt.Printf("Error: Failed to write CSV row: %v\n", err)
-			return err
+	// Endpoints and request settings of the providers section
+	endpoints := map[string]*string{
+		"shodan":         &client.BaseURL,
+		"anubis":         &client.AnubisURL,
+		"binaryedge":     &client.BinaryEdgeURL,
+		"censys":         &client.CensysURL,
+		"certstream":     &client.CertstreamURL,
+		"crtsh":          &client.CrtShURL,
+		"fofa":           &client.FofaURL,
+		"hackertarget":   &client.HackerTargetURL,
+		"rapiddns":       &client.RapidDNSURL,
+		"securitytrails": &client.SecurityTrailsURL,
+		"urlscan":        &client.URLScanURL,
+		"wayback":        &client.WaybackURL,
+		"zoomeye":        &client.ZoomEyeURL,
+	}
+	for name, p := range o.cfg.Providers {
+		if p.URL != "" {
+			if endpoint, ok := endpoints[name]; ok {
+				*endpoint = p.URL
+			} else {
+				fmt.Fprintf(w, "[!] Ignoring the URL of unknown provider %q in the config file\n", name)
+			}
+		}
+		if name == shodanx.SourceShodan {
+			// Its rate limit and retries replace --rate and --retries, see parse
+			if p.Concurrency != 0 {
+				fmt.Fprintln(w, "[!] Ignoring the concurrency of provider \"shodan\" in the config file, Shodan queries run one at a time")
+			}
+			p.RateLimit, p.Retries, p.Concurrency = 0, nil, 0
+		}
+		if p.RateLimit == 0 && p.Timeout == 0 && p.Retries == nil && p.Concurrency == 0 {
+			continue
+		}
+		if client.SourceSettings == nil {
+			client.SourceSettings = map[string]shodanx.SourceSettings{}
+		}
+		settings := shodanx.SourceSettings{Timeout: p.Timeout, Concurrency: p.Concurrency}
+		if p.RateLimit != 0 {
+			// Let up to Concurrency requests start at once
+			settings.Limiter = shodanx.NewRateLimiter(p.RateLimit, p.Concurrency)
 		}
+		if p.Retries != nil {
+			retry := client.Retry
+			retry.MaxRetries = *p.Retries
+			settings.Retry = &retry
+		}
+		client.SourceSettings[name] = settings
 	}
 
-	fmt.Println("[+] CSV results saved to", csvFile)
-	return nil
+	if o.cfg.Proxy != "" {
+		proxyURL, err := url.Parse(o.cfg.Proxy)
+		if err != nil {
+			fmt.Fprintf(w, "Error: Invalid proxy URL %q: %v\n", o.cfg.Proxy, err)
+			os.Exit(1)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		client.HTTPClient.Transport = transport
+	}
+	return client
 }
 
-func main() {
-	apiKey := flag.String("apikey", "", "Shodan API key (required)")
-	output := flag.String("output", "", "Output file name (without extension)")
-
-	// Custom usage message
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] <domain>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Example: %s --apikey YOUR_API_KEY --output results example.com\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Example: %s --apikey YOUR_API_KEY .mil\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "\nOptions:\n")
-		flag.PrintDefaults()
+// checkCredits prints the plan and remaining credits, and stops early if the
+// key cannot run any query
+func checkCredits(w io.Writer, ctx context.Context, client *shodanx.Client) {
+	info, err := client.APIInfo(ctx)
+	if err != nil {
+		if shodanx.IsFatal(err) {
+			fatal(err)
+		}
+		fmt.Fprintln(w, "[!] Could not fetch account info:", err)
+		return
 	}
 
-	// Parse flags first
-	flag.Parse()
-
-	// Check if domain argument is provided
-	if len(flag.Args()) < 1 {
-		fmt.Println("Error: Domain argument is required!")
-		fmt.Println("Usage: go run shodanX.go --apikey <your_api_key> [--output filename] <domain>")
-		fmt.Println("Example: go run shodanX.go --apikey YOUR_SHODAN_API_KEY --output mil .mil")
-		os.Exit(1)
+	fmt.Fprintf(w, "[*] Plan: %s, query credits left: %d, scan credits left: %d\n", info.Plan, info.QueryCredits, info.ScanCredits)
+	if info.QueryCredits <= 0 {
+		fatal(shodanx.ErrNoCredits)
 	}
-
-	// Validate API key is provided (after parsing)
-	if *apiKey == "" {
-		fmt.Println("Error: Shodan API key is required!")
-		fmt.Println("Usage: go run shodanX.go --apikey <your_api_key> [--output filename] <domain>")
-		fmt.Println("Example: go run shodanX.go --apikey YOUR_SHODAN_API_KEY --output mil .mil")
-		os.Exit(1)
+	if client.MaxCredits > info.QueryCredits {
+		fmt.Fprintf(w, "[!] --max-credits %d is above the %d credits left on this key\n", client.MaxCredits, info.QueryCredits)
 	}
+}
 
-	domain := flag.Arg(0)
-	fmt.Printf("[*] Starting scan for domain: %s\n", domain)
-	fmt.Printf("[*] Using API key: %s...\n", (*apiKey)[:8]+"***") // Show first 8 chars for confirmation
-
-	queries := []string{
-		// Basic hostname and SSL certificate queries
-		fmt.Sprintf("hostname:\"%s\"", domain),
-		fmt.Sprintf("ssl.cert.subject.cn:\"%s\"", domain),
-		fmt.Sprintf("ssl.cert.subject.an:\"%s\"", domain),
-		fmt.Sprintf("ssl.cert.issuer.cn:\"%s\"", domain),
-		fmt.Sprintf("ssl.cert.issuer.o:\"%s\"", domain),
-
-		// HTTP content queries
-		fmt.Sprintf("http.title:\"%s\"", domain),
-		fmt.Sprintf("http.html:\"%s\"", domain),
-		fmt.Sprintf("http.component:\"%s\"", domain),
-
-		// SSL Subject Alternative Names (SAN) - Critical for subdomains
-		fmt.Sprintf("ssl.cert.subject.alt_names:\"%s\"", domain),
-		fmt.Sprintf("ssl.cert.extensions.subject_alt_name:\"%s\"", domain),
-
-		// Server headers and metadata
-		fmt.Sprintf("http.server:\"%s\"", domain),
-		fmt.Sprintf("http.headers:\"%s\"", domain),
-		fmt.Sprintf("http.location:\"%s\"", domain),
-
-		// Mail servers and email-related services
-		fmt.Sprintf("smtp.starttls.tls.certificate.parsed.subject.common_name:\"%s\"", domain),
-		fmt.Sprintf("smtp.starttls.tls.certificate.parsed.extensions.subject_alt_name.dns_names:\"%s\"", domain),
-
-		// FTP services
-		fmt.Sprintf("ftp.banner:\"%s\"", domain),
-
-		// DNS-related queries
-		fmt.Sprintf("dns.txt:\"%s\"", domain),
-		fmt.Sprintf("dns.mx:\"%s\"", domain),
-
-		// Organization and ASN queries
-		fmt.Sprintf("org:\"%s\"", domain),
-		fmt.Sprintf("asn.description:\"%s\"", domain),
-
-		// Certificate transparency logs
-		fmt.Sprintf("ssl.cert.serial:\"%s\"", domain),
-		fmt.Sprintf("ssl.cert.fingerprint:\"%s\"", domain),
-
-		// Catch-all queries
-		fmt.Sprintf("all:\"%s\"", domain),
-
-		// Additional wildcard patterns for common subdomains
-		fmt.Sprintf("hostname:\"*.%s\"", domain),
-		fmt.Sprintf("ssl.cert.subject.cn:\"*.%s\"", domain),
-		fmt.Sprintf("ssl.cert.subject.alt_names:\"*.%s\"", domain),
+// readLines returns the non-empty, non-comment lines of a file
+func readLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
+	return parseLines(string(data)), nil
+}
 
-	var allSubs []string
+// parseLines splits text into trimmed lines, dropping blank lines and # comments
+func parseLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
 
-	for _, q := range queries {
-		fmt.Println("[*] Query:", q)
-		subs := searchShodan(q, *apiKey)
-		allSubs = append(allSubs, subs...)
+// readStdinLines returns the non-empty, non-comment lines of stdin
+func readStdinLines() ([]string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
 	}
+	return parseLines(string(data)), nil
+}
 
-	// Add DNS API results
-	dnsSubs := getDNSSubs(domain, *apiKey)
-	allSubs = append(allSubs, dnsSubs...)
+// readList returns the lines of a file if s names one, otherwise the
+// comma-separated entries of s
+func readList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	if lines, err := readLines(s); err == nil {
+		return lines
+	}
+	return splitList(s)
+}
 
-	// Remove duplicates
-	allSubs = unique(allSubs)
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
 
-	fmt.Printf("\n[+] Found %d unique subdomains:\n", len(allSubs))
-	for _, s := range allSubs {
-		fmt.Println(s)
+// joinPorts sorts a copy of ports and joins them with sep
+func joinPorts(ports []int, sep string) string {
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+	strs := make([]string, len(sorted))
+	for i, p := range sorted {
+		strs[i] = strconv.Itoa(p)
 	}
+	return strings.Join(strs, sep)
+}
 
-	// IMPROVED SAVING WITH ERROR HANDLING AND FALLBACK
-	if *output != "" {
-		if err := saveResults(domain, allSubs, queries, *output); err != nil {
-			fmt.Printf("Error: Failed to save results: %v\n", err)
-			os.Exit(1)
-		}
+// ipLess orders IP address strings numerically, falling back to text order
+func ipLess(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a < b
 	}
+	return bytes.Compare(ipA.To16(), ipB.To16()) < 0
+}
+
+// signalContext is cancelled on Ctrl-C/SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 }
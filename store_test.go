@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestStoreUpserts(t *testing.T) {
+	s, err := openStore(filepath.Join(t.TempDir(), "results.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	firstSeen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := &shodanx.Result{
+		Domain:     "example.com",
+		Subdomains: []string{"www.example.com"},
+		Sources:    map[string][]string{"www.example.com": {"shodan"}},
+		FirstSeen:  map[string]time.Time{"www.example.com": firstSeen},
+		Services: map[string][]shodanx.Service{"www.example.com": {
+			{IP: "192.0.2.1", Port: 443, Module: "https", Product: "nginx", Version: "1.18"},
+		}},
+		Geo: map[string]*shodanx.GeoInfo{"192.0.2.1": {ASN: "AS64500", Org: "Example"}},
+	}
+	second := &shodanx.Result{
+		Domain:     "example.com",
+		Subdomains: []string{"www.example.com", "api.example.com"},
+		Sources:    map[string][]string{"www.example.com": {"crtsh"}, "api.example.com": {"crtsh"}},
+		FirstSeen:  map[string]time.Time{"www.example.com": time.Now()},
+		Services: map[string][]shodanx.Service{"www.example.com": {
+			{IP: "192.0.2.1", Port: 443, Module: "https", Product: "nginx", Version: "1.24"},
+		}},
+	}
+	for _, result := range []*shodanx.Result{first, second} {
+		if err := s.save(result, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count := func(query string, args ...interface{}) int {
+		var n int
+		if err := s.db.QueryRow(query, args...).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		want  int
+	}{
+		{"runs", `SELECT COUNT(*) FROM runs`, nil, 2},
+		{"domains", `SELECT COUNT(*) FROM domains`, nil, 1},
+		{"subdomains", `SELECT COUNT(*) FROM subdomains`, nil, 2},
+		{"sources accumulate", `SELECT COUNT(*) FROM subdomain_sources WHERE subdomain = $1`, []interface{}{"www.example.com"}, 2},
+		{"runs record their names", `SELECT COUNT(*) FROM run_subdomains`, nil, 3},
+		{"ports", `SELECT COUNT(*) FROM ports`, nil, 1},
+		{"subdomain addresses", `SELECT COUNT(*) FROM subdomain_ips`, nil, 1},
+	}
+	for _, tt := range tests {
+		if got := count(tt.query, tt.args...); got != tt.want {
+			t.Errorf("%s: got %d rows, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	var seen time.Time
+	if err := s.db.QueryRow(`SELECT first_seen FROM subdomains WHERE name = $1`, "www.example.com").Scan(&seen); err != nil {
+		t.Fatal(err)
+	}
+	if !seen.Equal(firstSeen) {
+		t.Errorf("first_seen = %s, want %s kept from the first run", seen, firstSeen)
+	}
+	var asn, version string
+	if err := s.db.QueryRow(`SELECT asn FROM ips WHERE ip = $1`, "192.0.2.1").Scan(&asn); err != nil {
+		t.Fatal(err)
+	}
+	if asn != "AS64500" {
+		t.Errorf("asn = %q, want AS64500 kept when a run has no geo details", asn)
+	}
+	if err := s.db.QueryRow(`SELECT version FROM ports WHERE ip = $1 AND port = 443`, "192.0.2.1").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.24" {
+		t.Errorf("version = %q, want 1.24 from the latest run", version)
+	}
+}
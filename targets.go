@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// scanTargets returns the addresses of a result, sorted and without
+// duplicates, and the union of the ports seen open on them
+func scanTargets(result *shodanx.Result) (ips []string, ports []int) {
+	var all []string
+	seen := map[int]bool{}
+	for _, name := range result.Subdomains {
+		all = append(all, result.HostAddresses(name)...)
+		for _, svc := range result.Services[name] {
+			seen[svc.Port] = true
+		}
+	}
+	all = append(all, result.Addresses...)
+	ips = shodanx.Unique(all)
+	sort.Slice(ips, func(i, j int) bool {
+		return bytesLess(net.ParseIP(ips[i]), net.ParseIP(ips[j]))
+	})
+	for _, ip := range ips {
+		if h := result.InternetDB[ip]; h != nil {
+			for _, port := range h.Ports {
+				seen[port] = true
+			}
+		}
+	}
+	for port := range seen {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ips, ports
+}
+
+// bytesLess orders addresses numerically, so 10.0.0.2 comes before 10.0.0.10
+func bytesLess(a, b net.IP) bool {
+	return string(a.To16()) < string(b.To16())
+}
+
+// saveNmap writes the addresses of a result as target lists for nmap -iL and
+// masscan -iL: IPv4 to <prefix>_nmap.txt and IPv6, which nmap only scans
+// with -6, to <prefix>_nmap6.txt. The ports seen open go comma-separated to
+// <prefix>_nmap_ports.txt, for -p.
+func saveNmap(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	ips, ports := scanTargets(result)
+	var v4, v6 []string
+	for _, ip := range ips {
+		switch parsed := net.ParseIP(ip); {
+		case parsed == nil:
+		case parsed.To4() != nil:
+			v4 = append(v4, ip)
+		default:
+			v6 = append(v6, ip)
+		}
+	}
+	portList := make([]string, len(ports))
+	for i, port := range ports {
+		portList[i] = strconv.Itoa(port)
+	}
+
+	files := []struct {
+		name, what string
+		lines      []string
+	}{
+		{outputPrefix + "_nmap.txt", "IPv4 scan targets", v4},
+		{outputPrefix + "_nmap6.txt", "IPv6 scan targets", v6},
+		{outputPrefix + "_nmap_ports.txt", "Open ports", []string{strings.Join(portList, ",")}},
+	}
+	for _, f := range files {
+		if len(f.lines) == 0 || f.lines[0] == "" {
+			continue
+		}
+		if err := os.WriteFile(f.name, []byte(strings.Join(f.lines, "\n")+"\n"), 0644); err != nil {
+			fmt.Fprintf(w, "Error: Failed to save %s file %s: %v\n", f.what, f.name, err)
+			return err
+		}
+		fmt.Fprintf(w, "[+] %s saved to %s\n", f.what, f.name)
+	}
+	if len(v4) == 0 {
+		return nil
+	}
+	if len(ports) > 0 {
+		fmt.Fprintf(w, "[*] Scan with: nmap -iL %s -p %s  or  masscan -iL %s -p %s\n",
+			files[0].name, files[2].lines[0], files[0].name, files[2].lines[0])
+	} else {
+		fmt.Fprintf(w, "[*] Scan with: nmap -iL %s  or  masscan -iL %s -p 1-65535\n", files[0].name, files[0].name)
+	}
+	return nil
+}
+
+// liveHosts returns the subdomains with addresses that did not fail to
+// resolve, followed by the bare IPs: the hosts a vulnerability scanner should
+// cover
+func liveHosts(result *shodanx.Result) []string {
+	unresolved := map[string]bool{}
+	for _, name := range result.Unresolved {
+		unresolved[name] = true
+	}
+	var hosts []string
+	for _, name := range result.Subdomains {
+		if !unresolved[name] && len(result.HostAddresses(name)) > 0 {
+			hosts = append(hosts, name)
+		}
+	}
+	return append(hosts, result.Addresses...)
+}
+
+// saveTargets writes the live hosts of a result one per line to
+// <prefix>_targets.txt, the target list format Nessus ("Upload Targets") and
+// OpenVAS ("Hosts: From file") import
+func saveTargets(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	hosts := liveHosts(result)
+	if len(hosts) == 0 {
+		fmt.Fprintln(w, "[!] No live hosts for the scanner target list")
+		return nil
+	}
+	targetFile := outputPrefix + "_targets.txt"
+	if err := os.WriteFile(targetFile, []byte(strings.Join(hosts, "\n")+"\n"), 0644); err != nil {
+		fmt.Fprintf(w, "Error: Failed to save scanner targets file %s: %v\n", targetFile, err)
+		return err
+	}
+	fmt.Fprintf(w, "[+] %d live hosts saved to %s\n", len(hosts), targetFile)
+	return nil
+}
+
+// webURLs returns scheme://host:port of the web servers --probe found, the
+// input httpx, nuclei, katana and ffuf take
+func webURLs(result *shodanx.Result) []string {
+	var urls []string
+	for _, name := range result.Subdomains {
+		p := result.Probes[name]
+		if p == nil {
+			continue
+		}
+		if scheme, host, port, ok := probeHostPort(p); ok {
+			urls = append(urls, scheme+"://"+net.JoinHostPort(host, port))
+		}
+	}
+	return urls
+}
+
+// probeHostPort returns the scheme, host and port of the URL of a probe, the
+// port defaulting to that of the scheme. ok is false for unparsable URLs and
+// schemes other than http and https.
+func probeHostPort(p *shodanx.Probe) (scheme, host, port string, ok bool) {
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", "", false
+	}
+	port = u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	return u.Scheme, u.Hostname(), port, true
+}
+
+// saveURLs writes the URLs of the web servers of a result to <prefix>_urls.txt
+func saveURLs(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	urls := webURLs(result)
+	if len(urls) == 0 {
+		fmt.Fprintln(w, "[!] No web servers for the URL list; --probe finds them")
+		return nil
+	}
+	urlFile := outputPrefix + "_urls.txt"
+	if err := os.WriteFile(urlFile, []byte(strings.Join(urls, "\n")+"\n"), 0644); err != nil {
+		fmt.Fprintf(w, "Error: Failed to save URL file %s: %v\n", urlFile, err)
+		return err
+	}
+	fmt.Fprintf(w, "[+] %d URLs saved to %s\n", len(urls), urlFile)
+	return nil
+}
+
+// webPorts are the schemes of ports that serve the web by convention, for
+// hosts whose services are not known
+var webPorts = map[int]string{80: "http", 443: "https", 8000: "http", 8008: "http", 8080: "http", 8443: "https", 8888: "http"}
+
+// webCandidates returns the URLs a live host may serve the web on: the web
+// server --probe found, the services Shodan and the other sources saw
+// speaking HTTP, the conventional web ports seen open, and http and https on
+// their default ports when nothing is known
+func webCandidates(result *shodanx.Result, name string) []string {
+	var urls []string
+	add := func(scheme string, port int) {
+		urls = append(urls, scheme+"://"+net.JoinHostPort(name, strconv.Itoa(port)))
+	}
+	if p := result.Probes[name]; p != nil {
+		if scheme, _, port, ok := probeHostPort(p); ok {
+			n, _ := strconv.Atoi(port)
+			add(scheme, n)
+		}
+	}
+	for _, svc := range result.Services[name] {
+		module := strings.ToLower(svc.Module)
+		switch {
+		case strings.HasPrefix(module, "https"):
+			add("https", svc.Port)
+		case strings.HasPrefix(module, "http"):
+			add("http", svc.Port)
+		}
+	}
+	for _, port := range result.HostPorts(name) {
+		if scheme := webPorts[port]; scheme != "" {
+			add(scheme, port)
+		}
+	}
+	if len(urls) == 0 {
+		add("http", 80)
+		add("https", 443)
+	}
+	return shodanx.Unique(urls)
+}
+
+// saveWeb writes the candidate web URLs of the live hosts of a result to
+// <prefix>_web.txt, the input of Aquatone and EyeWitness
+func saveWeb(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	var urls []string
+	for _, name := range liveHosts(result) {
+		urls = append(urls, webCandidates(result, name)...)
+	}
+	if len(urls) == 0 {
+		fmt.Fprintln(w, "[!] No live hosts for the web URL list")
+		return nil
+	}
+	webFile := outputPrefix + "_web.txt"
+	if err := os.WriteFile(webFile, []byte(strings.Join(urls, "\n")+"\n"), 0644); err != nil {
+		fmt.Fprintf(w, "Error: Failed to save web URL file %s: %v\n", webFile, err)
+		return err
+	}
+	fmt.Fprintf(w, "[+] %d web URLs saved to %s\n", len(urls), webFile)
+	fmt.Fprintf(w, "[*] Screenshot with: cat %s | aquatone  or  EyeWitness --web -f %s\n", webFile, webFile)
+	return nil
+}
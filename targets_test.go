@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+func TestScanTargets(t *testing.T) {
+	result := &shodanx.Result{
+		Subdomains: []string{"www.example.com", "mail.example.com", "old.example.com"},
+		IPs:        map[string][]string{"mail.example.com": {"10.0.0.10", "2001:db8::1"}},
+		Services: map[string][]shodanx.Service{"www.example.com": {
+			{IP: "10.0.0.2", Port: 443}, {IP: "10.0.0.10", Port: 80},
+		}},
+		Addresses:  []string{"192.0.2.1", "10.0.0.2"},
+		InternetDB: map[string]*shodanx.InternetDBHost{"192.0.2.1": {Ports: []int{22, 443}}},
+	}
+	ips, ports := scanTargets(result)
+	if want := []string{"10.0.0.2", "10.0.0.10", "192.0.2.1", "2001:db8::1"}; !reflect.DeepEqual(ips, want) {
+		t.Errorf("ips = %v, want %v", ips, want)
+	}
+	if want := []int{22, 80, 443}; !reflect.DeepEqual(ports, want) {
+		t.Errorf("ports = %v, want %v", ports, want)
+	}
+}
+
+func TestLiveHosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *shodanx.Result
+		want   []string
+	}{
+		{"empty", &shodanx.Result{}, nil},
+		{"resolved names and bare IPs", &shodanx.Result{
+			Subdomains: []string{"www.example.com", "gone.example.com", "dangling.example.com", "mail.example.com"},
+			IPs: map[string][]string{
+				"www.example.com":      {"192.0.2.1"},
+				"dangling.example.com": {"192.0.2.2"},
+			},
+			Services:   map[string][]shodanx.Service{"mail.example.com": {{IP: "192.0.2.3", Port: 25}}},
+			Unresolved: []string{"dangling.example.com"},
+			Addresses:  []string{"198.51.100.7"},
+		}, []string{"www.example.com", "mail.example.com", "198.51.100.7"}},
+	}
+	for _, tt := range tests {
+		if got := liveHosts(tt.result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: liveHosts = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWebURLs(t *testing.T) {
+	result := &shodanx.Result{
+		Subdomains: []string{"www.example.com", "app.example.com", "mail.example.com", "v6.example.com", "bad.example.com"},
+		Probes: map[string]*shodanx.Probe{
+			"www.example.com": {URL: "https://www.example.com/"},
+			"app.example.com": {URL: "http://app.example.com:8080/login?next=/"},
+			"v6.example.com":  {URL: "http://[2001:db8::1]/"},
+			"bad.example.com": {URL: "://bad"},
+		},
+	}
+	want := []string{"https://www.example.com:443", "http://app.example.com:8080", "http://[2001:db8::1]:80"}
+	if got := webURLs(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("webURLs = %v, want %v", got, want)
+	}
+}
+
+func TestProbeHostPort(t *testing.T) {
+	tests := []struct {
+		url                string
+		scheme, host, port string
+		ok                 bool
+	}{
+		{"https://www.example.com/", "https", "www.example.com", "443", true},
+		{"http://app.example.com:8080/login", "http", "app.example.com", "8080", true},
+		{"http://[2001:db8::1]/", "http", "2001:db8::1", "80", true},
+		{"ftp://files.example.com/", "", "", "", false},
+		{"://bad", "", "", "", false},
+	}
+	for _, tt := range tests {
+		scheme, host, port, ok := probeHostPort(&shodanx.Probe{URL: tt.url})
+		if scheme != tt.scheme || host != tt.host || port != tt.port || ok != tt.ok {
+			t.Errorf("probeHostPort(%q) = %q, %q, %q, %v, want %q, %q, %q, %v",
+				tt.url, scheme, host, port, ok, tt.scheme, tt.host, tt.port, tt.ok)
+		}
+	}
+}
+
+func TestWebCandidates(t *testing.T) {
+	result := &shodanx.Result{
+		Subdomains: []string{"www.example.com", "api.example.com", "mail.example.com"},
+		Probes:     map[string]*shodanx.Probe{"www.example.com": {URL: "https://www.example.com/"}},
+		Services: map[string][]shodanx.Service{
+			"www.example.com": {{IP: "192.0.2.1", Port: 443, Module: "https"}, {IP: "192.0.2.1", Port: 8081, Module: "http-simple-new"}},
+			"api.example.com": {{IP: "192.0.2.2", Port: 22, Module: "ssh"}},
+		},
+		IPs:        map[string][]string{"api.example.com": {"192.0.2.2"}},
+		InternetDB: map[string]*shodanx.InternetDBHost{"192.0.2.2": {Ports: []int{22, 8443}}},
+	}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"www.example.com", []string{"https://www.example.com:443", "http://www.example.com:8081"}},
+		{"api.example.com", []string{"https://api.example.com:8443"}},
+		{"mail.example.com", []string{"http://mail.example.com:80", "https://mail.example.com:443"}},
+	}
+	for _, tt := range tests {
+		if got := webCandidates(result, tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("webCandidates(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+)
+
+// outputTemplate renders results through a user's text/template, see
+// --template
+type outputTemplate struct {
+	tmpl *template.Template
+
+	// suffix is appended to the output prefix: _ and the template's file
+	// name without .tmpl, e.g. _slack.json for slack.json.tmpl
+	suffix string
+}
+
+// templateAsset is a subdomain with what is known about it, the Assets of
+// the template data
+type templateAsset struct {
+	Name         string
+	Status       string // the group of the reports, e.g. "Resolving"
+	IPs          []string
+	Ports        []int
+	Technologies []string
+	Sources      []string
+	Risk         int
+	FirstSeen    time.Time // zero if unknown
+	Probe        *shodanx.Probe
+}
+
+// templateData is the dot of output templates: the fields of the result
+// (.Domain, .Subdomains, .Exposed, ...), the time of rendering and the assets
+// riskiest first
+type templateData struct {
+	*shodanx.Result
+	Generated time.Time
+	Assets    []templateAsset
+}
+
+// templateFuncs are the functions available to output templates besides the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"join":      templateJoin,
+	"json":      templateJSON,
+	"csv":       templateCSV,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"replace":   strings.ReplaceAll,
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+}
+
+// templateJoin joins the elements of any slice, e.g. {{join .Ports ","}}
+func templateJoin(list interface{}, sep string) string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// templateJSON encodes a value as JSON, e.g. {{json .IPs}}
+func templateJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// templateCSV encodes its arguments as a CSV record without the line break,
+// e.g. {{csv .Name (join .IPs " ")}}
+func templateCSV(fields ...interface{}) (string, error) {
+	record := make([]string, len(fields))
+	for i, f := range fields {
+		record[i] = fmt.Sprint(f)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(record)
+	w.Flush()
+	return strings.TrimRight(buf.String(), "\n"), w.Error()
+}
+
+// loadOutputTemplate parses the template file at path
+func loadOutputTemplate(path string) (*outputTemplate, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(path)
+	for _, ext := range []string{".tmpl", ".tpl", ".gotmpl"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return &outputTemplate{tmpl: tmpl, suffix: "_" + name}, nil
+}
+
+// templateDataOf returns the template data of a result
+func templateDataOf(result *shodanx.Result) *templateData {
+	status := map[string]string{}
+	for _, g := range statusGroups(result) {
+		for _, name := range g.Names {
+			status[name] = g.Title
+		}
+	}
+	data := &templateData{Result: result, Generated: time.Now().UTC().Truncate(time.Second)}
+	for _, name := range result.ByRisk() {
+		data.Assets = append(data.Assets, templateAsset{
+			Name:         name,
+			Status:       status[name],
+			IPs:          result.HostAddresses(name),
+			Ports:        result.HostPorts(name),
+			Technologies: result.HostTechnologies(name),
+			Sources:      result.Sources[name],
+			Risk:         result.RiskOf(name),
+			FirstSeen:    result.FirstSeen[name],
+			Probe:        result.Probes[name],
+		})
+	}
+	return data
+}
+
+// render executes the template on a result
+func (t *outputTemplate) render(w io.Writer, result *shodanx.Result) error {
+	return t.tmpl.Execute(w, templateDataOf(result))
+}
+
+// save renders a result to <prefix><suffix>
+func (t *outputTemplate) save(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	var buf bytes.Buffer
+	outFile := outputPrefix + t.suffix
+	err := t.render(&buf, result)
+	if err == nil {
+		err = os.WriteFile(outFile, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to render template to %s: %v\n", outFile, err)
+		return err
+	}
+	fmt.Fprintln(w, "[+] Template output saved to", outFile)
+	return nil
+}
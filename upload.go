@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// artifactSuffixes are the files written next to an output prefix
+var artifactSuffixes = []string{".txt", "_ips.txt", "_exposed.txt", "_certs.txt", "_related.txt",
+	".json", ".jsonl", ".csv", ".md", ".html", ".xlsx", "_nmap.txt", "_nmap6.txt", "_nmap_ports.txt",
+	"_targets.txt", "_burp.json", "_urls.txt", "_web.txt", ".dot", ".graphml", ".sarif"}
+
+// uploadTimeout bounds the upload of each file
+const uploadTimeout = 5 * time.Minute
+
+// objectStore puts files into a bucket of a cloud storage service
+type objectStore interface {
+	put(ctx context.Context, key, file string) error
+}
+
+// artifactUploader copies the output files of every run to cloud storage,
+// see --upload. Each run gets its own folder, <prefix>/<run start>/, so
+// scheduled scans keep their history instead of overwriting each other.
+type artifactUploader struct {
+	store  objectStore
+	prefix string // key prefix, without trailing slash
+	run    string // run start, e.g. 20261015T110000Z
+
+	// name is the bucket URL, e.g. s3://bucket, for messages
+	name string
+}
+
+// newArtifactUploader returns the uploader of a destination URL, chosen by
+// its scheme: s3://bucket/scans, gs://bucket/scans or
+// azblob://container/scans
+func newArtifactUploader(rawURL string) (*artifactUploader, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid upload URL %q, expected e.g. s3://bucket/prefix", rawURL)
+	}
+	a := &artifactUploader{
+		prefix: strings.Trim(u.Path, "/"),
+		run:    time.Now().UTC().Format("20060102T150405Z"),
+		name:   u.Scheme + "://" + u.Host,
+	}
+	switch u.Scheme {
+	case "s3":
+		a.store, err = newS3Store(u.Host)
+	case "gs":
+		a.store, err = newGCSStore(u.Host)
+	case "azblob":
+		a.store, err = newAzureStore(u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported upload URL scheme %q (available: s3, gs, azblob)", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// artifacts returns the files of outputPrefix written by a run started at
+// started, with the artifactSuffixes and the extra ones of the run; files
+// left by earlier runs with other formats are skipped
+func artifacts(outputPrefix string, started time.Time, extra []string) []string {
+	since := started.Truncate(time.Second)
+	suffixes := append(append([]string(nil), artifactSuffixes...), extra...)
+	var files []string
+	for _, suffix := range suffixes {
+		info, err := os.Stat(outputPrefix + suffix)
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		files = append(files, outputPrefix+suffix)
+	}
+	return files
+}
+
+// upload copies the files written for outputPrefix by a run started at
+// started, including those with the extra suffixes, and returns the folder
+// they were uploaded to
+func (a *artifactUploader) upload(outputPrefix string, started time.Time, extra []string) (string, error) {
+	dir := path.Join(a.prefix, a.run)
+	for _, file := range artifacts(outputPrefix, started, extra) {
+		key := path.Join(dir, filepath.Base(file))
+		ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+		err := a.store.put(ctx, key, file)
+		cancel()
+		if err != nil {
+			return "", fmt.Errorf("failed to upload %s: %w", file, err)
+		}
+	}
+	return a.name + "/" + dir + "/", nil
+}
+
+// contentType returns the MIME type of an output file; they are all text
+func contentType(file string) string {
+	if t := mime.TypeByExtension(filepath.Ext(file)); t != "" {
+		return t
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// s3Store uploads to an S3 bucket. Credentials and region come from the
+// usual AWS environment variables, shared config files or instance role;
+// AWS_ENDPOINT_URL points it at S3-compatible storage such as MinIO.
+type s3Store struct {
+	client *s3.Client
+	bucket string
+}
+
+func newS3Store(bucket string) (*s3Store, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		// S3-compatible servers rarely support virtual-hosted buckets
+		if o.BaseEndpoint != nil {
+			o.UsePathStyle = true
+		}
+	})
+	return &s3Store{client: client, bucket: bucket}, nil
+}
+
+func (s *s3Store) put(ctx context.Context, key, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        f,
+		ContentType: aws.String(contentType(file)),
+	})
+	return err
+}
+
+// gcsStore uploads to a Google Cloud Storage bucket with the application
+// default credentials: GOOGLE_APPLICATION_CREDENTIALS, gcloud's login or the
+// service account of the runner. STORAGE_EMULATOR_HOST points it at an
+// emulator.
+type gcsStore struct {
+	bucket *storage.BucketHandle
+}
+
+func newGCSStore(bucket string) (*gcsStore, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Google Cloud Storage client: %w", err)
+	}
+	return &gcsStore{bucket: client.Bucket(bucket)}, nil
+}
+
+func (s *gcsStore) put(ctx context.Context, key, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType(file)
+	if _, err := io.Copy(w, f); err != nil {
+		w.Close()
+		return err
+	}
+	// The object is only written when the writer is closed
+	return w.Close()
+}
+
+// Environment variables of the Azure Blob Storage account
+const (
+	azureConnStringEnv = "AZURE_STORAGE_CONNECTION_STRING"
+	azureAccountEnv    = "AZURE_STORAGE_ACCOUNT"
+	azureKeyEnv        = "AZURE_STORAGE_KEY"
+)
+
+// azureStore uploads to an Azure Blob Storage container. The account comes
+// from $AZURE_STORAGE_CONNECTION_STRING, or $AZURE_STORAGE_ACCOUNT with
+// $AZURE_STORAGE_KEY or else the default Azure credential chain
+// (environment, managed identity, Azure CLI login).
+type azureStore struct {
+	client    *azblob.Client
+	container string
+}
+
+func newAzureStore(container string) (*azureStore, error) {
+	var client *azblob.Client
+	var err error
+	account := os.Getenv(azureAccountEnv)
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+	switch {
+	case os.Getenv(azureConnStringEnv) != "":
+		client, err = azblob.NewClientFromConnectionString(os.Getenv(azureConnStringEnv), nil)
+	case account == "":
+		return nil, fmt.Errorf("azblob upload needs $%s or $%s", azureConnStringEnv, azureAccountEnv)
+	case os.Getenv(azureKeyEnv) != "":
+		var cred *azblob.SharedKeyCredential
+		cred, err = azblob.NewSharedKeyCredential(account, os.Getenv(azureKeyEnv))
+		if err == nil {
+			client, err = azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+		}
+	default:
+		var cred *azidentity.DefaultAzureCredential
+		cred, err = azidentity.NewDefaultAzureCredential(nil)
+		if err == nil {
+			client, err = azblob.NewClient(serviceURL, cred, nil)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Blob Storage client: %w", err)
+	}
+	return &azureStore{client: client, container: container}, nil
+}
+
+func (s *azureStore) put(ctx context.Context, key, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ct := contentType(file)
+	_, err = s.client.UploadFile(ctx, s.container, key, f, &azblob.UploadFileOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &ct},
+	})
+	return err
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moatasem121/shodanX/pkg/shodanx"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxMaxWidth caps the width of spreadsheet columns, in characters
+const xlsxMaxWidth = 60
+
+// xlsxSheet is a worksheet of the Excel export; cells are strings or numbers
+type xlsxSheet struct {
+	Name   string
+	Header []string
+	Rows   [][]interface{}
+}
+
+// xlsxSubdomains lists the subdomains with the columns of the CSV file and
+// their status
+func xlsxSubdomains(result *shodanx.Result) xlsxSheet {
+	sheet := xlsxSheet{Name: "Subdomains", Header: []string{"Subdomain", "Status", "IPs", "Ports", "Technologies",
+		"Web server", "Sources", "First Seen", "Risk"}}
+	for _, g := range statusGroups(result) {
+		for _, name := range g.Names {
+			var ports []string
+			for _, port := range result.HostPorts(name) {
+				ports = append(ports, strconv.Itoa(port))
+			}
+			web, firstSeen := "", ""
+			if p := result.Probes[name]; p != nil {
+				web = formatProbe(p)
+			}
+			if t, ok := result.FirstSeen[name]; ok {
+				firstSeen = t.Format("2006-01-02 15:04:05")
+			}
+			sheet.Rows = append(sheet.Rows, []interface{}{displayName(name), g.Title,
+				strings.Join(result.HostAddresses(name), ", "), strings.Join(ports, ", "),
+				strings.Join(result.HostTechnologies(name), ", "), web, strings.Join(result.Sources[name], ", "),
+				firstSeen, result.RiskOf(name)})
+		}
+	}
+	return sheet
+}
+
+// xlsxHosts lists every open port of every address: the services banners
+// were seen for, then the ports only InternetDB lists
+func xlsxHosts(result *shodanx.Result) xlsxSheet {
+	sheet := xlsxSheet{Name: "Hosts and Ports", Header: []string{"IP", "Port", "Transport", "Service", "Product",
+		"Version", "Hostnames", "ASN", "Org", "Country", "Source"}}
+	type hostPort struct {
+		ip   string
+		port int
+	}
+	services := map[hostPort]shodanx.Service{}
+	names := map[hostPort][]string{}
+	sources := map[hostPort]string{}
+	for _, name := range result.Subdomains {
+		for _, svc := range result.Services[name] {
+			key := hostPort{svc.IP, svc.Port}
+			if _, ok := services[key]; !ok {
+				services[key] = svc
+				sources[key] = "shodan"
+			}
+			names[key] = append(names[key], name)
+		}
+	}
+	for ip, host := range result.InternetDB {
+		for _, port := range host.Ports {
+			key := hostPort{ip, port}
+			if _, ok := services[key]; !ok {
+				services[key] = shodanx.Service{IP: ip, Port: port}
+				names[key] = host.Hostnames
+				sources[key] = "internetdb"
+			}
+		}
+	}
+	keys := make([]hostPort, 0, len(services))
+	for key := range services {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ip != keys[j].ip {
+			return keys[i].ip < keys[j].ip
+		}
+		return keys[i].port < keys[j].port
+	})
+	for _, key := range keys {
+		svc := services[key]
+		var asn, org, country string
+		if g := result.Geo[key.ip]; g != nil {
+			asn, org, country = g.ASN, g.Org, g.CountryCode
+		}
+		sheet.Rows = append(sheet.Rows, []interface{}{key.ip, key.port, svc.Transport, svc.Module, svc.Product,
+			svc.Version, strings.Join(shodanx.Unique(names[key]), ", "), asn, org, country, sources[key]})
+	}
+	return sheet
+}
+
+// xlsxCertificates lists the TLS certificates the services presented, with
+// the services and names sharing each and their issues
+func xlsxCertificates(result *shodanx.Result) xlsxSheet {
+	sheet := xlsxSheet{Name: "Certificates", Header: []string{"Subject", "SHA-256", "Services", "Hostnames", "Issues"}}
+	issues := map[string][]string{}
+	for _, f := range result.CertFindings() {
+		key := fmt.Sprintf("%s:%d", f.IP, f.Port)
+		if f.Expired {
+			issues[key] = append(issues[key], "expired "+f.Expires)
+		}
+		if f.SelfSigned {
+			issues[key] = append(issues[key], "self-signed")
+		}
+	}
+	type cert struct {
+		subject            string
+		services, hostname []string
+	}
+	certs := map[string]*cert{}
+	var order []string
+	for _, name := range result.Subdomains {
+		for _, svc := range result.Services[name] {
+			if svc.CertSHA256 == "" {
+				continue
+			}
+			c := certs[svc.CertSHA256]
+			if c == nil {
+				c = &cert{subject: svc.CertSubject}
+				certs[svc.CertSHA256] = c
+				order = append(order, svc.CertSHA256)
+			}
+			c.services = append(c.services, fmt.Sprintf("%s:%d", svc.IP, svc.Port))
+			c.hostname = append(c.hostname, name)
+		}
+	}
+	for _, sha := range order {
+		c := certs[sha]
+		services := shodanx.Unique(c.services)
+		var certIssues []string
+		for _, s := range services {
+			certIssues = append(certIssues, issues[s]...)
+		}
+		sheet.Rows = append(sheet.Rows, []interface{}{c.subject, sha, strings.Join(services, ", "),
+			strings.Join(shodanx.Unique(c.hostname), ", "), strings.Join(shodanx.Unique(certIssues), ", ")})
+	}
+	return sheet
+}
+
+// xlsxFindings lists every finding with a severity, most severe kinds first
+func xlsxFindings(result *shodanx.Result) xlsxSheet {
+	sheet := xlsxSheet{Name: "Findings", Header: []string{"Type", "Severity", "Asset", "Detail", "Hostnames"}}
+	add := func(kind, severity, asset, detail string, hostnames []string) {
+		sheet.Rows = append(sheet.Rows, []interface{}{kind, severity, asset, detail, strings.Join(hostnames, ", ")})
+	}
+	for _, e := range result.Exposed {
+		detail := e.Service
+		if e.Product != "" {
+			detail += " (" + e.Product + ")"
+		}
+		add("Exposed service", "high", fmt.Sprintf("%s:%d", e.IP, e.Port), detail, e.Hostnames)
+	}
+	for _, v := range result.VulnReport() {
+		detail := v.CVE
+		if v.CVSS > 0 {
+			detail += fmt.Sprintf(" (CVSS %.1f)", v.CVSS)
+		}
+		if v.Summary != "" {
+			detail += ": " + v.Summary
+		}
+		add("Vulnerability", shodanx.Severity(v.CVSS), fmt.Sprintf("%s:%d", v.IP, v.Port), detail, v.Hostnames)
+	}
+	for _, b := range result.Buckets {
+		switch b.Access {
+		case shodanx.BucketListable:
+			add("Storage bucket", "high", b.Provider+" "+b.Name, "publicly listable", []string{b.Host})
+		case shodanx.BucketMissing:
+			add("Storage bucket", "high", b.Provider+" "+b.Name, "unclaimed, can be taken over", []string{b.Host})
+		}
+	}
+	certs := result.CertFindings()
+	for i := range certs {
+		// Probed hosts have no IP but a single hostname
+		host := certs[i].IP
+		if host == "" {
+			host = strings.Join(certs[i].Hostnames, ",")
+		}
+		add("Certificate", "medium", fmt.Sprintf("%s:%d", host, certs[i].Port), formatCertFinding(&certs[i]), certs[i].Hostnames)
+	}
+	var mailNames []string
+	for name, rec := range result.Mail {
+		if len(rec.Issues) > 0 {
+			mailNames = append(mailNames, name)
+		}
+	}
+	sort.Strings(mailNames)
+	for _, name := range mailNames {
+		for _, issue := range result.Mail[name].Issues {
+			add("Email security", "medium", name, issue, nil)
+		}
+	}
+	for _, l := range result.Leaks {
+		add("Internal name leak", "low", l.Name, l.Reason, l.Seen)
+	}
+	return sheet
+}
+
+// saveXLSX writes an Excel workbook of a result to <prefix>.xlsx: a summary
+// sheet and sheets of subdomains, hosts and ports, certificates and findings
+func saveXLSX(w io.Writer, result *shodanx.Result, outputPrefix string) error {
+	xlsxFile := outputPrefix + ".xlsx"
+	summary := xlsxSheet{Name: "Summary", Header: []string{"Domain", result.Domain}}
+	for _, row := range reportSummary(result, statusGroups(result)) {
+		n, _ := strconv.Atoi(row[1])
+		summary.Rows = append(summary.Rows, []interface{}{row[0], n})
+	}
+	sheets := []xlsxSheet{summary, xlsxSubdomains(result), xlsxHosts(result), xlsxCertificates(result), xlsxFindings(result)}
+
+	err := writeXLSX(xlsxFile, sheets)
+	if err != nil {
+		fmt.Fprintf(w, "Error: Failed to save Excel file %s: %v\n", xlsxFile, err)
+		return err
+	}
+	fmt.Fprintln(w, "[+] Excel workbook saved to", xlsxFile)
+	return nil
+}
+
+// writeXLSX writes sheets to an Excel file, each with a bold, frozen and
+// filterable header row
+func writeXLSX(path string, sheets []xlsxSheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	header, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+	for i, sheet := range sheets {
+		if i == 0 {
+			err = f.SetSheetName("Sheet1", sheet.Name)
+		} else {
+			_, err = f.NewSheet(sheet.Name)
+		}
+		if err != nil {
+			return err
+		}
+		widths := make([]int, len(sheet.Header))
+		row := make([]interface{}, len(sheet.Header))
+		for c, h := range sheet.Header {
+			row[c], widths[c] = h, len(h)
+		}
+		if err := f.SetSheetRow(sheet.Name, "A1", &row); err != nil {
+			return err
+		}
+		for r, row := range sheet.Rows {
+			cell, _ := excelize.CoordinatesToCellName(1, r+2)
+			if err := f.SetSheetRow(sheet.Name, cell, &row); err != nil {
+				return err
+			}
+			for c, v := range row {
+				if n := len(fmt.Sprint(v)); c < len(widths) && n > widths[c] {
+					widths[c] = n
+				}
+			}
+		}
+		last, _ := excelize.CoordinatesToCellName(len(sheet.Header), 1)
+		if err := f.SetCellStyle(sheet.Name, "A1", last, header); err != nil {
+			return err
+		}
+		for c, w := range widths {
+			col, _ := excelize.ColumnNumberToName(c + 1)
+			f.SetColWidth(sheet.Name, col, col, float64(min(w, xlsxMaxWidth)+2))
+		}
+		// The summary is a list of figures, not a table
+		if i == 0 {
+			continue
+		}
+		f.SetPanes(sheet.Name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+		if len(sheet.Rows) > 0 {
+			end, _ := excelize.CoordinatesToCellName(len(sheet.Header), len(sheet.Rows)+1)
+			if err := f.AutoFilter(sheet.Name, "A1:"+end, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return f.SaveAs(path)
+}